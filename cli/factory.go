@@ -8,22 +8,23 @@ package main
 import (
 	"context"
 	"fmt"
-	"net"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/gogo/protobuf/types"
+	"github.com/kata-containers/runtime/pkg/katautils"
 	pb "github.com/kata-containers/runtime/protocols/cache"
 	vc "github.com/kata-containers/runtime/virtcontainers"
 	vf "github.com/kata-containers/runtime/virtcontainers/factory"
+	"github.com/kata-containers/runtime/virtcontainers/factory/grpccache"
 	"github.com/kata-containers/runtime/virtcontainers/pkg/oci"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 	"golang.org/x/sys/unix"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 var factorySubCmds = []cli.Command{
@@ -45,16 +46,45 @@ type cacheServer struct {
 	rpc     *grpc.Server
 	factory vc.Factory
 	done    chan struct{}
+
+	// pools are the VM configs of the factory pools, by name.
+	pools map[string]vc.VMConfig
+
+	// quota limits the VMs each client gets, nil not limiting them.
+	quota *grpccache.Quota
 }
 
 var jsonVMConfig *pb.GrpcVMConfig
 
+// poolConfig returns the VM config of the factory pool a request is for,
+// the base factory config for requests without a pool.
+func (s *cacheServer) poolConfig(ctx context.Context) (vc.VMConfig, string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	names := md[grpccache.PoolMetadataKey]
+	if len(names) == 0 || names[0] == "" {
+		return s.factory.Config(), "", nil
+	}
+
+	config, ok := s.pools[names[0]]
+	if !ok {
+		return vc.VMConfig{}, "", fmt.Errorf("unknown factory pool %s", names[0])
+	}
+
+	return config, names[0], nil
+}
+
 // Config requests base factory config and convert it to gRPC protocol.
 func (s *cacheServer) Config(ctx context.Context, empty *types.Empty) (*pb.GrpcVMConfig, error) {
-	if jsonVMConfig == nil {
-		config := s.factory.Config()
+	config, pool, err := s.poolConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if pool != "" {
+		return config.ToGrpc()
+	}
 
-		var err error
+	if jsonVMConfig == nil {
 		jsonVMConfig, err = config.ToGrpc()
 		if err != nil {
 			return nil, err
@@ -66,10 +96,19 @@ func (s *cacheServer) Config(ctx context.Context, empty *types.Empty) (*pb.GrpcV
 
 // GetBaseVM requests a paused VM and convert it to gRPC protocol.
 func (s *cacheServer) GetBaseVM(ctx context.Context, empty *types.Empty) (*pb.GrpcVM, error) {
-	config := s.factory.Config()
+	config, _, err := s.poolConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quotaKey := grpccache.QuotaKey(ctx)
+	if !s.quota.Take(quotaKey) {
+		return nil, fmt.Errorf("VM cache client %s exceeded its quota", grpccache.ClientID(ctx))
+	}
 
 	vm, err := s.factory.GetBaseVM(ctx, config)
 	if err != nil {
+		s.quota.Refund(quotaKey)
 		return nil, errors.Wrapf(err, "failed to GetBaseVM")
 	}
 
@@ -81,8 +120,13 @@ func (s *cacheServer) quit() {
 	close(s.done)
 }
 
-// Quit will stop VMCache server after 1 second.
+// Quit will stop VMCache server after 1 second. Only the clients of the
+// host, connected over the unix socket, can stop it.
 func (s *cacheServer) Quit(ctx context.Context, empty *types.Empty) (*types.Empty, error) {
+	if !grpccache.LocalClient(ctx) {
+		return nil, fmt.Errorf("VM cache client %s is not allowed to stop the server", grpccache.ClientID(ctx))
+	}
+
 	go func() {
 		kataLog.Info("VM cache server will stop after 1 second")
 		time.Sleep(time.Second)
@@ -93,34 +137,13 @@ func (s *cacheServer) Quit(ctx context.Context, empty *types.Empty) (*types.Empt
 
 func (s *cacheServer) Status(ctx context.Context, empty *types.Empty) (*pb.GrpcStatus, error) {
 	stat := pb.GrpcStatus{
-		Pid:      int64(os.Getpid()),
-		Vmstatus: s.factory.GetVMStatus(),
+		Pid:        int64(os.Getpid()),
+		Vmstatus:   s.factory.GetVMStatus(),
+		Cachestats: s.factory.GetCacheStats(),
 	}
 	return &stat, nil
 }
 
-func getUnixListener(path string) (net.Listener, error) {
-	err := os.MkdirAll(filepath.Dir(path), 0755)
-	if err != nil {
-		return nil, err
-	}
-	_, err = os.Stat(path)
-	if err == nil {
-		return nil, fmt.Errorf("%s already exist.  Please stop running VMCache server and remove %s", path, path)
-	} else if !os.IsNotExist(err) {
-		return nil, err
-	}
-	l, err := net.Listen("unix", path)
-	if err != nil {
-		return nil, err
-	}
-	if err = os.Chmod(path, 0600); err != nil {
-		l.Close()
-		return nil, err
-	}
-	return l, nil
-}
-
 var handledSignals = []os.Signal{
 	syscall.SIGTERM,
 	syscall.SIGINT,
@@ -159,10 +182,14 @@ var initFactoryCommand = cli.Command{
 		}
 
 		factoryConfig := vf.Config{
-			Template:     runtimeConfig.FactoryConfig.Template,
-			TemplatePath: runtimeConfig.FactoryConfig.TemplatePath,
-			Cache:        runtimeConfig.FactoryConfig.VMCacheNumber,
-			VMCache:      runtimeConfig.FactoryConfig.VMCacheNumber > 0,
+			Template:            runtimeConfig.FactoryConfig.Template,
+			TemplatePath:        runtimeConfig.FactoryConfig.TemplatePath,
+			Cache:               runtimeConfig.FactoryConfig.VMCacheNumber,
+			CacheMax:            runtimeConfig.FactoryConfig.VMCacheMaxNumber,
+			CacheRefillInterval: runtimeConfig.FactoryConfig.VMCacheRefillInterval,
+			CacheIdleTimeout:    runtimeConfig.FactoryConfig.VMCacheIdleTimeout,
+			VMCache:             runtimeConfig.FactoryConfig.VMCacheNumber > 0,
+			VMCacheTLS:          katautils.FactoryVMCacheTLS(runtimeConfig),
 			VMConfig: vc.VMConfig{
 				HypervisorType:   runtimeConfig.HypervisorType,
 				HypervisorConfig: runtimeConfig.HypervisorConfig,
@@ -171,9 +198,16 @@ var initFactoryCommand = cli.Command{
 				ProxyType:        runtimeConfig.ProxyType,
 				ProxyConfig:      runtimeConfig.ProxyConfig,
 			},
+			Pools: katautils.FactoryPools(runtimeConfig),
 		}
 
 		if runtimeConfig.FactoryConfig.VMCacheNumber > 0 {
+			l, opts, err := grpccache.Listen(runtimeConfig.FactoryConfig.VMCacheEndpoint, factoryConfig.VMCacheTLS)
+			if err != nil {
+				return err
+			}
+			defer l.Close()
+
 			f, err := vf.NewFactory(ctx, factoryConfig, false)
 			if err != nil {
 				return err
@@ -181,16 +215,15 @@ var initFactoryCommand = cli.Command{
 			defer f.CloseFactory(ctx)
 
 			s := &cacheServer{
-				rpc:     grpc.NewServer(),
+				rpc:     grpc.NewServer(opts...),
 				factory: f,
+				pools:   make(map[string]vc.VMConfig),
+				quota:   grpccache.NewQuota(runtimeConfig.FactoryConfig.VMCacheClientQuota, time.Minute),
 			}
-			pb.RegisterCacheServiceServer(s.rpc, s)
-
-			l, err := getUnixListener(runtimeConfig.FactoryConfig.VMCacheEndpoint)
-			if err != nil {
-				return err
+			for _, p := range factoryConfig.Pools {
+				s.pools[p.Name] = p.VMConfig
 			}
-			defer l.Close()
+			pb.RegisterCacheServiceServer(s.rpc, s)
 
 			signals := make(chan os.Signal, 8)
 			handleSignals(s, signals)
@@ -238,7 +271,7 @@ var destroyFactoryCommand = cli.Command{
 		}
 
 		if runtimeConfig.FactoryConfig.VMCacheNumber > 0 {
-			conn, err := grpc.Dial(fmt.Sprintf("unix://%s", runtimeConfig.FactoryConfig.VMCacheEndpoint), grpc.WithInsecure())
+			conn, err := grpccache.Dial(runtimeConfig.FactoryConfig.VMCacheEndpoint, katautils.FactoryVMCacheTLS(runtimeConfig))
 			if err != nil {
 				return errors.Wrapf(err, "failed to connect %q", runtimeConfig.FactoryConfig.VMCacheEndpoint)
 			}
@@ -261,6 +294,7 @@ var destroyFactoryCommand = cli.Command{
 					ProxyType:        runtimeConfig.ProxyType,
 					ProxyConfig:      runtimeConfig.ProxyConfig,
 				},
+				Pools: katautils.FactoryPools(runtimeConfig),
 			}
 			kataLog.WithField("factory", factoryConfig).Info("load vm factory")
 			f, err := vf.NewFactory(ctx, factoryConfig, true)
@@ -276,6 +310,16 @@ var destroyFactoryCommand = cli.Command{
 	},
 }
 
+func printCacheStats(cs *pb.GrpcCacheStats) {
+	var bootTime time.Duration
+	if cs.Boots > 0 {
+		bootTime = time.Duration(cs.BootTime / int64(cs.Boots))
+	}
+
+	fmt.Fprintf(defaultOutputFile, "VM cache target = %d hits = %d misses = %d evictions = %d\n", cs.Target, cs.Hits, cs.Misses, cs.Evictions)
+	fmt.Fprintf(defaultOutputFile, "VM boots = %d average boot time = %v max boot time = %v\n", cs.Boots, bootTime, time.Duration(cs.MaxBootTime))
+}
+
 var statusFactoryCommand = cli.Command{
 	Name:  "status",
 	Usage: "query the status of VM factory",
@@ -291,7 +335,7 @@ var statusFactoryCommand = cli.Command{
 		}
 
 		if runtimeConfig.FactoryConfig.VMCacheNumber > 0 {
-			conn, err := grpc.Dial(fmt.Sprintf("unix://%s", runtimeConfig.FactoryConfig.VMCacheEndpoint), grpc.WithInsecure())
+			conn, err := grpccache.Dial(runtimeConfig.FactoryConfig.VMCacheEndpoint, katautils.FactoryVMCacheTLS(runtimeConfig))
 			if err != nil {
 				fmt.Fprintln(defaultOutputFile, errors.Wrapf(err, "failed to connect %q", runtimeConfig.FactoryConfig.VMCacheEndpoint))
 			} else {
@@ -304,10 +348,19 @@ var statusFactoryCommand = cli.Command{
 					for _, vs := range status.Vmstatus {
 						fmt.Fprintf(defaultOutputFile, "VM pid = %d Cpu = %d Memory = %dMiB\n", vs.Pid, vs.Cpu, vs.Memory)
 					}
+					if cs := status.Cachestats; cs != nil {
+						printCacheStats(cs)
+					}
 				}
 			}
 		}
 		if runtimeConfig.FactoryConfig.Template {
+			// Only the pool templates are loaded, not their caches.
+			pools := katautils.FactoryPools(runtimeConfig)
+			for i := range pools {
+				pools[i].Cache = 0
+			}
+
 			factoryConfig := vf.Config{
 				Template:     true,
 				TemplatePath: runtimeConfig.FactoryConfig.TemplatePath,
@@ -319,6 +372,7 @@ var statusFactoryCommand = cli.Command{
 					ProxyType:        runtimeConfig.ProxyType,
 					ProxyConfig:      runtimeConfig.ProxyConfig,
 				},
+				Pools: pools,
 			}
 			kataLog.WithField("factory", factoryConfig).Info("load vm factory")
 			_, err := vf.NewFactory(ctx, factoryConfig, true)
@@ -6,16 +6,21 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/gogo/protobuf/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli"
+	"google.golang.org/grpc/metadata"
 
 	ktu "github.com/kata-containers/runtime/pkg/katatestutils"
 	vc "github.com/kata-containers/runtime/virtcontainers"
+	vf "github.com/kata-containers/runtime/virtcontainers/factory"
+	"github.com/kata-containers/runtime/virtcontainers/factory/grpccache"
 )
 
 const testDisabledAsNonRoot = "Test disabled as requires root privileges"
@@ -75,6 +80,14 @@ func TestFactoryCLIFunctionInit(t *testing.T) {
 	runtimeConfig.HypervisorType = vc.MockHypervisor
 	runtimeConfig.AgentType = vc.NoopAgentType
 	runtimeConfig.ProxyType = vc.NoopProxyType
+
+	// The template manifest records the hashes of the VM assets
+	hConfig := runtimeConfig.HypervisorConfig
+	for _, path := range []string{hConfig.KernelPath, hConfig.ImagePath, hConfig.HypervisorPath} {
+		err = ioutil.WriteFile(path, []byte(path), testFileMode)
+		assert.NoError(err)
+	}
+
 	ctx.App.Metadata["runtimeConfig"] = runtimeConfig
 	fn, ok = initFactoryCommand.Action.(func(context *cli.Context) error)
 	assert.True(ok)
@@ -150,3 +163,49 @@ func TestFactoryCLIFunctionStatus(t *testing.T) {
 	err = fn(ctx)
 	assert.Nil(err)
 }
+
+func TestCacheServerStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpdir, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmpdir)
+
+	vmConfig := vc.VMConfig{
+		HypervisorType: vc.MockHypervisor,
+		AgentType:      vc.NoopAgentType,
+		ProxyType:      vc.NoopProxyType,
+		HypervisorConfig: vc.HypervisorConfig{
+			KernelPath: tmpdir,
+			ImagePath:  tmpdir,
+		},
+	}
+
+	ctx := context.Background()
+	f, err := vf.NewFactory(ctx, vf.Config{Cache: 1, VMConfig: vmConfig}, false)
+	assert.NoError(err)
+	defer f.CloseFactory(ctx)
+
+	s := &cacheServer{factory: f}
+
+	// the VM is taken from the cache, the mock hypervisor VMs cannot be
+	// sent to the client though
+	_, err = s.GetBaseVM(ctx, &types.Empty{})
+	assert.Error(err)
+
+	status, err := s.Status(ctx, &types.Empty{})
+	assert.NoError(err)
+	assert.Equal(int64(os.Getpid()), status.Pid)
+	assert.NotNil(status.Cachestats)
+	assert.Equal(uint64(1), status.Cachestats.Hits+status.Cachestats.Misses)
+	assert.Equal(uint32(1), status.Cachestats.Target)
+
+	// unknown pool
+	md := metadata.Pairs(grpccache.PoolMetadataKey, "foo")
+	_, err = s.GetBaseVM(metadata.NewIncomingContext(ctx, md), &types.Empty{})
+	assert.Error(err)
+
+	// only the clients of the host can stop the server
+	_, err = s.Quit(ctx, &types.Empty{})
+	assert.Error(err)
+}
@@ -126,6 +126,7 @@ var runtimeCommands = []cli.Command{
 	execCLICommand,
 	killCLICommand,
 	listCLICommand,
+	migrateCLICommand,
 	pauseCLICommand,
 	psCLICommand,
 	resumeCLICommand,
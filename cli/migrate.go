@@ -0,0 +1,307 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/kata-containers/runtime/pkg/katautils"
+	vc "github.com/kata-containers/runtime/virtcontainers"
+	vcAnnot "github.com/kata-containers/runtime/virtcontainers/pkg/annotations"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/compatoci"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/oci"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var migrateCLICommand = cli.Command{
+	Name:  "migrate",
+	Usage: "live migrate a sandbox to another runtime",
+	ArgsUsage: `<sandbox-id> <uri>
+
+   <sandbox-id> is the ID of the sandbox, or of one of its containers, to
+   migrate.
+   <uri> is the address the destination runtime listens on, either
+   unix:<path> or tcp:<host>:<port>. Over tcp, both runtimes authenticate
+   each other with the migration mutual TLS configuration.
+
+   The destination runtime is started with:
+
+      ` + name + ` migrate --incoming <uri>`,
+	Description: `The migrate command live migrates a running sandbox, along with all its
+   containers, to the destination runtime and deletes it from this host once
+   it runs there. The container bundles must be available at the same paths on
+   the destination, under the bundle root.`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "incoming",
+			Usage: "wait for a sandbox to be migrated from another runtime on this URI",
+		},
+		cli.StringFlag{
+			Name:  "checkpoint-dir",
+			Usage: "directory where the migrated sandbox is received, defaults to a temporary directory",
+		},
+		cli.StringFlag{
+			Name:  "bundle-root",
+			Usage: "directory the bundles of the migrated containers must be in, defaults to the runtime root",
+		},
+		cli.StringFlag{
+			Name:  "netns",
+			Usage: "network namespace to plumb the migrated sandbox into, defaults to the one of its bundle",
+		},
+	},
+	Action: func(context *cli.Context) error {
+		ctx, err := cliContextToContext(context)
+		if err != nil {
+			return err
+		}
+
+		runtimeConfig, ok := context.App.Metadata["runtimeConfig"].(oci.RuntimeConfig)
+		if !ok {
+			return errors.New("invalid runtime config")
+		}
+
+		if uri := context.String("incoming"); uri != "" {
+			bundleRoot := context.String("bundle-root")
+			if bundleRoot == "" {
+				bundleRoot = context.GlobalString("root")
+			}
+
+			return migrateIncoming(ctx, uri, context.String("checkpoint-dir"), bundleRoot, context.String("netns"),
+				context.Bool("systemd-cgroup"), runtimeConfig)
+		}
+
+		args := context.Args()
+		if len(args) != 2 {
+			return fmt.Errorf("Expecting a sandbox ID and a destination URI")
+		}
+
+		return migrate(ctx, args.Get(0), args.Get(1), runtimeConfig)
+	},
+}
+
+// migrationIDRegex matches the IDs of the containers of a migrated sandbox,
+// they end up in paths on this host.
+var migrationIDRegex = regexp.MustCompile(`^[A-Za-z0-9]+(?:[._-][A-Za-z0-9]+)*$`)
+
+// migrationTLSConfig returns the TLS configuration to migrate over uri, none
+// for a unix socket. Over tcp, the source and the destination authenticate
+// each other with the migration mutual TLS configuration.
+func migrationTLSConfig(uri string, runtimeConfig oci.RuntimeConfig, server bool) (*tls.Config, error) {
+	network, _, err := vc.ParseMigrationURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == "unix" {
+		return nil, nil
+	}
+
+	if server {
+		return runtimeConfig.MigrationTLS.ServerConfig()
+	}
+
+	return runtimeConfig.MigrationTLS.ClientConfig(true)
+}
+
+func migrate(ctx context.Context, containerID, uri string, runtimeConfig oci.RuntimeConfig) error {
+	span, _ := katautils.Trace(ctx, "migrate")
+	defer span.Finish()
+
+	kataLog = kataLog.WithField("container", containerID)
+	setExternalLoggers(ctx, kataLog)
+	span.SetTag("container", containerID)
+
+	_, sandboxID, err := getExistingContainerInfo(ctx, containerID)
+	if err != nil {
+		return err
+	}
+
+	kataLog = kataLog.WithFields(logrus.Fields{
+		"sandbox": sandboxID,
+		"uri":     uri,
+	})
+
+	setExternalLoggers(ctx, kataLog)
+	span.SetTag("sandbox", sandboxID)
+
+	tlsConfig, err := migrationTLSConfig(uri, runtimeConfig, false)
+	if err != nil {
+		return err
+	}
+
+	return vci.MigrateSandbox(ctx, sandboxID, uri, tlsConfig)
+}
+
+// migrateIncoming waits for a single sandbox to be migrated on uri and
+// restores it. The bundles of its containers must be under bundleRoot.
+func migrateIncoming(ctx context.Context, uri, dir, bundleRoot, netNsPath string, systemdCgroup bool, runtimeConfig oci.RuntimeConfig) error {
+	span, ctx := katautils.Trace(ctx, "migrateIncoming")
+	defer span.Finish()
+
+	kataLog = kataLog.WithField("uri", uri)
+	setExternalLoggers(ctx, kataLog)
+
+	tlsConfig, err := migrationTLSConfig(uri, runtimeConfig, true)
+	if err != nil {
+		return err
+	}
+
+	if bundleRoot, err = katautils.ResolvePath(bundleRoot); err != nil {
+		return err
+	}
+
+	if dir == "" {
+		if dir, err = ioutil.TempDir("", "kata-migration"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+	}
+
+	l, err := vc.ListenMigration(uri, tlsConfig)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	kataLog.Info("waiting for sandbox migration")
+
+	conn, err := l.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return vc.ReceiveMigration(conn, dir, func(dir string) error {
+		return restoreMigratedSandbox(ctx, dir, bundleRoot, netNsPath, systemdCgroup, runtimeConfig)
+	})
+}
+
+// validMigratedBundle checks the ID and the bundle path the source sent for
+// a migrated container, and returns the resolved bundle path. The bundle
+// must be a directory under bundleRoot, itself resolved.
+func validMigratedBundle(containerID, bundlePath, bundleRoot string) (string, error) {
+	if !migrationIDRegex.MatchString(containerID) {
+		return "", fmt.Errorf("Invalid migrated container ID %q", containerID)
+	}
+
+	if bundlePath == "" {
+		return "", fmt.Errorf("Missing bundle path for migrated container %s", containerID)
+	}
+
+	fileInfo, err := os.Stat(bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("Invalid bundle path '%s': %s", bundlePath, err)
+	}
+	if !fileInfo.IsDir() {
+		return "", fmt.Errorf("Invalid bundle path '%s', it should be a directory", bundlePath)
+	}
+
+	resolved, err := katautils.ResolvePath(bundlePath)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(resolved, strings.TrimSuffix(bundleRoot, "/")+"/") {
+		return "", fmt.Errorf("Bundle path '%s' is not under %s", bundlePath, bundleRoot)
+	}
+
+	return resolved, nil
+}
+
+// restoreMigratedSandbox creates and starts again, from the checkpoint in
+// dir, the sandbox and containers of a migrated sandbox.
+func restoreMigratedSandbox(ctx context.Context, dir, bundleRoot, netNsPath string, systemdCgroup bool, runtimeConfig oci.RuntimeConfig) error {
+	sandboxConfig, err := vc.LoadCheckpointConfig(dir)
+	if err != nil {
+		return err
+	}
+
+	// Nothing is created unless all the bundles are valid.
+	bundlePaths := make(map[string]string, len(sandboxConfig.Containers))
+	for _, contConfig := range sandboxConfig.Containers {
+		bundlePath, err := validMigratedBundle(contConfig.ID, contConfig.Annotations[vcAnnot.BundlePathKey], bundleRoot)
+		if err != nil {
+			return err
+		}
+		bundlePaths[contConfig.ID] = bundlePath
+	}
+
+	kataLog = kataLog.WithField("sandbox", sandboxConfig.ID)
+	setExternalLoggers(ctx, kataLog)
+
+	runtimeConfig.HypervisorConfig.BootFromCheckpoint = true
+	runtimeConfig.HypervisorConfig.DevicesStatePath = vc.CheckpointVMStatePath(dir)
+
+	rootFs := vc.RootFs{Mounted: true}
+
+	// Containers are created in the order the source sandbox knew them,
+	// the sandbox container being the first one.
+	for _, contConfig := range sandboxConfig.Containers {
+		bundlePath := bundlePaths[contConfig.ID]
+
+		ociSpec, err := compatoci.ParseConfigJSON(bundlePath)
+		if err != nil {
+			return err
+		}
+
+		containerType, err := oci.ContainerType(ociSpec)
+		if err != nil {
+			return err
+		}
+
+		switch containerType {
+		case vc.PodSandbox:
+			if netNsPath != "" {
+				setNetNsPath(&ociSpec, netNsPath)
+			}
+
+			_, _, err = katautils.CreateSandbox(ctx, vci, ociSpec, runtimeConfig, rootFs, contConfig.ID, bundlePath, "", true, systemdCgroup, false)
+		case vc.PodContainer:
+			_, err = katautils.CreateContainer(ctx, vci, nil, ociSpec, rootFs, contConfig.ID, bundlePath, "", true, false)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, contConfig := range sandboxConfig.Containers {
+		if _, err := start(ctx, contConfig.ID); err != nil {
+			return err
+		}
+	}
+
+	kataLog.Info("migrated sandbox restored")
+
+	return nil
+}
+
+// setNetNsPath makes the OCI spec use the network namespace at path.
+func setNetNsPath(ociSpec *specs.Spec, path string) {
+	if ociSpec.Linux == nil {
+		ociSpec.Linux = &specs.Linux{}
+	}
+
+	for i, ns := range ociSpec.Linux.Namespaces {
+		if ns.Type == specs.NetworkNamespace {
+			ociSpec.Linux.Namespaces[i].Path = path
+			return
+		}
+	}
+
+	ociSpec.Linux.Namespaces = append(ociSpec.Linux.Namespaces, specs.LinuxNamespace{
+		Type: specs.NetworkNamespace,
+		Path: path,
+	})
+}
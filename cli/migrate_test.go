@@ -0,0 +1,214 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/oci"
+	"github.com/kata-containers/runtime/virtcontainers/types"
+)
+
+const testMigrationURI = "unix:/run/kata-migration.sock"
+
+func execMigrateCLICommand(assert *assert.Assertions, set *flag.FlagSet, expectedErr bool) {
+	ctx := createCLIContext(set)
+	ctx.App.Name = "foo"
+	ctx.App.Metadata["runtimeConfig"] = oci.RuntimeConfig{}
+
+	fn, ok := migrateCLICommand.Action.(func(context *cli.Context) error)
+	assert.True(ok)
+
+	err := fn(ctx)
+	if expectedErr {
+		assert.Error(err)
+	} else {
+		assert.NoError(err)
+	}
+}
+
+func TestMigrateCLIFunctionSuccessful(t *testing.T) {
+	assert := assert.New(t)
+
+	state := types.ContainerState{
+		State: types.StateRunning,
+	}
+
+	path, err := createTempContainerIDMapping(testContainerID, testSandboxID)
+	assert.NoError(err)
+	defer os.RemoveAll(path)
+
+	testingImpl.StatusContainerFunc = func(ctx context.Context, sandboxID, containerID string) (vc.ContainerStatus, error) {
+		return newSingleContainerStatus(testContainerID, state, map[string]string{}, &specs.Spec{}), nil
+	}
+
+	var migratedSandboxID, migratedURI string
+	testingImpl.MigrateSandboxFunc = func(ctx context.Context, sandboxID, target string, tlsConfig *tls.Config) error {
+		migratedSandboxID = sandboxID
+		migratedURI = target
+		return nil
+	}
+
+	defer func() {
+		testingImpl.MigrateSandboxFunc = nil
+		testingImpl.StatusContainerFunc = nil
+	}()
+
+	set := flag.NewFlagSet("", 0)
+	set.Parse([]string{testContainerID, testMigrationURI})
+
+	execMigrateCLICommand(assert, set, false)
+	assert.Equal(testSandboxID, migratedSandboxID)
+	assert.Equal(testMigrationURI, migratedURI)
+
+	// migrating over tcp requires TLS
+	migratedSandboxID = ""
+	set = flag.NewFlagSet("", 0)
+	set.Parse([]string{testContainerID, "tcp:10.0.0.2:4444"})
+
+	execMigrateCLICommand(assert, set, true)
+	assert.Empty(migratedSandboxID)
+}
+
+func TestMigrateCLIFunctionMissingArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	set := flag.NewFlagSet("", 0)
+	set.Parse([]string{testContainerID})
+
+	execMigrateCLICommand(assert, set, true)
+}
+
+func TestMigrateCLIFunctionContainerNotExistFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	testingImpl.MigrateSandboxFunc = func(ctx context.Context, sandboxID, target string, tlsConfig *tls.Config) error {
+		return nil
+	}
+
+	path, err := ioutil.TempDir("", "containers-mapping")
+	assert.NoError(err)
+	defer os.RemoveAll(path)
+	ctrsMapTreePath = path
+
+	defer func() {
+		testingImpl.MigrateSandboxFunc = nil
+	}()
+
+	set := flag.NewFlagSet("", 0)
+	set.Parse([]string{testContainerID, testMigrationURI})
+
+	execMigrateCLICommand(assert, set, true)
+}
+
+func TestMigrateCLIFunctionMigrateSandboxFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	state := types.ContainerState{
+		State: types.StateRunning,
+	}
+
+	path, err := createTempContainerIDMapping(testContainerID, testSandboxID)
+	assert.NoError(err)
+	defer os.RemoveAll(path)
+
+	testingImpl.StatusContainerFunc = func(ctx context.Context, sandboxID, containerID string) (vc.ContainerStatus, error) {
+		return newSingleContainerStatus(testContainerID, state, map[string]string{}, &specs.Spec{}), nil
+	}
+
+	defer func() {
+		testingImpl.StatusContainerFunc = nil
+	}()
+
+	set := flag.NewFlagSet("", 0)
+	set.Parse([]string{testContainerID, testMigrationURI})
+
+	execMigrateCLICommand(assert, set, true)
+}
+
+func TestMigrateIncomingInvalidURI(t *testing.T) {
+	assert := assert.New(t)
+
+	err := migrateIncoming(context.Background(), "exec:cat", "", "/", "", false, oci.RuntimeConfig{})
+	assert.Error(err)
+
+	// listening on tcp requires TLS
+	err = migrateIncoming(context.Background(), "tcp:localhost:0", "", "/", "", false, oci.RuntimeConfig{})
+	assert.Error(err)
+}
+
+func TestValidMigratedBundle(t *testing.T) {
+	assert := assert.New(t)
+
+	root, err := ioutil.TempDir(testDir, "")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+
+	root, err = filepath.EvalSymlinks(root)
+	assert.NoError(err)
+
+	bundle := filepath.Join(root, "bundle")
+	err = os.Mkdir(bundle, testDirMode)
+	assert.NoError(err)
+
+	resolved, err := validMigratedBundle(testContainerID, bundle, root)
+	assert.NoError(err)
+	assert.Equal(bundle, resolved)
+
+	for _, id := range []string{"", "../foo", "foo/bar", ".foo"} {
+		_, err = validMigratedBundle(id, bundle, root)
+		assert.Error(err, "id %q", id)
+	}
+
+	// missing, not a directory, outside of the root
+	_, err = validMigratedBundle(testContainerID, "", root)
+	assert.Error(err)
+
+	file := filepath.Join(root, "file")
+	err = ioutil.WriteFile(file, nil, testFileMode)
+	assert.NoError(err)
+	_, err = validMigratedBundle(testContainerID, file, root)
+	assert.Error(err)
+
+	_, err = validMigratedBundle(testContainerID, filepath.Join(bundle, ".."), root)
+	assert.Error(err)
+
+	_, err = validMigratedBundle(testContainerID, bundle, filepath.Join(root, "other"))
+	assert.Error(err)
+
+	link := filepath.Join(root, "link")
+	err = os.Symlink("/", link)
+	assert.NoError(err)
+	_, err = validMigratedBundle(testContainerID, link, root)
+	assert.Error(err)
+}
+
+func TestSetNetNsPath(t *testing.T) {
+	assert := assert.New(t)
+
+	ociSpec := specs.Spec{}
+	setNetNsPath(&ociSpec, "/foo")
+	assert.Equal([]specs.LinuxNamespace{{Type: specs.NetworkNamespace, Path: "/foo"}}, ociSpec.Linux.Namespaces)
+
+	ociSpec.Linux.Namespaces = []specs.LinuxNamespace{
+		{Type: specs.PIDNamespace},
+		{Type: specs.NetworkNamespace, Path: "/foo"},
+	}
+	setNetNsPath(&ociSpec, "/bar")
+	assert.Equal("/bar", ociSpec.Linux.Namespaces[1].Path)
+	assert.Len(ociSpec.Linux.Namespaces, 2)
+}
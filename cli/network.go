@@ -24,17 +24,29 @@ const (
 	interfaceType networkType = iota
 
 	routeType
+
+	neighborType
+)
+
+type networkOp int
+
+const (
+	addOp networkOp = iota
+	delOp
+	updateOp
 )
 
 var kataNetworkCLICommand = cli.Command{
 	Name:  "kata-network",
-	Usage: "manage interfaces and routes for container",
+	Usage: "manage interfaces, routes and neighbors for container",
 	Subcommands: []cli.Command{
 		addIfaceCommand,
 		delIfaceCommand,
+		updateIfaceCommand,
 		listIfacesCommand,
 		updateRoutesCommand,
 		listRoutesCommand,
+		addNeighborsCommand,
 	},
 	Action: func(context *cli.Context) error {
 		return cli.ShowSubcommandHelp(context)
@@ -52,7 +64,7 @@ var addIfaceCommand = cli.Command{
 			return err
 		}
 
-		return networkModifyCommand(ctx, context.Args().First(), context.Args().Get(1), interfaceType, true)
+		return networkModifyCommand(ctx, context.Args().First(), context.Args().Get(1), interfaceType, addOp)
 	},
 }
 
@@ -67,7 +79,22 @@ var delIfaceCommand = cli.Command{
 			return err
 		}
 
-		return networkModifyCommand(ctx, context.Args().First(), context.Args().Get(1), interfaceType, false)
+		return networkModifyCommand(ctx, context.Args().First(), context.Args().Get(1), interfaceType, delOp)
+	},
+}
+
+var updateIfaceCommand = cli.Command{
+	Name:      "update-iface",
+	Usage:     "update the addresses of an interface of a container",
+	ArgsUsage: `update-iface <container-id> file or - for stdin`,
+	Flags:     []cli.Flag{},
+	Action: func(context *cli.Context) error {
+		ctx, err := cliContextToContext(context)
+		if err != nil {
+			return err
+		}
+
+		return networkModifyCommand(ctx, context.Args().First(), context.Args().Get(1), interfaceType, updateOp)
 	},
 }
 
@@ -97,7 +124,7 @@ var updateRoutesCommand = cli.Command{
 			return err
 		}
 
-		return networkModifyCommand(ctx, context.Args().First(), context.Args().Get(1), routeType, true)
+		return networkModifyCommand(ctx, context.Args().First(), context.Args().Get(1), routeType, updateOp)
 	},
 }
 
@@ -116,7 +143,22 @@ var listRoutesCommand = cli.Command{
 	},
 }
 
-func networkModifyCommand(ctx context.Context, containerID, input string, opType networkType, add bool) (err error) {
+var addNeighborsCommand = cli.Command{
+	Name:      "add-neighbors",
+	Usage:     "add static ARP and NDP neighbors to a container",
+	ArgsUsage: `add-neighbors <container-id> file or - for stdin`,
+	Flags:     []cli.Flag{},
+	Action: func(context *cli.Context) error {
+		ctx, err := cliContextToContext(context)
+		if err != nil {
+			return err
+		}
+
+		return networkModifyCommand(ctx, context.Args().First(), context.Args().Get(1), neighborType, addOp)
+	},
+}
+
+func networkModifyCommand(ctx context.Context, containerID, input string, opType networkType, op networkOp) (err error) {
 	status, sandboxID, err := getExistingContainerInfo(ctx, containerID)
 	if err != nil {
 		return err
@@ -156,18 +198,25 @@ func networkModifyCommand(ctx context.Context, containerID, input string, opType
 		if err = json.NewDecoder(f).Decode(&inf); err != nil {
 			return err
 		}
-		if add {
+		switch op {
+		case addOp:
 			resultingInf, err = vci.AddInterface(ctx, sandboxID, inf)
 			if err != nil {
 				kataLog.WithField("resulting-interface", fmt.Sprintf("%+v", resultingInf)).
 					WithError(err).Error("add interface failed")
 			}
-		} else {
+		case delOp:
 			resultingInf, err = vci.RemoveInterface(ctx, sandboxID, inf)
 			if err != nil {
 				kataLog.WithField("resulting-interface", fmt.Sprintf("%+v", resultingInf)).
 					WithError(err).Error("delete interface failed")
 			}
+		case updateOp:
+			resultingInf, err = vci.UpdateInterface(ctx, sandboxID, inf)
+			if err != nil {
+				kataLog.WithField("resulting-interface", fmt.Sprintf("%+v", resultingInf)).
+					WithError(err).Error("update interface failed")
+			}
 		}
 		json.NewEncoder(output).Encode(resultingInf)
 	case routeType:
@@ -181,6 +230,15 @@ func networkModifyCommand(ctx context.Context, containerID, input string, opType
 			kataLog.WithField("resulting-routes", fmt.Sprintf("%+v", resultingRoutes)).
 				WithError(err).Error("update routes failed")
 		}
+	case neighborType:
+		var neighs []*vcTypes.ARPNeighbor
+		if err = json.NewDecoder(f).Decode(&neighs); err != nil {
+			return err
+		}
+		if err = vci.AddNeighbors(ctx, sandboxID, neighs); err != nil {
+			kataLog.WithField("neighbors", fmt.Sprintf("%+v", neighs)).
+				WithError(err).Error("add neighbors failed")
+		}
 	}
 	return err
 }
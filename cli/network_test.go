@@ -30,12 +30,18 @@ var (
 	testListInterfacesFuncReturnNil = func(ctx context.Context, sandboxID string) ([]*vcTypes.Interface, error) {
 		return nil, nil
 	}
+	testUpdateInterfaceFuncReturnNil = func(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+		return nil, nil
+	}
 	testUpdateRoutsFuncReturnNil = func(ctx context.Context, sandboxID string, routes []*vcTypes.Route) ([]*vcTypes.Route, error) {
 		return nil, nil
 	}
 	testListRoutesFuncReturnNil = func(ctx context.Context, sandboxID string) ([]*vcTypes.Route, error) {
 		return nil, nil
 	}
+	testAddNeighborsFuncReturnNil = func(ctx context.Context, sandboxID string, neighs []*vcTypes.ARPNeighbor) error {
+		return nil
+	}
 )
 
 func TestNetworkCliFunction(t *testing.T) {
@@ -50,6 +56,8 @@ func TestNetworkCliFunction(t *testing.T) {
 	testingImpl.ListInterfacesFunc = testListInterfacesFuncReturnNil
 	testingImpl.UpdateRoutesFunc = testUpdateRoutsFuncReturnNil
 	testingImpl.ListRoutesFunc = testListRoutesFuncReturnNil
+	testingImpl.UpdateInterfaceFunc = testUpdateInterfaceFuncReturnNil
+	testingImpl.AddNeighborsFunc = testAddNeighborsFuncReturnNil
 
 	path, err := createTempContainerIDMapping(testContainerID, testSandboxID)
 	assert.NoError(err)
@@ -65,6 +73,8 @@ func TestNetworkCliFunction(t *testing.T) {
 		testingImpl.ListInterfacesFunc = nil
 		testingImpl.UpdateRoutesFunc = nil
 		testingImpl.ListRoutesFunc = nil
+		testingImpl.UpdateInterfaceFunc = nil
+		testingImpl.AddNeighborsFunc = nil
 		testingImpl.StatusContainerFunc = nil
 	}()
 
@@ -84,9 +94,11 @@ func TestNetworkCliFunction(t *testing.T) {
 	set.Parse([]string{testContainerID, f.Name()})
 	execCLICommandFunc(assert, addIfaceCommand, set, false)
 	execCLICommandFunc(assert, delIfaceCommand, set, false)
+	execCLICommandFunc(assert, updateIfaceCommand, set, false)
 
 	f.Seek(0, 0)
 	f.WriteString("[{}]")
 	f.Close()
 	execCLICommandFunc(assert, updateRoutesCommand, set, false)
+	execCLICommandFunc(assert, addNeighborsCommand, set, false)
 }
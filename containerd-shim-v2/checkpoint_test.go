@@ -0,0 +1,83 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containerd/containerd/namespaces"
+	taskAPI "github.com/containerd/containerd/runtime/v2/task"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/vcmock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointSandbox(t *testing.T) {
+	assert := assert.New(t)
+	var err error
+
+	sandbox := &vcmock.Sandbox{
+		MockID: testSandboxID,
+	}
+
+	s := &service{
+		id:         testSandboxID,
+		sandbox:    sandbox,
+		containers: make(map[string]*container),
+	}
+
+	reqCreate := &taskAPI.CreateTaskRequest{
+		ID: testSandboxID,
+	}
+	s.containers[testSandboxID], err = newContainer(s, reqCreate, vc.PodSandbox, nil, true)
+	assert.NoError(err)
+
+	ctx := namespaces.WithNamespace(context.Background(), "UnitTest")
+
+	_, err = s.Checkpoint(ctx, &taskAPI.CheckpointTaskRequest{
+		ID:   testSandboxID,
+		Path: testDir,
+	})
+	assert.NoError(err)
+}
+
+func TestCheckpointContainerFail(t *testing.T) {
+	assert := assert.New(t)
+	var err error
+
+	sandbox := &vcmock.Sandbox{
+		MockID: testSandboxID,
+	}
+
+	s := &service{
+		id:         testSandboxID,
+		sandbox:    sandbox,
+		containers: make(map[string]*container),
+	}
+
+	reqCreate := &taskAPI.CreateTaskRequest{
+		ID: testContainerID,
+	}
+	s.containers[testContainerID], err = newContainer(s, reqCreate, vc.PodContainer, nil, true)
+	assert.NoError(err)
+
+	ctx := namespaces.WithNamespace(context.Background(), "UnitTest")
+
+	_, err = s.Checkpoint(ctx, &taskAPI.CheckpointTaskRequest{
+		ID:   testContainerID,
+		Path: testDir,
+	})
+	assert.Error(err)
+
+	_, err = s.Checkpoint(ctx, &taskAPI.CheckpointTaskRequest{
+		ID:   "foo",
+		Path: testDir,
+	})
+	assert.Error(err)
+}
@@ -34,6 +34,7 @@ type container struct {
 	bundle      string
 	cType       vc.ContainerType
 	exit        uint32
+	restarts    uint32
 	status      task.Status
 	terminal    bool
 	mounted     bool
@@ -65,6 +65,10 @@ func create(ctx context.Context, s *service, r *taskAPI.CreateTaskRequest) (*con
 			return nil, err
 		}
 
+		// The network namespace is watched by the shim itself, there is
+		// no kata-runtime CLI for the network monitor binary to call into.
+		s.config.NetmonConfig.InProcess = true
+
 		if rootFs.Mounted, err = checkAndMount(s, r); err != nil {
 			return nil, err
 		}
@@ -77,6 +81,12 @@ func create(ctx context.Context, s *service, r *taskAPI.CreateTaskRequest) (*con
 			}
 		}()
 
+		if r.Checkpoint != "" {
+			// Restore the sandbox VM from the checkpoint instead of booting it.
+			s.config.HypervisorConfig.BootFromCheckpoint = true
+			s.config.HypervisorConfig.DevicesStatePath = vc.CheckpointVMStatePath(r.Checkpoint)
+		}
+
 		katautils.HandleFactory(ctx, vci, s.config)
 
 		// Pass service's context instead of local ctx to CreateSandbox(), since local
@@ -88,6 +98,24 @@ func create(ctx context.Context, s *service, r *taskAPI.CreateTaskRequest) (*con
 			return nil, err
 		}
 		s.sandbox = sandbox
+		s.restartable = oci.IsRestartableSandbox(ociSpec, s.config.MonitorConfig)
+
+		if s.config.NetmonConfig.Enable {
+			if err = startNetmon(s); err != nil {
+				// The sandbox VM is running already.
+				if err2 := sandbox.Stop(true); err2 != nil {
+					logrus.WithError(err2).Warn("failed to stop sandbox")
+				}
+				if err2 := sandbox.Delete(); err2 != nil {
+					logrus.WithError(err2).Warn("failed to delete sandbox")
+				}
+				s.sandbox = nil
+				return nil, err
+			}
+		}
+
+		// The sandbox events are published until the sandbox is deleted.
+		go forwardSandboxEvents(s.ctx, s, sandbox.Events())
 
 	case vc.PodContainer:
 		if s.sandbox == nil {
@@ -0,0 +1,115 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"context"
+	"time"
+
+	"github.com/containerd/typeurl"
+	"github.com/sirupsen/logrus"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+)
+
+// sandboxEventTopicPrefix is the prefix of the topics the sandbox events
+// are published on, the event type being appended to it, e.g.
+// /kata/sandbox/vm-started.
+const sandboxEventTopicPrefix = "/kata/sandbox/"
+
+// forwardedSandboxEvents are the sandbox events published to containerd,
+// the container lifecycle and OOM ones being already published as task
+// events.
+var forwardedSandboxEvents = map[vc.EventType]bool{
+	vc.EventVMStarted:          true,
+	vc.EventVMStopped:          true,
+	vc.EventVMRestarted:        true,
+	vc.EventAgentConnected:     true,
+	vc.EventDeviceHotplugged:   true,
+	vc.EventDeviceHotunplugged: true,
+	vc.EventDeviceAttached:     true,
+	vc.EventDeviceDetached:     true,
+	vc.EventVCPUsResized:       true,
+	vc.EventMemoryResized:      true,
+}
+
+// SandboxEvent is the containerd event a sandbox event is published as.
+type SandboxEvent struct {
+	SandboxID   string            `json:"sandbox_id"`
+	ContainerID string            `json:"container_id,omitempty"`
+	Type        string            `json:"type"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Data        map[string]string `json:"data,omitempty"`
+}
+
+func init() {
+	typeurl.Register(&SandboxEvent{}, "io.katacontainers.events", "SandboxEvent")
+}
+
+// Field returns the value for the given fieldpath as a string, if defined,
+// so that the event can be filtered by containerd.
+func (e *SandboxEvent) Field(fieldpath []string) (string, bool) {
+	if len(fieldpath) == 0 {
+		return "", false
+	}
+
+	switch fieldpath[0] {
+	case "sandbox_id":
+		return e.SandboxID, len(e.SandboxID) > 0
+	case "container_id":
+		return e.ContainerID, len(e.ContainerID) > 0
+	case "type":
+		return e.Type, len(e.Type) > 0
+	case "data":
+		if len(fieldpath) < 2 {
+			return "", false
+		}
+		value, ok := e.Data[fieldpath[1]]
+		return value, ok
+	}
+
+	return "", false
+}
+
+func newSandboxEvent(e vc.Event) *SandboxEvent {
+	return &SandboxEvent{
+		SandboxID:   e.SandboxID,
+		ContainerID: e.ContainerID,
+		Type:        string(e.Type),
+		Timestamp:   e.Timestamp,
+		Data:        e.Data,
+	}
+}
+
+// forwardSandboxEvents publishes the sandbox events received on ch to
+// containerd, until ch is closed.
+func forwardSandboxEvents(ctx context.Context, s *service, ch <-chan vc.Event) {
+	if ch == nil {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			if !forwardedSandboxEvents[e.Type] {
+				continue
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"sandbox": e.SandboxID,
+				"event":   e.Type,
+			}).Debug("forwarding sandbox event")
+
+			s.send(newSandboxEvent(e))
+		}
+	}
+}
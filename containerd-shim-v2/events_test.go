@@ -0,0 +1,77 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containerd/typeurl"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSandboxEventField(t *testing.T) {
+	assert := assert.New(t)
+
+	e := newSandboxEvent(vc.Event{
+		Type:      vc.EventMemoryResized,
+		SandboxID: testSandboxID,
+		Timestamp: time.Now(),
+		Data:      map[string]string{"memory-mb": "2048"},
+	})
+
+	assert.Equal(sandboxEventTopicPrefix+"memory-resized", getTopic(e))
+
+	value, ok := e.Field([]string{"sandbox_id"})
+	assert.True(ok)
+	assert.Equal(testSandboxID, value)
+
+	_, ok = e.Field([]string{"container_id"})
+	assert.False(ok)
+
+	value, ok = e.Field([]string{"data", "memory-mb"})
+	assert.True(ok)
+	assert.Equal("2048", value)
+
+	_, ok = e.Field([]string{"data"})
+	assert.False(ok)
+	_, ok = e.Field(nil)
+	assert.False(ok)
+
+	any, err := typeurl.MarshalAny(e)
+	assert.NoError(err)
+	v, err := typeurl.UnmarshalAny(any)
+	assert.NoError(err)
+	assert.Equal(e.Data, v.(*SandboxEvent).Data)
+}
+
+func TestForwardSandboxEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &service{
+		events: make(chan interface{}, chSize),
+	}
+
+	ch := make(chan vc.Event, 3)
+	ch <- vc.Event{Type: vc.EventVMStarted, SandboxID: testSandboxID}
+	ch <- vc.Event{Type: vc.EventOOM, SandboxID: testSandboxID, ContainerID: testContainerID}
+	ch <- vc.Event{Type: vc.EventDeviceHotplugged, SandboxID: testSandboxID}
+	close(ch)
+
+	forwardSandboxEvents(context.Background(), s, ch)
+
+	// OOM events are published as task events already
+	assert.Len(s.events, 2)
+	assert.Equal(string(vc.EventVMStarted), (<-s.events).(*SandboxEvent).Type)
+	assert.Equal(string(vc.EventDeviceHotplugged), (<-s.events).(*SandboxEvent).Type)
+
+	// no events for sandboxes not providing them
+	forwardSandboxEvents(context.Background(), s, nil)
+}
@@ -26,6 +26,9 @@ type exec struct {
 	ttyio     *ttyIO
 	id        string
 
+	// guestPid is the PID of the exec process in the VM, 0 if unknown.
+	guestPid int
+
 	exitCode int32
 
 	status task.Status
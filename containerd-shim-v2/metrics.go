@@ -6,15 +6,35 @@
 package containerdshim
 
 import (
+	"sort"
+
 	"github.com/containerd/cgroups"
+	"github.com/containerd/containerd/api/types/task"
 	"github.com/containerd/typeurl"
+	"github.com/sirupsen/logrus"
 
+	"github.com/gogo/protobuf/proto"
 	google_protobuf "github.com/gogo/protobuf/types"
 	vc "github.com/kata-containers/runtime/virtcontainers"
 )
 
-func marshalMetrics(s *service, containerID string) (*google_protobuf.Any, error) {
-	stats, err := s.sandbox.StatsContainer(containerID)
+// processStatsField is the protobuf field number the stats of the running
+// execs are appended to the container metrics with. The field is unknown
+// to the metrics message, the consumers not aware of it skip it. Each exec
+// is reported as a message made of:
+//
+//	1: exec ID (string)
+//	2: PID in the VM (uint64)
+//	3: user and system CPU time in nanoseconds (uint64)
+//	4: resident set size in bytes (uint64)
+//	5: bytes read from storage (uint64)
+//	6: bytes written to storage (uint64)
+//
+// The resources are the ones used by the exec process and its descendants.
+const processStatsField = 1000
+
+func marshalMetrics(s *service, c *container) (*google_protobuf.Any, error) {
+	stats, err := s.sandbox.StatsContainer(c.id)
 	if err != nil {
 		return nil, err
 	}
@@ -26,9 +46,80 @@ func marshalMetrics(s *service, containerID string) (*google_protobuf.Any, error
 		return nil, err
 	}
 
+	data.Value = append(data.Value, marshalProcessStats(s, c)...)
+
 	return data, nil
 }
 
+// marshalProcessStats returns the stats of the running execs of a
+// container, encoded as processStatsField fields.
+func marshalProcessStats(s *service, c *container) []byte {
+	var execIDs []string
+	for execID, e := range c.execs {
+		if e.status == task.StatusRunning && e.guestPid != 0 {
+			execIDs = append(execIDs, execID)
+		}
+	}
+	if len(execIDs) == 0 {
+		return nil
+	}
+	sort.Strings(execIDs)
+
+	stats, err := s.sandbox.ProcessStats(c.id)
+	if err != nil {
+		logrus.WithError(err).WithField("container", c.id).Debug("failed to get exec stats")
+		return nil
+	}
+
+	execStats := make(map[string]*vc.ProcessStats)
+	processes := execProcesses(c, stats)
+	for _, st := range stats {
+		execID, ok := processes[st.GuestPid]
+		if !ok {
+			continue
+		}
+
+		es := execStats[execID]
+		if es == nil {
+			es = &vc.ProcessStats{GuestPid: c.execs[execID].guestPid}
+			execStats[execID] = es
+		}
+		es.CPUTime += st.CPUTime
+		es.RSS += st.RSS
+		es.ReadBytes += st.ReadBytes
+		es.WriteBytes += st.WriteBytes
+	}
+
+	buf := proto.NewBuffer(nil)
+	for _, execID := range execIDs {
+		es, ok := execStats[execID]
+		if !ok {
+			// The exec process is gone, its exit is not waited
+			// for yet.
+			continue
+		}
+
+		entry := proto.NewBuffer(nil)
+		entry.EncodeVarint(1<<3 | proto.WireBytes)
+		entry.EncodeStringBytes(execID)
+		for i, v := range []uint64{
+			uint64(es.GuestPid),
+			uint64(es.CPUTime),
+			es.RSS,
+			es.ReadBytes,
+			es.WriteBytes,
+		} {
+			entry.EncodeVarint(uint64(i+2)<<3 | proto.WireVarint)
+			entry.EncodeVarint(v)
+		}
+
+		buf.EncodeVarint(processStatsField<<3 | proto.WireBytes)
+		buf.EncodeRawBytes(entry.Bytes())
+	}
+
+	return buf.Bytes()
+}
+
 func statsToMetrics(stats *vc.ContainerStats) *cgroups.Metrics {
 	metrics := &cgroups.Metrics{}
 
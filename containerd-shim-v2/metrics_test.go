@@ -9,10 +9,14 @@ package containerdshim
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/containerd/cgroups"
+	"github.com/containerd/containerd/api/types/task"
 	"github.com/containerd/containerd/namespaces"
+	"github.com/gogo/protobuf/proto"
 	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/vcmock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -54,3 +58,65 @@ func TestStatNetworkMetric(t *testing.T) {
 	metrics := statsToMetrics(&resp)
 	assert.Equal(expectedNetwork, metrics.Network)
 }
+
+func TestMarshalProcessStats(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &service{
+		id: testSandboxID,
+		sandbox: &processStatsSandbox{
+			Sandbox: &vcmock.Sandbox{MockID: testSandboxID},
+			stats: []vc.ProcessStats{
+				{GuestPid: 1, CPUTime: time.Second},
+				{GuestPid: 42, CPUTime: time.Second, RSS: 4096},
+				{GuestPid: 43, GuestPPid: 42, CPUTime: 2 * time.Second, RSS: 1024, WriteBytes: 512},
+				{GuestPid: 44, ReadBytes: 256},
+			},
+		},
+	}
+
+	c := &container{
+		id: testContainerID,
+		execs: map[string]*exec{
+			"running": {id: "token", guestPid: 42, status: task.StatusRunning},
+			"stopped": {id: "token2", guestPid: 44, status: task.StatusStopped},
+		},
+	}
+
+	buf := proto.NewBuffer(marshalProcessStats(s, c))
+
+	// only the running exec is reported
+	key, err := buf.DecodeVarint()
+	assert.NoError(err)
+	assert.Equal(uint64(processStatsField<<3|proto.WireBytes), key)
+
+	entry, err := buf.DecodeRawBytes(false)
+	assert.NoError(err)
+	_, err = buf.DecodeVarint()
+	assert.Error(err)
+
+	entryBuf := proto.NewBuffer(entry)
+	key, err = entryBuf.DecodeVarint()
+	assert.NoError(err)
+	assert.Equal(uint64(1<<3|proto.WireBytes), key)
+	execID, err := entryBuf.DecodeStringBytes()
+	assert.NoError(err)
+	assert.Equal("running", execID)
+
+	// along with the resources used by its descendants
+	var values []uint64
+	for i := 2; i <= 6; i++ {
+		key, err = entryBuf.DecodeVarint()
+		assert.NoError(err)
+		assert.Equal(uint64(i<<3|proto.WireVarint), key)
+
+		v, err := entryBuf.DecodeVarint()
+		assert.NoError(err)
+		values = append(values, v)
+	}
+	assert.Equal([]uint64{42, uint64(3 * time.Second), 5120, 0, 512}, values)
+
+	// no exec running
+	c.execs["running"].status = task.StatusStopped
+	assert.Empty(marshalProcessStats(s, c))
+}
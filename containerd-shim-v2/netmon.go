@@ -0,0 +1,111 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/kata-containers/runtime/pkg/netmon"
+	vcTypes "github.com/kata-containers/runtime/virtcontainers/pkg/types"
+)
+
+// netmonHandler applies the network changes seen by the shim network
+// monitor to the sandbox. The changes are serialized with the requests
+// handled by the service, and fail while the sandbox VM restarts.
+type netmonHandler struct {
+	s *service
+}
+
+func (h *netmonHandler) AddInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	if err := h.s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
+	return h.s.sandbox.AddInterface(inf)
+}
+
+func (h *netmonHandler) RemoveInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	if err := h.s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
+	return h.s.sandbox.RemoveInterface(inf)
+}
+
+func (h *netmonHandler) UpdateInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	if err := h.s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
+	return h.s.sandbox.UpdateInterface(inf)
+}
+
+func (h *netmonHandler) UpdateRoutes(routes []*vcTypes.Route) ([]*vcTypes.Route, error) {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	if err := h.s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
+	return h.s.sandbox.UpdateRoutes(routes)
+}
+
+func (h *netmonHandler) AddNeighbors(neighs []*vcTypes.ARPNeighbor) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	if err := h.s.checkRestarting(); err != nil {
+		return err
+	}
+
+	return h.s.sandbox.AddNeighbors(neighs)
+}
+
+// startNetmon watches the network namespace of the sandbox from within the
+// shim, instead of running the kata-netmon binary which calls into the
+// kata-runtime CLI. There is nothing to watch when the sandbox does not
+// have a network namespace, e.g. with disable_new_netns.
+func startNetmon(s *service) error {
+	netNsPath := s.sandbox.GetNetNs()
+	if netNsPath == "" {
+		return nil
+	}
+
+	watcher, err := netmon.NewWatcher(netNsPath, &netmonHandler{s: s})
+	if err != nil {
+		return err
+	}
+	s.netmon = watcher
+
+	go func() {
+		if err := watcher.Run(); err != nil {
+			logrus.WithError(err).WithField("sandbox", s.sandbox.ID()).Error("network monitor failed")
+		}
+	}()
+
+	return nil
+}
+
+// stopNetmon stops watching the network namespace of the sandbox, if it is
+// watched.
+func stopNetmon(s *service) {
+	if s.netmon == nil {
+		return
+	}
+
+	s.netmon.Stop()
+	s.netmon = nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"testing"
+
+	"github.com/kata-containers/runtime/pkg/netmon"
+	vcTypes "github.com/kata-containers/runtime/virtcontainers/pkg/types"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/vcmock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartStopNetmon(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &service{
+		id: testSandboxID,
+		sandbox: &vcmock.Sandbox{
+			MockID: testSandboxID,
+		},
+	}
+
+	// Without a network namespace, there is nothing to watch.
+	assert.NoError(startNetmon(s))
+	assert.Nil(s.netmon)
+
+	s.sandbox = &vcmock.Sandbox{
+		MockID:    testSandboxID,
+		MockNetNs: netmon.CurrentNetNsPath,
+	}
+	assert.NoError(startNetmon(s))
+	assert.NotNil(s.netmon)
+
+	stopNetmon(s)
+	assert.Nil(s.netmon)
+
+	// Stopping again is a no-op.
+	stopNetmon(s)
+
+	s.sandbox = &vcmock.Sandbox{
+		MockID:    testSandboxID,
+		MockNetNs: "/foo/bar/netns",
+	}
+	assert.Error(startNetmon(s))
+	assert.Nil(s.netmon)
+}
+
+func TestNetmonHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &service{
+		id: testSandboxID,
+		sandbox: &vcmock.Sandbox{
+			MockID: testSandboxID,
+		},
+	}
+
+	h := &netmonHandler{s: s}
+
+	_, err := h.AddInterface(&vcTypes.Interface{})
+	assert.NoError(err)
+
+	_, err = h.RemoveInterface(&vcTypes.Interface{})
+	assert.NoError(err)
+
+	_, err = h.UpdateInterface(&vcTypes.Interface{})
+	assert.NoError(err)
+
+	_, err = h.UpdateRoutes([]*vcTypes.Route{})
+	assert.NoError(err)
+
+	err = h.AddNeighbors([]*vcTypes.ARPNeighbor{})
+	assert.NoError(err)
+}
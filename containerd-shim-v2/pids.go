@@ -0,0 +1,178 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"encoding/json"
+
+	"github.com/containerd/containerd/api/types/task"
+	"github.com/containerd/typeurl"
+	"github.com/sirupsen/logrus"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+)
+
+// GuestProcessDetails is the info of the processes reported by Pids. The
+// container processes run in the VM, the host only knows the shim PID:
+// each process is reported with the shim PID, its PID in the VM being
+// given along in the details.
+type GuestProcessDetails struct {
+	GuestPid uint32 `json:"guest_pid"`
+	// Init tells whether the process is the container init one.
+	Init bool `json:"init,omitempty"`
+	// ExecID is the ID of the exec the process is, or descends from, if
+	// any.
+	ExecID string `json:"exec_id,omitempty"`
+	// Stats are the resources used by the process, if the VM reports
+	// them.
+	Stats *GuestProcessStats `json:"stats,omitempty"`
+}
+
+// GuestProcessStats are the resources used by a process in the VM.
+type GuestProcessStats struct {
+	CPUTimeNs  uint64 `json:"cpu_time_ns"`
+	RSSBytes   uint64 `json:"rss_bytes"`
+	ReadBytes  uint64 `json:"read_bytes"`
+	WriteBytes uint64 `json:"write_bytes"`
+}
+
+func init() {
+	typeurl.Register(&GuestProcessDetails{}, "io.katacontainers.task", "GuestProcessDetails")
+}
+
+func newGuestProcessInfo(shimPid uint32, details *GuestProcessDetails) (*task.ProcessInfo, error) {
+	info, err := typeurl.MarshalAny(details)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task.ProcessInfo{
+		Pid:  shimPid,
+		Info: info,
+	}, nil
+}
+
+// containerProcesses returns the processes of a container: the init one,
+// followed by the other ones the agent lists in the VM. The processes are
+// reported with their stats and, for the execs and their descendants, the
+// exec ID, if the VM can list them.
+func containerProcesses(s *service, c *container) ([]*task.ProcessInfo, error) {
+	status, err := s.sandbox.StatusContainer(c.id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.sandbox.ProcessStats(c.id)
+	if err != nil {
+		logrus.WithError(err).WithField("container", c.id).Debug("failed to get container process stats")
+		return containerPids(s, c.id, status.GuestPID)
+	}
+
+	execIDs := execProcesses(c, stats)
+
+	details := []*GuestProcessDetails{{GuestPid: uint32(status.GuestPID), Init: true}}
+	for _, st := range stats {
+		d := details[0]
+		if st.GuestPid != status.GuestPID {
+			d = &GuestProcessDetails{
+				GuestPid: uint32(st.GuestPid),
+				ExecID:   execIDs[st.GuestPid],
+			}
+			details = append(details, d)
+		}
+
+		d.Stats = &GuestProcessStats{
+			CPUTimeNs:  uint64(st.CPUTime),
+			RSSBytes:   st.RSS,
+			ReadBytes:  st.ReadBytes,
+			WriteBytes: st.WriteBytes,
+		}
+	}
+
+	var processes []*task.ProcessInfo
+	for _, d := range details {
+		pInfo, err := newGuestProcessInfo(s.pid, d)
+		if err != nil {
+			return nil, err
+		}
+		processes = append(processes, pInfo)
+	}
+
+	return processes, nil
+}
+
+// execProcesses returns the IDs of the running execs of a container the
+// processes in stats are, or descend from, by PID in the VM.
+func execProcesses(c *container, stats []vc.ProcessStats) map[int]string {
+	execIDs := make(map[int]string)
+	for execID, e := range c.execs {
+		if e.status == task.StatusRunning && e.guestPid != 0 {
+			execIDs[e.guestPid] = execID
+		}
+	}
+
+	ppids := make(map[int]int, len(stats))
+	for _, st := range stats {
+		ppids[st.GuestPid] = st.GuestPPid
+	}
+
+	processes := make(map[int]string)
+	for _, st := range stats {
+		// A process has no more ancestors than the listed processes.
+		pid := st.GuestPid
+		for i := 0; i <= len(stats) && pid != 0; i++ {
+			if execID, ok := execIDs[pid]; ok {
+				processes[st.GuestPid] = execID
+				break
+			}
+			pid = ppids[pid]
+		}
+	}
+
+	return processes
+}
+
+// containerPids returns the processes of a container, with their PIDs in
+// the VM only.
+func containerPids(s *service, containerID string, initPid int) ([]*task.ProcessInfo, error) {
+	pInfo, err := newGuestProcessInfo(s.pid, &GuestProcessDetails{
+		GuestPid: uint32(initPid),
+		Init:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	processes := []*task.ProcessInfo{pInfo}
+
+	processList, err := s.sandbox.ProcessListContainer(containerID, vc.ProcessListOptions{Format: "json"})
+	if err != nil {
+		// The init process is still worth reporting, e.g. when the
+		// container is not running.
+		logrus.WithError(err).WithField("container", containerID).Warn("failed to list container processes")
+		return processes, nil
+	}
+
+	var pids []int
+	if len(processList) > 0 {
+		if err := json.Unmarshal(processList, &pids); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pid := range pids {
+		if pid == initPid {
+			continue
+		}
+
+		pInfo, err := newGuestProcessInfo(s.pid, &GuestProcessDetails{GuestPid: uint32(pid)})
+		if err != nil {
+			return nil, err
+		}
+		processes = append(processes, pInfo)
+	}
+
+	return processes, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/api/types/task"
+	taskAPI "github.com/containerd/containerd/runtime/v2/task"
+	"github.com/containerd/typeurl"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/vcmock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPids(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &service{
+		id:         testSandboxID,
+		pid:        1234,
+		ctx:        context.Background(),
+		containers: make(map[string]*container),
+		sandbox: &vcmock.Sandbox{
+			MockID: testSandboxID,
+		},
+	}
+
+	ctx := context.Background()
+	req := &taskAPI.PidsRequest{ID: testContainerID}
+
+	_, err := s.Pids(ctx, req)
+	assert.Error(err)
+
+	s.containers[testContainerID] = &container{id: testContainerID}
+
+	resp, err := s.Pids(ctx, req)
+	assert.NoError(err)
+	assert.Len(resp.Processes, 1)
+	assert.Equal(s.pid, resp.Processes[0].Pid)
+
+	v, err := typeurl.UnmarshalAny(resp.Processes[0].Info)
+	assert.NoError(err)
+	details, ok := v.(*GuestProcessDetails)
+	assert.True(ok)
+	assert.True(details.Init)
+}
+
+type processStatsSandbox struct {
+	*vcmock.Sandbox
+	stats []vc.ProcessStats
+}
+
+func (s *processStatsSandbox) ProcessStats(containerID string) ([]vc.ProcessStats, error) {
+	return s.stats, nil
+}
+
+func TestPidsProcessStats(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &service{
+		id:         testSandboxID,
+		pid:        1234,
+		ctx:        context.Background(),
+		containers: make(map[string]*container),
+		sandbox: &processStatsSandbox{
+			Sandbox: &vcmock.Sandbox{MockID: testSandboxID},
+			stats: []vc.ProcessStats{
+				{GuestPid: 0, CPUTime: time.Second},
+				{GuestPid: 42, RSS: 4096},
+				{GuestPid: 43, GuestPPid: 42, WriteBytes: 512},
+				{GuestPid: 44, ReadBytes: 256},
+				{GuestPid: 45, GuestPPid: 44},
+			},
+		},
+	}
+
+	s.containers[testContainerID] = &container{
+		id: testContainerID,
+		execs: map[string]*exec{
+			"exec":    {id: "token", guestPid: 42, status: task.StatusRunning},
+			"stopped": {id: "token2", guestPid: 44, status: task.StatusStopped},
+		},
+	}
+
+	resp, err := s.Pids(context.Background(), &taskAPI.PidsRequest{ID: testContainerID})
+	assert.NoError(err)
+	assert.Len(resp.Processes, 5)
+
+	var details []*GuestProcessDetails
+	for _, p := range resp.Processes {
+		assert.Equal(s.pid, p.Pid)
+
+		v, err := typeurl.UnmarshalAny(p.Info)
+		assert.NoError(err)
+		details = append(details, v.(*GuestProcessDetails))
+	}
+
+	assert.Equal([]*GuestProcessDetails{
+		{Init: true, Stats: &GuestProcessStats{CPUTimeNs: uint64(time.Second)}},
+		{GuestPid: 42, ExecID: "exec", Stats: &GuestProcessStats{RSSBytes: 4096}},
+		// the descendants of an exec are attributed to it
+		{GuestPid: 43, ExecID: "exec", Stats: &GuestProcessStats{WriteBytes: 512}},
+		// the PID of a stopped exec may be reused
+		{GuestPid: 44, Stats: &GuestProcessStats{ReadBytes: 256}},
+		{GuestPid: 45, Stats: &GuestProcessStats{}},
+	}, details)
+}
+
+func TestNewGuestProcessInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	pInfo, err := newGuestProcessInfo(1234, &GuestProcessDetails{GuestPid: 42})
+	assert.NoError(err)
+	assert.Equal(uint32(1234), pInfo.Pid)
+
+	v, err := typeurl.UnmarshalAny(pInfo.Info)
+	assert.NoError(err)
+	assert.Equal(&GuestProcessDetails{GuestPid: 42}, v)
+}
@@ -31,6 +31,7 @@ import (
 	"golang.org/x/sys/unix"
 
 	"github.com/kata-containers/runtime/pkg/katautils"
+	"github.com/kata-containers/runtime/pkg/netmon"
 	vc "github.com/kata-containers/runtime/virtcontainers"
 	"github.com/kata-containers/runtime/virtcontainers/pkg/compatoci"
 	"github.com/kata-containers/runtime/virtcontainers/pkg/oci"
@@ -46,6 +47,15 @@ const (
 	chSize      = 128
 	exitCode255 = 255
 
+	// exitCodeVMRestart is the exit status reported for the processes
+	// that went away with a restarted sandbox VM.
+	exitCodeVMRestart = 254
+
+	// vmRestartTimeout is how long the waiter of a process that went
+	// away with the VM of a restartable sandbox waits for the VM to be
+	// restarted.
+	vmRestartTimeout = 1 * time.Minute
+
 	// A time span used to wait for publish a containerd event,
 	// once it costs a longer time than timeOut, it will be canceld.
 	timeOut = 5 * time.Second
@@ -72,6 +82,7 @@ func New(ctx context.Context, id string, publisher events.Publisher) (cdshim.Shi
 	}
 	vci.SetLogger(ctx, logger)
 	katautils.SetLogger(ctx, logger, logger.Logger.Level)
+	netmon.SetLogger(logger)
 
 	// load runtime config so that tracing can start if enabled
 	_, runtimeConfig, err := katautils.LoadConfiguration("", false, true)
@@ -91,14 +102,15 @@ func New(ctx context.Context, id string, publisher events.Publisher) (cdshim.Shi
 	ctx, cancel := context.WithCancel(ctx)
 
 	s := &service{
-		id:         id,
-		pid:        uint32(os.Getpid()),
-		ctx:        ctx,
-		containers: make(map[string]*container),
-		config:     &runtimeConfig,
-		events:     make(chan interface{}, chSize),
-		ec:         make(chan exit, bufferSize),
-		cancel:     cancel,
+		id:          id,
+		pid:         uint32(os.Getpid()),
+		ctx:         ctx,
+		containers:  make(map[string]*container),
+		config:      &runtimeConfig,
+		events:      make(chan interface{}, chSize),
+		ec:          make(chan exit, bufferSize),
+		vmRestarted: make(chan struct{}),
+		cancel:      cancel,
 	}
 
 	go s.processExits()
@@ -133,6 +145,26 @@ type service struct {
 	events     chan interface{}
 	monitor    chan error
 
+	// netmon watches the network namespace of the sandbox, when the
+	// network monitor is enabled.
+	netmon *netmon.Watcher
+
+	// restartable is set if the sandbox VM may be restarted by the
+	// monitor, see oci.IsRestartableSandbox.
+	restartable bool
+	// vmRestarted is closed, and replaced, each time a sandbox VM
+	// restart is over.
+	vmRestarted chan struct{}
+	// restarting is set while the sandbox VM reboots, the service
+	// lock being released meanwhile, see checkRestarting.
+	restarting bool
+	// restartLock serializes the sandbox VM restarts.
+	restartLock sync.Mutex
+
+	// oomCancel stops the OOM events watcher of the sandbox VM, each
+	// VM having its own.
+	oomCancel context.CancelFunc
+
 	cancel func()
 
 	ec chan exit
@@ -271,7 +303,7 @@ func (s *service) sendL(evt interface{}) {
 }
 
 func getTopic(e interface{}) string {
-	switch e.(type) {
+	switch evt := e.(type) {
 	case *eventstypes.TaskCreate:
 		return cdruntime.TaskCreateEventTopic
 	case *eventstypes.TaskStart:
@@ -292,6 +324,8 @@ func getTopic(e interface{}) string {
 		return cdruntime.TaskResumedEventTopic
 	case *eventstypes.TaskCheckpointed:
 		return cdruntime.TaskCheckpointedEventTopic
+	case *SandboxEvent:
+		return sandboxEventTopicPrefix + evt.Type
 	default:
 		logrus.Warnf("no topic for type %#v", e)
 	}
@@ -379,6 +413,10 @@ func (s *service) Create(ctx context.Context, r *taskAPI.CreateTaskRequest) (_ *
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
 	type Result struct {
 		container *container
 		err       error
@@ -433,6 +471,10 @@ func (s *service) Start(ctx context.Context, r *taskAPI.StartRequest) (_ *taskAP
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
 	c, err := s.getContainer(r.ID)
 	if err != nil {
 		return nil, err
@@ -482,6 +524,10 @@ func (s *service) Delete(ctx context.Context, r *taskAPI.DeleteRequest) (_ *task
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
 	c, err := s.getContainer(r.ID)
 	if err != nil {
 		return nil, err
@@ -568,6 +614,10 @@ func (s *service) ResizePty(ctx context.Context, r *taskAPI.ResizePtyRequest) (_
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
 	c, err := s.getContainer(r.ID)
 	if err != nil {
 		return nil, err
@@ -655,6 +705,10 @@ func (s *service) Pause(ctx context.Context, r *taskAPI.PauseRequest) (_ *ptypes
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
 	c, err := s.getContainer(r.ID)
 	if err != nil {
 		return nil, err
@@ -692,6 +746,10 @@ func (s *service) Resume(ctx context.Context, r *taskAPI.ResumeRequest) (_ *ptyp
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
 	c, err := s.getContainer(r.ID)
 	if err != nil {
 		return nil, err
@@ -727,6 +785,10 @@ func (s *service) Kill(ctx context.Context, r *taskAPI.KillRequest) (_ *ptypes.E
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
 	signum := syscall.Signal(r.Signal)
 
 	c, err := s.getContainer(r.ID)
@@ -769,22 +831,33 @@ func (s *service) Kill(ctx context.Context, r *taskAPI.KillRequest) (_ *ptypes.E
 }
 
 // Pids returns all pids inside the container
-// Since for kata, it cannot get the process's pid from VM,
-// thus only return the Shim's pid directly.
+// Since for kata, the container processes run in the VM, they
+// are all reported with the Shim's pid, their pids in the VM
+// being given in the process info, see GuestProcessDetails.
 func (s *service) Pids(ctx context.Context, r *taskAPI.PidsRequest) (_ *taskAPI.PidsResponse, err error) {
 	span, _ := trace(s.ctx, "Pids")
 	defer span.Finish()
 
-	var processes []*task.ProcessInfo
-
 	defer func() {
 		err = toGRPC(err)
 	}()
 
-	pInfo := task.ProcessInfo{
-		Pid: s.pid,
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
+	c, err := s.getContainer(r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	processes, err := containerProcesses(s, c)
+	if err != nil {
+		return nil, err
 	}
-	processes = append(processes, &pInfo)
 
 	return &taskAPI.PidsResponse{
 		Processes: processes,
@@ -839,7 +912,34 @@ func (s *service) Checkpoint(ctx context.Context, r *taskAPI.CheckpointTaskReque
 		err = toGRPC(err)
 	}()
 
-	return nil, errdefs.ToGRPCf(errdefs.ErrNotImplemented, "service Checkpoint")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
+	c, err := s.getContainer(r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The checkpoint is a snapshot of the whole VM, thus only the
+	// sandbox can be checkpointed, not one of its containers alone.
+	if c.cType != vc.PodSandbox {
+		return nil, errdefs.ToGRPCf(errdefs.ErrNotImplemented, "checkpoint of container %s, only sandbox %s can be checkpointed", c.id, s.sandbox.ID())
+	}
+
+	if err = s.sandbox.Checkpoint(r.Path); err != nil {
+		return nil, err
+	}
+
+	s.send(&eventstypes.TaskCheckpointed{
+		ContainerID: c.id,
+		Checkpoint:  r.Path,
+	})
+
+	return empty, nil
 }
 
 // Connect returns shim information such as the shim's pid
@@ -856,7 +956,8 @@ func (s *service) Connect(ctx context.Context, r *taskAPI.ConnectRequest) (_ *ta
 
 	return &taskAPI.ConnectResponse{
 		ShimPid: s.pid,
-		//Since kata cannot get the container's pid in VM, thus only return the shim's pid.
+		//Since the container's pid in VM is no host pid, only return the shim's pid,
+		//the pid in VM is reported by Pids.
 		TaskPid: s.pid,
 	}, nil
 }
@@ -898,12 +999,16 @@ func (s *service) Stats(ctx context.Context, r *taskAPI.StatsRequest) (_ *taskAP
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
 	c, err := s.getContainer(r.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := marshalMetrics(s, c.id)
+	data, err := marshalMetrics(s, c)
 	if err != nil {
 		return nil, err
 	}
@@ -925,6 +1030,10 @@ func (s *service) Update(ctx context.Context, r *taskAPI.UpdateTaskRequest) (_ *
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkRestarting(); err != nil {
+		return nil, err
+	}
+
 	var resources *specs.LinuxResources
 	v, err := typeurl.UnmarshalAny(r.Resources)
 	if err != nil {
@@ -1011,6 +1120,16 @@ func (s *service) checkProcesses(e exit) {
 	})
 }
 
+// checkRestarting fails the requests using the sandbox while its VM
+// restarts. The service lock must be held.
+func (s *service) checkRestarting() error {
+	if s.restarting {
+		return errdefs.ToGRPCf(errdefs.ErrUnavailable, "sandbox %s VM is restarting", s.id)
+	}
+
+	return nil
+}
+
 func (s *service) getContainer(id string) (*container, error) {
 	c := s.containers[id]
 
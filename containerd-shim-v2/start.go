@@ -39,9 +39,7 @@ func startContainer(ctx context.Context, s *service, c *container) error {
 		}
 		go watchSandbox(s)
 
-		// We don't rely on the context passed to startContainer as it can be cancelled after
-		// this rpc call.
-		go watchOOMEvents(s.ctx, s)
+		startOOMWatcher(s)
 	} else {
 		_, err := s.sandbox.StartContainer(c.id)
 		if err != nil {
@@ -61,6 +59,18 @@ func startContainer(ctx context.Context, s *service, c *container) error {
 
 	c.status = task.StatusRunning
 
+	if err := startContainerIO(ctx, s, c); err != nil {
+		return err
+	}
+
+	go wait(s, c, "")
+
+	return nil
+}
+
+// startContainerIO connects the container process IO streams to the
+// container ones.
+func startContainerIO(ctx context.Context, s *service, c *container) error {
 	stdin, stdout, stderr, err := s.sandbox.IOStream(c.id, c.id)
 	if err != nil {
 		return err
@@ -84,8 +94,6 @@ func startContainer(ctx context.Context, s *service, c *container) error {
 		close(c.stdinCloser)
 	}
 
-	go wait(s, c, "")
-
 	return nil
 }
 
@@ -107,6 +115,7 @@ func startExec(ctx context.Context, s *service, containerID, execID string) (*ex
 		return nil, err
 	}
 	execs.id = proc.Token
+	execs.guestPid = proc.GuestPid
 
 	execs.status = task.StatusRunning
 	if execs.tty.height != 0 && execs.tty.width != 0 {
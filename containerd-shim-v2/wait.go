@@ -26,9 +26,15 @@ func wait(s *service, c *container, execID string) (int32, error) {
 
 	processID := c.id
 
+	s.mu.Lock()
+	exitIOch := c.exitIOch
+	restarts := c.restarts
+	vmRestarted := s.vmRestarted
+	s.mu.Unlock()
+
 	if execID == "" {
 		//wait until the io closed, then wait the container
-		<-c.exitIOch
+		<-exitIOch
 	} else {
 		execs, err = c.getExec(execID)
 		if err != nil {
@@ -47,11 +53,25 @@ func wait(s *service, c *container, execID string) (int32, error) {
 			"container": c.id,
 			"pid":       processID,
 		}).Error("Wait for process failed")
+
+		if s.restartable {
+			waitVMRestart(vmRestarted)
+		}
 	}
 
 	timeStamp := time.Now()
 
 	s.mu.Lock()
+	if c.restarts != restarts {
+		if execID == "" {
+			// The container process went away with the sandbox VM and
+			// got restarted, it has its own waiter.
+			s.mu.Unlock()
+			return ret, nil
+		}
+		ret = exitCodeVMRestart
+	}
+
 	if execID == "" {
 		// Take care of the use case where it is a sandbox.
 		// Right after the container representing the sandbox has
@@ -63,6 +83,7 @@ func wait(s *service, c *container, execID string) (int32, error) {
 			if s.monitor != nil {
 				s.monitor <- nil
 			}
+			stopNetmon(s)
 			if err = s.sandbox.Stop(true); err != nil {
 				logrus.WithField("sandbox", s.sandbox.ID()).Error("failed to stop sandbox")
 			}
@@ -99,9 +120,15 @@ func watchSandbox(s *service) {
 	if s.monitor == nil {
 		return
 	}
-	err := <-s.monitor
-	if err == nil {
-		return
+
+	var err error
+	for {
+		if err = <-s.monitor; err == nil {
+			return
+		}
+		if !handleSandboxFailure(s, err) {
+			break
+		}
 	}
 	s.monitor = nil
 
@@ -109,6 +136,7 @@ func watchSandbox(s *service) {
 	defer s.mu.Unlock()
 	// sandbox malfunctioning, cleanup as much as we can
 	logrus.WithError(err).Warn("sandbox stopped unexpectedly")
+	stopNetmon(s)
 	err = s.sandbox.Stop(true)
 	if err != nil {
 		logrus.WithError(err).Warn("stop sandbox failed")
@@ -133,6 +161,119 @@ func watchSandbox(s *service) {
 	// No need to send async events here.
 }
 
+// handleSandboxFailure takes the action the sandbox monitor asks for
+// on err. It returns false if the sandbox has to be stopped.
+func handleSandboxFailure(s *service, err error) bool {
+	merr, ok := err.(*vc.MonitorError)
+	if !ok {
+		return false
+	}
+
+	switch merr.Action {
+	case vc.MonitorActionReport:
+		logrus.WithError(err).Warn("sandbox health check failed")
+		return true
+	case vc.MonitorActionRestartVM:
+		logrus.WithError(err).Warn("sandbox health check failed, restarting sandbox VM")
+		if err := restartSandboxVM(s); err != nil {
+			logrus.WithError(err).Error("restart sandbox VM failed")
+			return false
+		}
+		return true
+	}
+
+	return false
+}
+
+// restartSandboxVM restarts the sandbox VM, and waits again for the
+// container processes started in the new VM. The exits of the previous
+// processes of the containers, but the sandbox one, are reported with
+// exitCodeVMRestart. The service lock is not held while the VM reboots,
+// the requests using the sandbox fail meanwhile. Restarts are serialized
+// by restartLock.
+func restartSandboxVM(s *service) error {
+	s.restartLock.Lock()
+	defer s.restartLock.Unlock()
+
+	// The waiters of the processes going away with the VM must neither
+	// stop the sandbox nor report the containers as exited.
+	s.mu.Lock()
+	var restarted []*container
+	for _, c := range s.containers {
+		if c.status == task.StatusRunning || c.status == task.StatusPaused {
+			c.restarts++
+			restarted = append(restarted, c)
+		}
+	}
+	s.restarting = true
+	s.mu.Unlock()
+
+	err := s.sandbox.RestartVM()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.restarting = false
+
+	if s.vmRestarted != nil {
+		close(s.vmRestarted)
+	}
+	if err != nil {
+		for _, c := range restarted {
+			c.restarts--
+		}
+		// vmRestarted is left closed, the sandbox is stopped.
+		return err
+	}
+	s.vmRestarted = make(chan struct{})
+
+	exitTime := time.Now()
+	for _, c := range restarted {
+		c.exitIOch = make(chan struct{})
+		c.stdinCloser = make(chan struct{})
+		if err := startContainerIO(s.ctx, s, c); err != nil {
+			logrus.WithError(err).WithField("container", c.id).Warn("failed to connect container IO in restarted VM")
+			close(c.exitIOch)
+		}
+
+		go wait(s, c, "")
+
+		if !c.cType.IsSandbox() {
+			go cReap(s, exitCodeVMRestart, c.id, "", exitTime)
+		}
+	}
+
+	startOOMWatcher(s)
+
+	return nil
+}
+
+// startOOMWatcher watches the OOM events of the sandbox VM, stopping the
+// watcher of the previous VM if any. The service lock must be held.
+func startOOMWatcher(s *service) {
+	if s.oomCancel != nil {
+		s.oomCancel()
+	}
+
+	// We don't rely on the context of the request starting the sandbox
+	// as it can be cancelled after the request.
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.oomCancel = cancel
+
+	go watchOOMEvents(ctx, s)
+}
+
+// waitVMRestart waits, for vmRestartTimeout at most, for the VM the
+// container process went away with to be restarted. vmRestarted is the
+// one of the service when the process was waited for, it is already
+// closed if the VM got restarted since.
+func waitVMRestart(vmRestarted chan struct{}) {
+	select {
+	case <-vmRestarted:
+	case <-time.After(vmRestartTimeout):
+	}
+}
+
 func watchOOMEvents(ctx context.Context, s *service) {
 	if s.sandbox == nil {
 		return
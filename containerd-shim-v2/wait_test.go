@@ -0,0 +1,168 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/api/types/task"
+	"github.com/containerd/containerd/errdefs"
+	taskAPI "github.com/containerd/containerd/runtime/v2/task"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/vcmock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSandboxFailure(t *testing.T) {
+	assert := assert.New(t)
+	var err error
+
+	s := &service{
+		id:          testSandboxID,
+		ctx:         context.Background(),
+		containers:  make(map[string]*container),
+		ec:          make(chan exit, bufferSize),
+		vmRestarted: make(chan struct{}),
+		sandbox: &vcmock.Sandbox{
+			MockID: testSandboxID,
+		},
+	}
+
+	failure := errors.New("failed to ping agent")
+
+	assert.False(handleSandboxFailure(s, failure))
+
+	assert.True(handleSandboxFailure(s, &vc.MonitorError{
+		Action: vc.MonitorActionReport,
+		Err:    failure,
+	}))
+
+	assert.False(handleSandboxFailure(s, &vc.MonitorError{
+		Action: vc.MonitorActionStop,
+		Err:    failure,
+	}))
+
+	restartVM := &vc.MonitorError{
+		Action: vc.MonitorActionRestartVM,
+		Err:    failure,
+	}
+
+	// nothing running to restart, the OOM events watcher of the
+	// previous VM is stopped
+	oomStopped := false
+	s.oomCancel = func() { oomStopped = true }
+	vmRestarted := s.vmRestarted
+	assert.True(handleSandboxFailure(s, restartVM))
+	assert.NotEqual(vmRestarted, s.vmRestarted)
+	assert.True(oomStopped)
+	assert.NotNil(s.oomCancel)
+
+	reqCreate := &taskAPI.CreateTaskRequest{
+		ID: testSandboxID,
+	}
+	s.containers[testSandboxID], err = newContainer(s, reqCreate, vc.PodSandbox, nil, true)
+	assert.NoError(err)
+	s.containers[testSandboxID].status = task.StatusRunning
+
+	reqCreate = &taskAPI.CreateTaskRequest{
+		ID: testContainerID,
+	}
+	s.containers[testContainerID], err = newContainer(s, reqCreate, vc.PodContainer, nil, true)
+	assert.NoError(err)
+	s.containers[testContainerID].status = task.StatusRunning
+
+	assert.True(handleSandboxFailure(s, restartVM))
+	assert.Equal(uint32(1), s.containers[testSandboxID].restarts)
+	assert.Equal(uint32(1), s.containers[testContainerID].restarts)
+
+	// Only the container process restart is reported, the mock sandbox
+	// has the new processes exit right away.
+	var restartExits []exit
+	for i := 0; i < 3; i++ {
+		e := <-s.ec
+		if e.status == exitCodeVMRestart {
+			restartExits = append(restartExits, e)
+		}
+	}
+	assert.Len(restartExits, 1)
+	assert.Equal(testContainerID, restartExits[0].id)
+}
+
+func TestWaitVMRestart(t *testing.T) {
+	assert := assert.New(t)
+
+	vmRestarted := make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		waitVMRestart(vmRestarted)
+		close(done)
+	}()
+
+	close(vmRestarted)
+
+	// already restarted
+	waitVMRestart(vmRestarted)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		assert.Fail("waitVMRestart did not return on VM restart")
+	}
+}
+
+type restartSandbox struct {
+	*vcmock.Sandbox
+	restartVM func() error
+}
+
+func (s *restartSandbox) RestartVM() error {
+	return s.restartVM()
+}
+
+func TestRestartSandboxVMUnlocked(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &service{
+		id:          testSandboxID,
+		ctx:         context.Background(),
+		containers:  make(map[string]*container),
+		ec:          make(chan exit, bufferSize),
+		vmRestarted: make(chan struct{}),
+	}
+
+	// the service is not locked while the VM reboots, the requests
+	// using the sandbox fail meanwhile
+	s.sandbox = &restartSandbox{
+		Sandbox: &vcmock.Sandbox{MockID: testSandboxID},
+		restartVM: func() error {
+			_, err := s.Stats(context.Background(), &taskAPI.StatsRequest{ID: testContainerID})
+			assert.True(errdefs.IsUnavailable(errdefs.FromGRPC(err)))
+
+			_, err = s.State(context.Background(), &taskAPI.StateRequest{ID: testContainerID})
+			assert.True(errdefs.IsNotFound(errdefs.FromGRPC(err)))
+
+			return errors.New("restart failure")
+		},
+	}
+
+	vmRestarted := s.vmRestarted
+	assert.Error(restartSandboxVM(s))
+
+	// the waiters are released, and the sandbox is left to be stopped
+	select {
+	case <-vmRestarted:
+	default:
+		assert.Fail("vmRestarted not closed on restart failure")
+	}
+	assert.Equal(vmRestarted, s.vmRestarted)
+	assert.False(s.restarting)
+}
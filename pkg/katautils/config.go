@@ -10,14 +10,18 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"regexp"
 	goruntime "runtime"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	govmmQemu "github.com/kata-containers/govmm/qemu"
 	vc "github.com/kata-containers/runtime/virtcontainers"
 	"github.com/kata-containers/runtime/virtcontainers/device/config"
 	exp "github.com/kata-containers/runtime/virtcontainers/experimental"
+	"github.com/kata-containers/runtime/virtcontainers/factory/grpccache"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/mtls"
 	"github.com/kata-containers/runtime/virtcontainers/pkg/oci"
 	"github.com/kata-containers/runtime/virtcontainers/utils"
 	"github.com/sirupsen/logrus"
@@ -34,6 +38,19 @@ var (
 
 	// if true, enable opentracing support.
 	tracing = false
+
+	// factoryPoolNameRegexp matches the valid factory pool names, the
+	// name of a pool being the one of its template directory.
+	factoryPoolNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	// reservedFactoryPoolNames are the names of the files of the default
+	// VM template, stored in the directory of the pool templates.
+	reservedFactoryPoolNames = map[string]bool{
+		"state":         true,
+		"state.mem":     true,
+		"memory":        true,
+		"manifest.json": true,
+	}
 )
 
 // The TOML configuration file contains a number of sections (or
@@ -78,10 +95,27 @@ type tomlConfig struct {
 }
 
 type factory struct {
-	Template        bool   `toml:"enable_template"`
-	TemplatePath    string `toml:"template_path"`
-	VMCacheNumber   uint   `toml:"vm_cache_number"`
-	VMCacheEndpoint string `toml:"vm_cache_endpoint"`
+	Template              bool          `toml:"enable_template"`
+	TemplatePath          string        `toml:"template_path"`
+	VMCacheNumber         uint          `toml:"vm_cache_number"`
+	VMCacheMaxNumber      uint          `toml:"vm_cache_max_number"`
+	VMCacheRefillInterval uint32        `toml:"vm_cache_refill_interval"`
+	VMCacheIdleTimeout    uint32        `toml:"vm_cache_idle_timeout"`
+	VMCacheEndpoint       string        `toml:"vm_cache_endpoint"`
+	VMCacheTLSCert        string        `toml:"vm_cache_tls_cert"`
+	VMCacheTLSKey         string        `toml:"vm_cache_tls_key"`
+	VMCacheTLSCA          string        `toml:"vm_cache_tls_ca"`
+	VMCacheClientQuota    uint          `toml:"vm_cache_client_quota"`
+	Pools                 []factoryPool `toml:"pool"`
+}
+
+type factoryPool struct {
+	Name             string `toml:"name"`
+	VMCacheNumber    uint   `toml:"vm_cache_number"`
+	VMCacheMaxNumber uint   `toml:"vm_cache_max_number"`
+	NumVCPUs         uint32 `toml:"default_vcpus"`
+	MemorySize       uint32 `toml:"default_memory"`
+	Kernel           string `toml:"kernel"`
 }
 
 type hypervisor struct {
@@ -117,6 +151,8 @@ type hypervisor struct {
 	NumVCPUs                int32    `toml:"default_vcpus"`
 	DefaultMaxVCPUs         uint32   `toml:"default_maxvcpus"`
 	MemorySize              uint32   `toml:"default_memory"`
+	DefaultMaxMemorySize    uint64   `toml:"default_maxmemory"`
+	SpareNetworkDevices     uint32   `toml:"spare_network_devices"`
 	MemSlots                uint32   `toml:"memory_slots"`
 	MemOffset               uint32   `toml:"memory_offset"`
 	DefaultBridges          uint32   `toml:"default_bridges"`
@@ -156,6 +192,14 @@ type runtime struct {
 	EnableAgentPidNs    bool     `toml:"enable_agent_pidns"`
 	Experimental        []string `toml:"experimental"`
 	InterNetworkModel   string   `toml:"internetworking_model"`
+	MonitorInterval     uint32   `toml:"monitor_interval"`
+	MonitorThreshold    uint32   `toml:"monitor_failure_threshold"`
+	MonitorGracePeriod  uint32   `toml:"monitor_grace_period"`
+	MonitorAction       string   `toml:"monitor_action"`
+	NetworkPolicy       string   `toml:"network_policy"`
+	MigrationTLSCert    string   `toml:"migration_tls_cert"`
+	MigrationTLSKey     string   `toml:"migration_tls_key"`
+	MigrationTLSCA      string   `toml:"migration_tls_ca"`
 }
 
 type shim struct {
@@ -596,6 +640,8 @@ func newFirecrackerHypervisorConfig(h hypervisor) (vc.HypervisorConfig, error) {
 		NumVCPUs:              h.defaultVCPUs(),
 		DefaultMaxVCPUs:       h.defaultMaxVCPUs(),
 		MemorySize:            h.defaultMemSz(),
+		DefaultMaxMemorySize:  h.DefaultMaxMemorySize,
+		SpareNetworkDevices:   h.SpareNetworkDevices,
 		MemSlots:              h.defaultMemSlots(),
 		EntropySource:         h.GetEntropySource(),
 		DefaultBridges:        h.defaultBridges(),
@@ -906,11 +952,67 @@ func newFactoryConfig(f factory) (oci.FactoryConfig, error) {
 	if f.VMCacheEndpoint == "" {
 		f.VMCacheEndpoint = defaultVMCacheEndpoint
 	}
+
+	for _, tlsFile := range []*string{&f.VMCacheTLSCert, &f.VMCacheTLSKey, &f.VMCacheTLSCA} {
+		if *tlsFile == "" {
+			continue
+		}
+
+		path, err := ResolvePath(*tlsFile)
+		if err != nil {
+			return oci.FactoryConfig{}, err
+		}
+		*tlsFile = path
+	}
+
+	var pools []oci.FactoryPoolConfig
+	names := make(map[string]bool)
+	for _, p := range f.Pools {
+		if p.Name == "" {
+			return oci.FactoryConfig{}, errors.New("Missing factory pool name")
+		}
+		if !factoryPoolNameRegexp.MatchString(p.Name) {
+			return oci.FactoryConfig{}, fmt.Errorf("Invalid factory pool name %q, only letters, digits, \"_\" and \"-\" are allowed", p.Name)
+		}
+		if reservedFactoryPoolNames[p.Name] {
+			return oci.FactoryConfig{}, fmt.Errorf("Invalid factory pool name %q, reserved for the VM template files", p.Name)
+		}
+		if names[p.Name] {
+			return oci.FactoryConfig{}, fmt.Errorf("Duplicated factory pool %s", p.Name)
+		}
+		names[p.Name] = true
+
+		kernel := p.Kernel
+		if kernel != "" {
+			var err error
+			if kernel, err = ResolvePath(kernel); err != nil {
+				return oci.FactoryConfig{}, err
+			}
+		}
+
+		pools = append(pools, oci.FactoryPoolConfig{
+			Name:             p.Name,
+			VMCacheNumber:    p.VMCacheNumber,
+			VMCacheMaxNumber: p.VMCacheMaxNumber,
+			NumVCPUs:         p.NumVCPUs,
+			MemorySize:       p.MemorySize,
+			KernelPath:       kernel,
+		})
+	}
+
 	return oci.FactoryConfig{
-		Template:        f.Template,
-		TemplatePath:    f.TemplatePath,
-		VMCacheNumber:   f.VMCacheNumber,
-		VMCacheEndpoint: f.VMCacheEndpoint,
+		Template:              f.Template,
+		TemplatePath:          f.TemplatePath,
+		VMCacheNumber:         f.VMCacheNumber,
+		VMCacheMaxNumber:      f.VMCacheMaxNumber,
+		VMCacheRefillInterval: time.Duration(f.VMCacheRefillInterval) * time.Millisecond,
+		VMCacheIdleTimeout:    time.Duration(f.VMCacheIdleTimeout) * time.Second,
+		VMCacheEndpoint:       f.VMCacheEndpoint,
+		VMCacheTLSCert:        f.VMCacheTLSCert,
+		VMCacheTLSKey:         f.VMCacheTLSKey,
+		VMCacheTLSCA:          f.VMCacheTLSCA,
+		VMCacheClientQuota:    f.VMCacheClientQuota,
+		Pools:                 pools,
 	}, nil
 }
 
@@ -1114,6 +1216,65 @@ func SetKernelParams(runtimeConfig *oci.RuntimeConfig) error {
 	return nil
 }
 
+func newMonitorConfig(r runtime) (vc.MonitorConfig, error) {
+	config := vc.MonitorConfig{
+		Interval:         time.Duration(r.MonitorInterval) * time.Second,
+		FailureThreshold: r.MonitorThreshold,
+		GracePeriod:      time.Duration(r.MonitorGracePeriod) * time.Second,
+	}
+
+	if r.MonitorAction != "" {
+		if err := config.Action.SetAction(r.MonitorAction); err != nil {
+			return vc.MonitorConfig{}, err
+		}
+	}
+
+	return config, nil
+}
+
+// newNetworkPolicy loads the network policy file of the runtime section, if
+// any.
+func newNetworkPolicy(r runtime) (*vc.NetworkPolicy, error) {
+	if r.NetworkPolicy == "" {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(r.NetworkPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return vc.ParseNetworkPolicy(data)
+}
+
+// newMigrationTLS returns the mutual TLS configuration of the live
+// migrations over tcp, which is none unless all its files are set.
+func newMigrationTLS(r runtime) (mtls.Config, error) {
+	config := mtls.Config{
+		CertFile: r.MigrationTLSCert,
+		KeyFile:  r.MigrationTLSKey,
+		CAFile:   r.MigrationTLSCA,
+	}
+
+	if !config.Enabled() {
+		return config, nil
+	}
+
+	if err := config.Check(); err != nil {
+		return mtls.Config{}, fmt.Errorf("Invalid migration TLS: %v", err)
+	}
+
+	for _, tlsFile := range []*string{&config.CertFile, &config.KeyFile, &config.CAFile} {
+		path, err := ResolvePath(*tlsFile)
+		if err != nil {
+			return mtls.Config{}, err
+		}
+		*tlsFile = path
+	}
+
+	return config, nil
+}
+
 func updateRuntimeConfig(configPath string, tomlConf tomlConfig, config *oci.RuntimeConfig, builtIn bool) error {
 	if err := updateRuntimeConfigHypervisor(configPath, tomlConf, config); err != nil {
 		return err
@@ -1137,6 +1298,24 @@ func updateRuntimeConfig(configPath string, tomlConf tomlConfig, config *oci.Run
 	}
 	config.FactoryConfig = fConfig
 
+	mConfig, err := newMonitorConfig(tomlConf.Runtime)
+	if err != nil {
+		return fmt.Errorf("%v: %v", configPath, err)
+	}
+	config.MonitorConfig = mConfig
+
+	policy, err := newNetworkPolicy(tomlConf.Runtime)
+	if err != nil {
+		return fmt.Errorf("%v: %v", configPath, err)
+	}
+	config.NetworkPolicy = policy
+
+	migrationTLS, err := newMigrationTLS(tomlConf.Runtime)
+	if err != nil {
+		return fmt.Errorf("%v: %v", configPath, err)
+	}
+	config.MigrationTLS = migrationTLS
+
 	config.NetmonConfig = vc.NetmonConfig{
 		Path:   tomlConf.Netmon.path(),
 		Debug:  tomlConf.Netmon.debug(),
@@ -1381,6 +1560,9 @@ func checkFactoryConfig(config oci.RuntimeConfig) error {
 		if config.AgentType != vc.KataContainersAgent {
 			return errors.New("VM cache just support kata agent")
 		}
+		if err := grpccache.CheckEndpoint(config.FactoryConfig.VMCacheEndpoint, FactoryVMCacheTLS(config)); err != nil {
+			return err
+		}
 	}
 
 	return nil
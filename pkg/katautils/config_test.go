@@ -18,9 +18,11 @@ import (
 	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	ktu "github.com/kata-containers/runtime/pkg/katatestutils"
 	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/mtls"
 	"github.com/kata-containers/runtime/virtcontainers/pkg/oci"
 	"github.com/kata-containers/runtime/virtcontainers/utils"
 	"github.com/stretchr/testify/assert"
@@ -905,6 +907,41 @@ func TestNewQemuHypervisorConfigImageAndInitrd(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestNewFirecrackerHypervisorConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpdir, err := ioutil.TempDir(testDir, "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmpdir)
+
+	hypervisorPath := path.Join(tmpdir, "hypervisor")
+	kernelPath := path.Join(tmpdir, "kernel")
+	imagePath := path.Join(tmpdir, "image")
+
+	for _, file := range []string{imagePath, hypervisorPath, kernelPath} {
+		err = createEmptyFile(file)
+		assert.NoError(err)
+	}
+
+	orgVHostVSockDevicePath := utils.VHostVSockDevicePath
+	defer func() {
+		utils.VHostVSockDevicePath = orgVHostVSockDevicePath
+	}()
+	utils.VHostVSockDevicePath = "/dev/null"
+
+	hypervisor := hypervisor{
+		Path:                 hypervisorPath,
+		Kernel:               kernelPath,
+		Image:                imagePath,
+		DefaultMaxMemorySize: 4096,
+		SpareNetworkDevices:  2,
+	}
+	config, err := newFirecrackerHypervisorConfig(hypervisor)
+	assert.NoError(err)
+	assert.Equal(uint64(4096), config.DefaultMaxMemorySize)
+	assert.Equal(uint32(2), config.SpareNetworkDevices)
+}
+
 func TestNewClhHypervisorConfig(t *testing.T) {
 
 	assert := assert.New(t)
@@ -1613,6 +1650,198 @@ func TestUpdateRuntimeConfigurationFactoryConfig(t *testing.T) {
 	assert.Equal(expectedFactoryConfig, config.FactoryConfig)
 }
 
+func TestUpdateRuntimeConfigurationFactoryCache(t *testing.T) {
+	assert := assert.New(t)
+
+	config := oci.RuntimeConfig{}
+	expectedFactoryConfig := oci.FactoryConfig{
+		TemplatePath:          defaultTemplatePath,
+		VMCacheNumber:         1,
+		VMCacheMaxNumber:      4,
+		VMCacheRefillInterval: 500 * time.Millisecond,
+		VMCacheIdleTimeout:    time.Minute,
+		VMCacheEndpoint:       defaultVMCacheEndpoint,
+	}
+
+	tomlConf := tomlConfig{Factory: factory{
+		VMCacheNumber:         1,
+		VMCacheMaxNumber:      4,
+		VMCacheRefillInterval: 500,
+		VMCacheIdleTimeout:    60,
+	}}
+
+	err := updateRuntimeConfig("", tomlConf, &config, false)
+	assert.NoError(err)
+	assert.Equal(expectedFactoryConfig, config.FactoryConfig)
+}
+
+func TestUpdateRuntimeConfigurationFactoryVMCacheTLS(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir(testDir, "")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+	ca := filepath.Join(dir, "ca.pem")
+	for _, file := range []string{cert, key, ca} {
+		assert.NoError(createEmptyFile(file))
+	}
+
+	config := oci.RuntimeConfig{}
+	expectedFactoryConfig := oci.FactoryConfig{
+		TemplatePath:       defaultTemplatePath,
+		VMCacheNumber:      1,
+		VMCacheEndpoint:    "tcp://127.0.0.1:7000",
+		VMCacheTLSCert:     cert,
+		VMCacheTLSKey:      key,
+		VMCacheTLSCA:       ca,
+		VMCacheClientQuota: 10,
+	}
+
+	tomlConf := tomlConfig{Factory: factory{
+		VMCacheNumber:      1,
+		VMCacheEndpoint:    "tcp://127.0.0.1:7000",
+		VMCacheTLSCert:     cert,
+		VMCacheTLSKey:      key,
+		VMCacheTLSCA:       ca,
+		VMCacheClientQuota: 10,
+	}}
+
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.NoError(err)
+	assert.Equal(expectedFactoryConfig, config.FactoryConfig)
+
+	tomlConf.Factory.VMCacheTLSCA = filepath.Join(dir, "foo.pem")
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.Error(err)
+}
+
+func TestUpdateRuntimeConfigurationMigrationTLS(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir(testDir, "")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+	ca := filepath.Join(dir, "ca.pem")
+	for _, file := range []string{cert, key, ca} {
+		assert.NoError(createEmptyFile(file))
+	}
+
+	config := oci.RuntimeConfig{}
+	tomlConf := tomlConfig{Factory: factory{
+		VMCacheTLSCert: cert,
+		VMCacheTLSKey:  key,
+		VMCacheTLSCA:   ca,
+	}}
+
+	// the VM cache TLS is not used for migrations
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.NoError(err)
+	assert.False(config.MigrationTLS.Enabled())
+
+	tomlConf.Runtime = runtime{
+		MigrationTLSCert: cert,
+		MigrationTLSKey:  key,
+		MigrationTLSCA:   ca,
+	}
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.NoError(err)
+	assert.Equal(mtls.Config{CertFile: cert, KeyFile: key, CAFile: ca}, config.MigrationTLS)
+
+	tomlConf.Runtime.MigrationTLSCA = ""
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.Error(err)
+
+	tomlConf.Runtime.MigrationTLSCA = filepath.Join(dir, "foo.pem")
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.Error(err)
+}
+
+func TestUpdateRuntimeConfigurationFactoryPools(t *testing.T) {
+	assert := assert.New(t)
+
+	config := oci.RuntimeConfig{}
+	expectedFactoryConfig := oci.FactoryConfig{
+		TemplatePath:    defaultTemplatePath,
+		VMCacheNumber:   2,
+		VMCacheEndpoint: defaultVMCacheEndpoint,
+		Pools: []oci.FactoryPoolConfig{
+			{Name: "small", VMCacheNumber: 4, NumVCPUs: 1, MemorySize: 256},
+			{Name: "large", VMCacheNumber: 1, NumVCPUs: 4, MemorySize: 4096},
+		},
+	}
+
+	tomlConf := tomlConfig{Factory: factory{
+		VMCacheNumber: 2,
+		Pools: []factoryPool{
+			{Name: "small", VMCacheNumber: 4, NumVCPUs: 1, MemorySize: 256},
+			{Name: "large", VMCacheNumber: 1, NumVCPUs: 4, MemorySize: 4096},
+		},
+	}}
+
+	err := updateRuntimeConfig("", tomlConf, &config, false)
+	assert.NoError(err)
+	assert.Equal(expectedFactoryConfig, config.FactoryConfig)
+
+	pools := FactoryPools(config)
+	assert.Len(pools, 2)
+	assert.Equal("large", pools[1].Name)
+	assert.Equal(uint(1), pools[1].Cache)
+	assert.Equal(uint32(4), pools[1].VMConfig.HypervisorConfig.NumVCPUs)
+	assert.Equal(uint32(4096), pools[1].VMConfig.HypervisorConfig.MemorySize)
+
+	tomlConf.Factory.Pools = append(tomlConf.Factory.Pools, factoryPool{Name: "small"})
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.Error(err)
+
+	tomlConf.Factory.Pools = []factoryPool{{VMCacheNumber: 1}}
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.Error(err)
+
+	// the pool names are template directory names
+	for _, name := range []string{"../large", "large/small", "..", "large pool", "state", "memory", "manifest.json"} {
+		tomlConf.Factory.Pools = []factoryPool{{Name: name}}
+		err = updateRuntimeConfig("", tomlConf, &config, false)
+		assert.Error(err, name)
+	}
+
+	tomlConf.Factory.Pools = []factoryPool{{Name: "large_pool-2"}}
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.NoError(err)
+}
+
+func TestUpdateRuntimeConfigurationMonitorConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	config := oci.RuntimeConfig{}
+	expectedMonitorConfig := vc.MonitorConfig{
+		Interval:         5 * time.Second,
+		FailureThreshold: 3,
+		GracePeriod:      30 * time.Second,
+		Action:           vc.MonitorActionRestartVM,
+	}
+
+	tomlConf := tomlConfig{Runtime: runtime{
+		MonitorInterval:    5,
+		MonitorThreshold:   3,
+		MonitorGracePeriod: 30,
+		MonitorAction:      "restart-vm",
+	}}
+
+	err := updateRuntimeConfig("", tomlConf, &config, false)
+	assert.NoError(err)
+	assert.Equal(expectedMonitorConfig, config.MonitorConfig)
+
+	tomlConf.Runtime.MonitorAction = "foo"
+	err = updateRuntimeConfig("", tomlConf, &config, false)
+	assert.Error(err)
+}
+
 func TestUpdateRuntimeConfigurationInvalidKernelParams(t *testing.T) {
 	assert := assert.New(t)
 
@@ -1805,6 +2034,33 @@ func TestCheckFactoryConfig(t *testing.T) {
 	}
 }
 
+func TestCheckFactoryConfigVMCache(t *testing.T) {
+	assert := assert.New(t)
+
+	config := oci.RuntimeConfig{
+		HypervisorType: vc.QemuHypervisor,
+		AgentType:      vc.KataContainersAgent,
+		FactoryConfig: oci.FactoryConfig{
+			VMCacheNumber:   1,
+			VMCacheEndpoint: defaultVMCacheEndpoint,
+		},
+	}
+	assert.NoError(checkFactoryConfig(config))
+
+	config.FactoryConfig.VMCacheEndpoint = "tcp://127.0.0.1:7000"
+	assert.Error(checkFactoryConfig(config))
+
+	config.FactoryConfig.VMCacheTLSCert = "/cert.pem"
+	config.FactoryConfig.VMCacheTLSKey = "/key.pem"
+	assert.Error(checkFactoryConfig(config))
+
+	config.FactoryConfig.VMCacheTLSCA = "/ca.pem"
+	assert.NoError(checkFactoryConfig(config))
+
+	config.FactoryConfig.VMCacheEndpoint = "http://127.0.0.1:7000"
+	assert.Error(checkFactoryConfig(config))
+}
+
 func TestCheckNetNsConfigShimTrace(t *testing.T) {
 	assert := assert.New(t)
 
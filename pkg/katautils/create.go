@@ -15,6 +15,7 @@ import (
 
 	vc "github.com/kata-containers/runtime/virtcontainers"
 	vf "github.com/kata-containers/runtime/virtcontainers/factory"
+	"github.com/kata-containers/runtime/virtcontainers/factory/grpccache"
 	"github.com/kata-containers/runtime/virtcontainers/pkg/oci"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 )
@@ -61,6 +62,7 @@ func HandleFactory(ctx context.Context, vci vc.VC, runtimeConfig *oci.RuntimeCon
 		TemplatePath:    runtimeConfig.FactoryConfig.TemplatePath,
 		VMCache:         runtimeConfig.FactoryConfig.VMCacheNumber > 0,
 		VMCacheEndpoint: runtimeConfig.FactoryConfig.VMCacheEndpoint,
+		VMCacheTLS:      FactoryVMCacheTLS(*runtimeConfig),
 		VMConfig: vc.VMConfig{
 			HypervisorType:   runtimeConfig.HypervisorType,
 			HypervisorConfig: runtimeConfig.HypervisorConfig,
@@ -69,6 +71,7 @@ func HandleFactory(ctx context.Context, vci vc.VC, runtimeConfig *oci.RuntimeCon
 			ProxyType:        runtimeConfig.ProxyType,
 			ProxyConfig:      runtimeConfig.ProxyConfig,
 		},
+		Pools: FactoryPools(*runtimeConfig),
 	}
 
 	kataUtilsLogger.WithField("factory", factoryConfig).Info("load vm factory")
@@ -86,6 +89,58 @@ func HandleFactory(ctx context.Context, vci vc.VC, runtimeConfig *oci.RuntimeCon
 	vci.SetFactory(ctx, f)
 }
 
+// FactoryPools returns the configurations of the VM factory pools, the
+// VMs of a pool being created from the hypervisor configuration updated
+// with the pool settings. The VMs of the pools are only cached by VMCache.
+func FactoryPools(runtimeConfig oci.RuntimeConfig) []vf.PoolConfig {
+	var pools []vf.PoolConfig
+
+	for _, p := range runtimeConfig.FactoryConfig.Pools {
+		var cache, cacheMax uint
+		if runtimeConfig.FactoryConfig.VMCacheNumber > 0 {
+			cache = p.VMCacheNumber
+			cacheMax = p.VMCacheMaxNumber
+		}
+
+		hypervisorConfig := runtimeConfig.HypervisorConfig
+		if p.NumVCPUs > 0 {
+			hypervisorConfig.NumVCPUs = p.NumVCPUs
+		}
+		if p.MemorySize > 0 {
+			hypervisorConfig.MemorySize = p.MemorySize
+		}
+		if p.KernelPath != "" {
+			hypervisorConfig.KernelPath = p.KernelPath
+		}
+
+		pools = append(pools, vf.PoolConfig{
+			Name:     p.Name,
+			Cache:    cache,
+			CacheMax: cacheMax,
+			VMConfig: vc.VMConfig{
+				HypervisorType:   runtimeConfig.HypervisorType,
+				HypervisorConfig: hypervisorConfig,
+				AgentType:        runtimeConfig.AgentType,
+				AgentConfig:      runtimeConfig.AgentConfig,
+				ProxyType:        runtimeConfig.ProxyType,
+				ProxyConfig:      runtimeConfig.ProxyConfig,
+			},
+		})
+	}
+
+	return pools
+}
+
+// FactoryVMCacheTLS returns the mutual TLS configuration of the VMCache
+// server and clients.
+func FactoryVMCacheTLS(runtimeConfig oci.RuntimeConfig) grpccache.TLSConfig {
+	return grpccache.TLSConfig{
+		CertFile: runtimeConfig.FactoryConfig.VMCacheTLSCert,
+		KeyFile:  runtimeConfig.FactoryConfig.VMCacheTLSKey,
+		CAFile:   runtimeConfig.FactoryConfig.VMCacheTLSCA,
+	}
+}
+
 // SetEphemeralStorageType sets the mount type to 'ephemeral'
 // if the mount source path is provisioned by k8s for ephemeral storage.
 // For the given pod ephemeral volume is created only once
@@ -0,0 +1,722 @@
+// Copyright (c) 2018 Intel Corporation
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package netmon
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	vcTypes "github.com/kata-containers/runtime/virtcontainers/pkg/types"
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// kataSuffix is the suffix of the interfaces created by Kata Containers,
+// which are ignored.
+const kataSuffix = "kata"
+
+var (
+	netlinkFamily = netlink.FAMILY_ALL
+
+	netmonLog = logrus.WithField("source", "netmon")
+)
+
+// SetLogger sets the logger for netmon package.
+func SetLogger(logger *logrus.Entry) {
+	fields := netmonLog.Data
+	netmonLog = logger.WithFields(fields)
+}
+
+// Handler applies the interfaces, addresses, routes and neighbors changes
+// seen by a Watcher. A virtcontainers.VCSandbox is a Handler.
+type Handler interface {
+	AddInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error)
+	RemoveInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error)
+	UpdateInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error)
+	UpdateRoutes(routes []*vcTypes.Route) ([]*vcTypes.Route, error)
+	AddNeighbors(neighs []*vcTypes.ARPNeighbor) error
+}
+
+// Watcher listens to the link, address, route and neighbor events of a
+// network namespace. Whenever an interface is added or removed, its
+// addresses change, a route changes or a static neighbor is added, it asks
+// its Handler to reflect the change.
+type Watcher struct {
+	handler Handler
+
+	netIfaces map[int]vcTypes.Interface
+
+	linkUpdateCh  chan netlink.LinkUpdate
+	addrUpdateCh  chan netlink.AddrUpdate
+	rtUpdateCh    chan netlink.RouteUpdate
+	neighUpdateCh chan netlink.NeighUpdate
+	doneCh        chan struct{}
+
+	netNs      netns.NsHandle
+	netHandler *netlink.Handle
+}
+
+// CurrentNetNsPath is the path of the network namespace of the current
+// process.
+const CurrentNetNsPath = "/proc/self/ns/net"
+
+// NewWatcher creates a Watcher of the network namespace at netNsPath, see
+// CurrentNetNsPath to watch the current one.
+func NewWatcher(netNsPath string, handler Handler) (*Watcher, error) {
+	if netNsPath == "" {
+		return nil, fmt.Errorf("Missing network namespace path to watch")
+	}
+
+	ns, err := netns.GetFromPath(netNsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	netHandler, err := netlink.NewHandleAt(ns, netlinkFamily)
+	if err != nil {
+		ns.Close()
+		return nil, err
+	}
+
+	return &Watcher{
+		handler:       handler,
+		netIfaces:     make(map[int]vcTypes.Interface),
+		linkUpdateCh:  make(chan netlink.LinkUpdate),
+		addrUpdateCh:  make(chan netlink.AddrUpdate),
+		rtUpdateCh:    make(chan netlink.RouteUpdate),
+		neighUpdateCh: make(chan netlink.NeighUpdate),
+		doneCh:        make(chan struct{}),
+		netNs:         ns,
+		netHandler:    netHandler,
+	}, nil
+}
+
+// Run scans the interfaces already in the network namespace, so that only
+// the ones showing up later are added, and then handles the netlink events
+// until Stop is called, or until handling one of them fails.
+func (w *Watcher) Run() error {
+	defer func() {
+		w.netHandler.Delete()
+		w.netNs.Close()
+	}()
+
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	return w.handleEvents()
+}
+
+// Stop makes Run return. It does not wait for the event being handled, if
+// any.
+func (w *Watcher) Stop() {
+	close(w.doneCh)
+}
+
+func (w *Watcher) start() error {
+	if err := w.scanNetwork(); err != nil {
+		return err
+	}
+
+	if err := netlink.LinkSubscribeWithOptions(w.linkUpdateCh, w.doneCh,
+		netlink.LinkSubscribeOptions{Namespace: &w.netNs}); err != nil {
+		return err
+	}
+
+	if err := netlink.AddrSubscribeWithOptions(w.addrUpdateCh, w.doneCh,
+		netlink.AddrSubscribeOptions{Namespace: &w.netNs}); err != nil {
+		return err
+	}
+
+	if err := netlink.RouteSubscribeWithOptions(w.rtUpdateCh, w.doneCh,
+		netlink.RouteSubscribeOptions{Namespace: &w.netNs}); err != nil {
+		return err
+	}
+
+	return netlink.NeighSubscribeWithOptions(w.neighUpdateCh, w.doneCh,
+		netlink.NeighSubscribeOptions{Namespace: &w.netNs})
+}
+
+func (w *Watcher) handleEvents() error {
+	for {
+		select {
+		case <-w.doneCh:
+			return nil
+		case ev, ok := <-w.linkUpdateCh:
+			if !ok {
+				return nil
+			}
+			if err := w.handleLinkEvent(ev); err != nil {
+				return err
+			}
+		case ev, ok := <-w.addrUpdateCh:
+			if !ok {
+				return nil
+			}
+			if err := w.handleAddrEvent(ev); err != nil {
+				return err
+			}
+		case ev, ok := <-w.rtUpdateCh:
+			if !ok {
+				return nil
+			}
+			if err := w.handleRouteEvent(ev); err != nil {
+				return err
+			}
+		case ev, ok := <-w.neighUpdateCh:
+			if !ok {
+				return nil
+			}
+			if err := w.handleNeighEvent(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *Watcher) logger() *logrus.Entry {
+	return netmonLog
+}
+
+// convertInterface converts a link and its IP addresses as defined by netlink
+// package, into the Interface structure format expected by kata-runtime to
+// describe an interface and its associated IP addresses.
+func convertInterface(linkAttrs *netlink.LinkAttrs, linkType string, addrs []netlink.Addr) vcTypes.Interface {
+	if linkAttrs == nil {
+		netmonLog.Warn("Link attributes are nil")
+		return vcTypes.Interface{}
+	}
+
+	var ipAddrs []*vcTypes.IPAddress
+
+	for _, addr := range addrs {
+		if addr.IPNet == nil {
+			continue
+		}
+
+		// The guest generates its own IPv6 link-local and temporary
+		// addresses. The addresses are only converted once the
+		// duplicate address detection succeeded, their update when it
+		// completes being another address event.
+		if addr.IP.To4() == nil && addr.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if addr.Flags&(unix.IFA_F_TEMPORARY|unix.IFA_F_TENTATIVE|unix.IFA_F_DADFAILED) != 0 {
+			continue
+		}
+
+		netMask, _ := addr.Mask.Size()
+
+		ipAddr := &vcTypes.IPAddress{
+			Address: addr.IP.String(),
+			Mask:    fmt.Sprintf("%d", netMask),
+		}
+
+		if addr.IP.To4() != nil {
+			ipAddr.Family = netlink.FAMILY_V4
+		} else {
+			ipAddr.Family = netlink.FAMILY_V6
+		}
+
+		ipAddrs = append(ipAddrs, ipAddr)
+	}
+
+	iface := vcTypes.Interface{
+		Device:      linkAttrs.Name,
+		Name:        linkAttrs.Name,
+		IPAddresses: ipAddrs,
+		Mtu:         uint64(linkAttrs.MTU),
+		HwAddr:      linkAttrs.HardwareAddr.String(),
+		LinkType:    linkType,
+	}
+
+	netmonLog.WithField("interface", iface).Debug("Interface converted")
+
+	return iface
+}
+
+// convertRoutes converts a list of routes as defined by netlink package,
+// into a list of Route structure format expected by kata-runtime to
+// describe a set of routes.
+func (w *Watcher) convertRoutes(netRoutes []netlink.Route) []*vcTypes.Route {
+	var routes []*vcTypes.Route
+
+	for _, netRoute := range netRoutes {
+		dst := ""
+
+		if netRoute.Protocol == unix.RTPROT_KERNEL {
+			continue
+		}
+
+		// The guest learns the routes coming from IPv6 router
+		// advertisements from the same advertisements.
+		if netRoute.Protocol == unix.RTPROT_RA {
+			continue
+		}
+
+		// Only unicast routes can be described to the agent.
+		if netRoute.Type != unix.RTN_UNSPEC && netRoute.Type != unix.RTN_UNICAST {
+			continue
+		}
+
+		if netRoute.Dst != nil {
+			dst = netRoute.Dst.String()
+			if netRoute.Dst.IP.To4() != nil || netRoute.Dst.IP.To16() != nil {
+				dst = netRoute.Dst.String()
+			} else {
+				netmonLog.WithField("destination", netRoute.Dst.IP.String()).Warn("Unexpected network address format")
+			}
+		}
+
+		src := ""
+		if netRoute.Src != nil {
+			if netRoute.Src.To4() != nil || netRoute.Src.To16() != nil {
+				src = netRoute.Src.String()
+			} else {
+				netmonLog.WithField("source", netRoute.Src.String()).Warn("Unexpected network address format")
+			}
+		}
+
+		gw := ""
+		if netRoute.Gw != nil {
+			if netRoute.Gw.To4() != nil || netRoute.Gw.To16() != nil {
+				gw = netRoute.Gw.String()
+			} else {
+				netmonLog.WithField("gateway", netRoute.Gw.String()).Warn("Unexpected network address format")
+			}
+		}
+
+		// The link is looked up in the watched network namespace,
+		// which is not necessarily the one of the calling process.
+		dev := ""
+		link, err := w.netHandler.LinkByIndex(netRoute.LinkIndex)
+		if err == nil {
+			dev = link.Attrs().Name
+		}
+
+		route := &vcTypes.Route{
+			Dest:    dst,
+			Gateway: gw,
+			Device:  dev,
+			Source:  src,
+			Scope:   uint32(netRoute.Scope),
+		}
+
+		routes = append(routes, route)
+	}
+
+	netmonLog.WithField("routes", routes).Debug("Routes converted")
+
+	return routes
+}
+
+// convertNeighbor converts a neighbor as defined by netlink package, into
+// the ARPNeighbor structure format expected by kata-runtime. Only the static
+// neighbors are converted, as the guest resolves the other ones by itself.
+func convertNeighbor(neigh netlink.Neigh, device string) *vcTypes.ARPNeighbor {
+	if neigh.State&netlink.NUD_PERMANENT == 0 || neigh.IP == nil {
+		return nil
+	}
+
+	ipAddr := &vcTypes.IPAddress{
+		Address: neigh.IP.String(),
+	}
+
+	if neigh.IP.To4() != nil {
+		ipAddr.Family = netlink.FAMILY_V4
+	} else {
+		ipAddr.Family = netlink.FAMILY_V6
+	}
+
+	lladdr := ""
+	if neigh.HardwareAddr != nil {
+		lladdr = neigh.HardwareAddr.String()
+	}
+
+	return &vcTypes.ARPNeighbor{
+		ToIPAddress: ipAddr,
+		Device:      device,
+		LLAddr:      lladdr,
+		State:       neigh.State,
+		Flags:       neigh.Flags,
+	}
+}
+
+// scanNetwork lists all the interfaces it can find inside the watched
+// network namespace, and store them in-memory to keep track of them.
+func (w *Watcher) scanNetwork() error {
+	links, err := w.netHandler.LinkList()
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		addrs, err := w.netHandler.AddrList(link, netlinkFamily)
+		if err != nil {
+			return err
+		}
+
+		linkAttrs := link.Attrs()
+		if linkAttrs == nil {
+			continue
+		}
+
+		iface := convertInterface(linkAttrs, link.Type(), addrs)
+		w.netIfaces[linkAttrs.Index] = iface
+	}
+
+	w.logger().Debug("Network scanned")
+
+	return nil
+}
+
+// routeList returns the routes of the watched network namespace.
+func (w *Watcher) routeList() ([]netlink.Route, error) {
+	families := []int{netlinkFamily}
+	if netlinkFamily == netlink.FAMILY_ALL {
+		families = []int{netlink.FAMILY_V4, netlink.FAMILY_V6}
+	}
+
+	var routes []netlink.Route
+	for _, family := range families {
+		familyRoutes, err := w.netHandler.RouteList(nil, family)
+		if err != nil {
+			return nil, err
+		}
+
+		if family == netlink.FAMILY_V6 {
+			setIPv6DefaultRoutesDst(familyRoutes)
+		}
+		routes = append(routes, familyRoutes...)
+	}
+
+	return routes, nil
+}
+
+// setIPv6DefaultRoutesDst sets the destination of the IPv6 default routes.
+// The default routes have no destination, the IPv6 ones get ::/0 not to be
+// taken for IPv4 routes when they have no gateway either.
+func setIPv6DefaultRoutesDst(routes []netlink.Route) {
+	for i := range routes {
+		if routes[i].Dst == nil {
+			routes[i].Dst = &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 8*net.IPv6len)}
+		}
+	}
+}
+
+func (w *Watcher) updateRoutes() error {
+	// Get all the routes.
+	netlinkRoutes, err := w.routeList()
+	if err != nil {
+		return err
+	}
+
+	// Translate them into Route structures.
+	routes := w.convertRoutes(netlinkRoutes)
+
+	// Update the routes through the handler.
+	_, err = w.handler.UpdateRoutes(routes)
+	return err
+}
+
+// addNeighbors adds the static neighbors of the interface through the
+// handler.
+func (w *Watcher) addNeighbors(iface vcTypes.Interface, linkIndex int) error {
+	netNeighs, err := w.netHandler.NeighList(linkIndex, netlinkFamily)
+	if err != nil {
+		return err
+	}
+
+	var neighs []*vcTypes.ARPNeighbor
+	for _, netNeigh := range netNeighs {
+		if neigh := convertNeighbor(netNeigh, iface.Name); neigh != nil {
+			neighs = append(neighs, neigh)
+		}
+	}
+
+	if len(neighs) == 0 {
+		return nil
+	}
+
+	return w.handler.AddNeighbors(neighs)
+}
+
+func (w *Watcher) handleRTMAddr(ev netlink.AddrUpdate) error {
+	// Only the addresses of the interfaces already known are updated,
+	// the addresses of a new interface being added with it.
+	iface, exist := w.netIfaces[ev.LinkIndex]
+	if !exist {
+		w.logger().Debugf("Ignoring address %s since interface %d not found",
+			ev.LinkAddress.String(), ev.LinkIndex)
+		return nil
+	}
+
+	// Ignore the interfaces created by Kata Containers.
+	if strings.HasSuffix(iface.Name, kataSuffix) {
+		w.logger().Debugf("Ignore the interface %s because found %q",
+			iface.Name, kataSuffix)
+		return nil
+	}
+
+	// The link might have been removed since, in which case the
+	// DELLINK event is coming.
+	link, err := w.netHandler.LinkByIndex(ev.LinkIndex)
+	if err != nil {
+		w.logger().WithError(err).Debugf("Ignoring address update of interface %s",
+			iface.Name)
+		return nil
+	}
+
+	// Get the up to date list of IP addresses of the interface, as a
+	// single event might not be the only change.
+	addrs, err := w.netHandler.AddrList(link, netlinkFamily)
+	if err != nil {
+		return err
+	}
+
+	updated := convertInterface(link.Attrs(), link.Type(), addrs)
+
+	// Addresses the guest does not care about, such as the IPv6
+	// link-local ones, are filtered out by the conversion.
+	if reflect.DeepEqual(updated.IPAddresses, iface.IPAddresses) {
+		w.logger().Debugf("Ignoring address %s since addresses of interface %s are unchanged",
+			ev.LinkAddress.String(), iface.Name)
+		return nil
+	}
+
+	// Update the interface through the handler.
+	if _, err := w.handler.UpdateInterface(&updated); err != nil {
+		return err
+	}
+
+	// Update the interface in the internal list.
+	w.netIfaces[ev.LinkIndex] = updated
+
+	return nil
+}
+
+func (w *Watcher) handleRTMNewLink(ev netlink.LinkUpdate) error {
+	// NEWLINK might be a lot of different things. We're interested in
+	// adding the interface (both to our list and through the handler)
+	// only if this has the flags UP and RUNNING, meaning we don't expect
+	// any further change on the interface, and that we are ready to add
+	// it.
+
+	linkAttrs := ev.Link.Attrs()
+	if linkAttrs == nil {
+		w.logger().Warn("The link attributes are nil")
+		return nil
+	}
+
+	// First, ignore if the interface name contains "kata". This way we
+	// are preventing from adding interfaces created by Kata Containers.
+	if strings.HasSuffix(linkAttrs.Name, kataSuffix) {
+		w.logger().Debugf("Ignore the interface %s because found %q",
+			linkAttrs.Name, kataSuffix)
+		return nil
+	}
+
+	// Check if the interface exist in the internal list.
+	if _, exist := w.netIfaces[int(ev.Index)]; exist {
+		w.logger().Debugf("Ignoring interface %s because already exist",
+			linkAttrs.Name)
+		return nil
+	}
+
+	// Now, check if the interface has been enabled to UP and RUNNING.
+	if (ev.Flags&unix.IFF_UP) != unix.IFF_UP ||
+		(ev.Flags&unix.IFF_RUNNING) != unix.IFF_RUNNING {
+		w.logger().Debugf("Ignore the interface %s because not UP and RUNNING",
+			linkAttrs.Name)
+		return nil
+	}
+
+	// Get the list of IP addresses associated with this interface.
+	addrs, err := w.netHandler.AddrList(ev.Link, netlinkFamily)
+	if err != nil {
+		return err
+	}
+
+	// Convert the interfaces in the appropriate structure format.
+	iface := convertInterface(linkAttrs, ev.Link.Type(), addrs)
+
+	// Add the interface through the handler.
+	if _, err := w.handler.AddInterface(&iface); err != nil {
+		return err
+	}
+
+	// Add the interface to the internal list.
+	w.netIfaces[linkAttrs.Index] = iface
+
+	// Add the static neighbors set up before the interface came up.
+	if err := w.addNeighbors(iface, linkAttrs.Index); err != nil {
+		return err
+	}
+
+	// Complete by updating the routes.
+	return w.updateRoutes()
+}
+
+func (w *Watcher) handleRTMDelLink(ev netlink.LinkUpdate) error {
+	// It can only delete if identical interface is found in the internal
+	// list of interfaces. Otherwise, the deletion will be ignored.
+	linkAttrs := ev.Link.Attrs()
+	if linkAttrs == nil {
+		w.logger().Warn("Link attributes are nil")
+		return nil
+	}
+
+	// First, ignore if the interface name contains "kata". This way we
+	// are preventing from deleting interfaces created by Kata Containers.
+	if strings.Contains(linkAttrs.Name, kataSuffix) {
+		w.logger().Debugf("Ignore the interface %s because found %q",
+			linkAttrs.Name, kataSuffix)
+		return nil
+	}
+
+	// Check if the interface exist in the internal list.
+	iface, exist := w.netIfaces[int(ev.Index)]
+	if !exist {
+		w.logger().Debugf("Ignoring interface %s because not found",
+			linkAttrs.Name)
+		return nil
+	}
+
+	if _, err := w.handler.RemoveInterface(&iface); err != nil {
+		return err
+	}
+
+	// Delete the interface from the internal list.
+	delete(w.netIfaces, linkAttrs.Index)
+
+	// Complete by updating the routes.
+	return w.updateRoutes()
+}
+
+func (w *Watcher) handleRTMNewRoute(ev netlink.RouteUpdate) error {
+	// Add the route through updateRoutes(), only if the route refer to an
+	// interface that already exists in the internal list of interfaces.
+	if _, exist := w.netIfaces[ev.Route.LinkIndex]; !exist {
+		w.logger().Debugf("Ignoring route %+v since interface %d not found",
+			ev.Route, ev.Route.LinkIndex)
+		return nil
+	}
+
+	return w.updateRoutes()
+}
+
+func (w *Watcher) handleRTMDelRoute(ev netlink.RouteUpdate) error {
+	// Remove the route through updateRoutes(), only if the route refer to
+	// an interface that already exists in the internal list of interfaces.
+	return w.updateRoutes()
+}
+
+func (w *Watcher) handleRTMNewNeigh(ev netlink.NeighUpdate) error {
+	// Add the neighbor only if it refers to an interface that already
+	// exists in the internal list of interfaces.
+	iface, exist := w.netIfaces[ev.LinkIndex]
+	if !exist {
+		w.logger().Debugf("Ignoring neighbor %+v since interface %d not found",
+			ev.Neigh, ev.LinkIndex)
+		return nil
+	}
+
+	// Ignore the interfaces created by Kata Containers.
+	if strings.HasSuffix(iface.Name, kataSuffix) {
+		w.logger().Debugf("Ignore the interface %s because found %q",
+			iface.Name, kataSuffix)
+		return nil
+	}
+
+	neigh := convertNeighbor(ev.Neigh, iface.Name)
+	if neigh == nil {
+		w.logger().Debugf("Ignoring neighbor %+v since not static", ev.Neigh)
+		return nil
+	}
+
+	return w.handler.AddNeighbors([]*vcTypes.ARPNeighbor{neigh})
+}
+
+func (w *Watcher) handleRTMDelNeigh(ev netlink.NeighUpdate) error {
+	w.logger().Debug("Neighbor removal not supported")
+	return nil
+}
+
+func (w *Watcher) handleLinkEvent(ev netlink.LinkUpdate) error {
+	w.logger().Debug("handleLinkEvent: netlink event received")
+
+	switch ev.Header.Type {
+	case unix.NLMSG_DONE:
+		w.logger().Debug("NLMSG_DONE")
+		return nil
+	case unix.NLMSG_ERROR:
+		w.logger().Error("NLMSG_ERROR")
+		return fmt.Errorf("Error while listening on netlink socket")
+	case unix.RTM_NEWLINK:
+		w.logger().Debug("RTM_NEWLINK")
+		return w.handleRTMNewLink(ev)
+	case unix.RTM_DELLINK:
+		w.logger().Debug("RTM_DELLINK")
+		return w.handleRTMDelLink(ev)
+	default:
+		w.logger().Warnf("Unknown msg type %v", ev.Header.Type)
+	}
+
+	return nil
+}
+
+func (w *Watcher) handleRouteEvent(ev netlink.RouteUpdate) error {
+	w.logger().Debug("handleRouteEvent: netlink event received")
+
+	switch ev.Type {
+	case unix.RTM_NEWROUTE:
+		w.logger().Debug("RTM_NEWROUTE")
+		return w.handleRTMNewRoute(ev)
+	case unix.RTM_DELROUTE:
+		w.logger().Debug("RTM_DELROUTE")
+		return w.handleRTMDelRoute(ev)
+	default:
+		w.logger().Warnf("Unknown msg type %v", ev.Type)
+	}
+
+	return nil
+}
+
+func (w *Watcher) handleAddrEvent(ev netlink.AddrUpdate) error {
+	w.logger().Debug("handleAddrEvent: netlink event received")
+
+	if ev.NewAddr {
+		w.logger().Debug("RTM_NEWADDR")
+	} else {
+		w.logger().Debug("RTM_DELADDR")
+	}
+
+	// Both an added and a deleted address lead to the interface being
+	// updated with its current addresses.
+	return w.handleRTMAddr(ev)
+}
+
+func (w *Watcher) handleNeighEvent(ev netlink.NeighUpdate) error {
+	w.logger().Debug("handleNeighEvent: netlink event received")
+
+	switch ev.Type {
+	case unix.RTM_NEWNEIGH:
+		w.logger().Debug("RTM_NEWNEIGH")
+		return w.handleRTMNewNeigh(ev)
+	case unix.RTM_DELNEIGH:
+		w.logger().Debug("RTM_DELNEIGH")
+		return w.handleRTMDelNeigh(ev)
+	default:
+		w.logger().Warnf("Unknown msg type %v", ev.Type)
+	}
+
+	return nil
+}
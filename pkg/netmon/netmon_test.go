@@ -0,0 +1,807 @@
+// Copyright (c) 2018 Intel Corporation
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package netmon
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	ktu "github.com/kata-containers/runtime/pkg/katatestutils"
+	vcTypes "github.com/kata-containers/runtime/virtcontainers/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	testWrongNetlinkFamily = -1
+	testIfaceName          = "test_eth0"
+	testPeerName           = "test_eth1"
+	testMTU                = 12345
+	testHwAddr             = "02:00:ca:fe:00:48"
+	testIPAddress          = "192.168.0.15"
+	testIPAddressWithMask  = "192.168.0.15/32"
+	testIP6Address         = "2001:db8:1::242:ac11:2"
+	testIP6AddressWithMask = "2001:db8:1::/64"
+	testScope              = 1
+	testTxQLen             = -1
+	testIfaceIndex         = 5
+)
+
+// testHandler records the calls made by a Watcher.
+type testHandler struct {
+	calls chan string
+	err   error
+}
+
+func newTestHandler() *testHandler {
+	return &testHandler{
+		calls: make(chan string, 64),
+	}
+}
+
+func (h *testHandler) record(call string) {
+	select {
+	case h.calls <- call:
+	default:
+	}
+}
+
+func (h *testHandler) AddInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	h.record("add " + inf.Name)
+	return inf, h.err
+}
+
+func (h *testHandler) RemoveInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	h.record("remove " + inf.Name)
+	return inf, h.err
+}
+
+func (h *testHandler) UpdateInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	h.record("update " + inf.Name)
+	return inf, h.err
+}
+
+func (h *testHandler) UpdateRoutes(routes []*vcTypes.Route) ([]*vcTypes.Route, error) {
+	h.record("routes")
+	return routes, h.err
+}
+
+func (h *testHandler) AddNeighbors(neighs []*vcTypes.ARPNeighbor) error {
+	for _, neigh := range neighs {
+		h.record("neighbor " + neigh.ToIPAddress.Address)
+	}
+	return h.err
+}
+
+// waitCall waits for the handler to be called with call.
+func (h *testHandler) waitCall(call string) bool {
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case c := <-h.calls:
+			if c == call {
+				return true
+			}
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+func skipUnlessRoot(t *testing.T) {
+	tc := ktu.NewTestConstraint(false)
+
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip("Test disabled as requires root user")
+	}
+}
+
+func newTestWatcher(t *testing.T) (*Watcher, *testHandler) {
+	h := newTestHandler()
+
+	w, err := NewWatcher(CurrentNetNsPath, h)
+	assert.Nil(t, err)
+
+	return w, h
+}
+
+func TestNewWatcherErrorWrongFamilyType(t *testing.T) {
+	// Override netlinkFamily
+	savedNetlinkFamily := netlinkFamily
+	netlinkFamily = testWrongNetlinkFamily
+	defer func() {
+		netlinkFamily = savedNetlinkFamily
+	}()
+
+	w, err := NewWatcher(CurrentNetNsPath, newTestHandler())
+	assert.NotNil(t, err)
+	assert.Nil(t, w)
+}
+
+func TestNewWatcherErrorNoNetNs(t *testing.T) {
+	w, err := NewWatcher("", newTestHandler())
+	assert.NotNil(t, err)
+	assert.Nil(t, w)
+}
+
+func TestNewWatcherErrorWrongNetNs(t *testing.T) {
+	w, err := NewWatcher("/foo/bar/netns", newTestHandler())
+	assert.NotNil(t, err)
+	assert.Nil(t, w)
+}
+
+func TestConvertInterface(t *testing.T) {
+	hwAddr, err := net.ParseMAC(testHwAddr)
+	assert.Nil(t, err)
+
+	addrs := []netlink.Addr{
+		{
+			IPNet: &net.IPNet{
+				IP: net.ParseIP(testIPAddress),
+			},
+		},
+		{
+			IPNet: &net.IPNet{
+				IP: net.ParseIP(testIP6Address),
+			},
+		},
+		// the duplicate address detection is not complete
+		{
+			IPNet: &net.IPNet{
+				IP: net.ParseIP("2001:db8::2"),
+			},
+			Flags: unix.IFA_F_TENTATIVE,
+		},
+	}
+
+	linkAttrs := &netlink.LinkAttrs{
+		Name:         testIfaceName,
+		MTU:          testMTU,
+		HardwareAddr: hwAddr,
+	}
+
+	linkType := "link_type_test"
+
+	expected := vcTypes.Interface{
+		Device: testIfaceName,
+		Name:   testIfaceName,
+		Mtu:    uint64(testMTU),
+		HwAddr: testHwAddr,
+		IPAddresses: []*vcTypes.IPAddress{
+			{
+				Family:  netlink.FAMILY_V4,
+				Address: testIPAddress,
+				Mask:    "0",
+			},
+			{
+				Family:  netlink.FAMILY_V6,
+				Address: testIP6Address,
+				Mask:    "0",
+			},
+		},
+		LinkType: linkType,
+	}
+
+	got := convertInterface(linkAttrs, linkType, addrs)
+
+	assert.True(t, reflect.DeepEqual(expected, got),
+		"Got %+v\nExpected %+v", got, expected)
+}
+
+func TestConvertRoutes(t *testing.T) {
+	ip, ipNet, err := net.ParseCIDR(testIPAddressWithMask)
+	assert.Nil(t, err)
+	assert.NotNil(t, ipNet)
+
+	_, ip6Net, err := net.ParseCIDR(testIP6AddressWithMask)
+	assert.Nil(t, err)
+	assert.NotNil(t, ipNet)
+
+	routes := []netlink.Route{
+		{
+			Dst:       ipNet,
+			Src:       ip,
+			Gw:        ip,
+			LinkIndex: -1,
+			Scope:     testScope,
+		},
+		{
+			Dst:       ip6Net,
+			Src:       nil,
+			Gw:        nil,
+			LinkIndex: -1,
+			Scope:     testScope,
+		},
+		// only unicast routes are converted
+		{
+			Dst:       ipNet,
+			LinkIndex: -1,
+			Type:      unix.RTN_MULTICAST,
+		},
+	}
+
+	expected := []*vcTypes.Route{
+		{
+			Dest:    testIPAddressWithMask,
+			Gateway: testIPAddress,
+			Source:  testIPAddress,
+			Scope:   uint32(testScope),
+		},
+		{
+			Dest:    testIP6AddressWithMask,
+			Gateway: "",
+			Source:  "",
+			Scope:   uint32(testScope),
+		},
+	}
+
+	w, _ := newTestWatcher(t)
+
+	got := w.convertRoutes(routes)
+	assert.True(t, reflect.DeepEqual(expected, got),
+		"Got %+v\nExpected %+v", got, expected)
+}
+
+func TestSetIPv6DefaultRoutesDst(t *testing.T) {
+	_, ip6Net, err := net.ParseCIDR(testIP6AddressWithMask)
+	assert.Nil(t, err)
+
+	routes := []netlink.Route{
+		{Dst: ip6Net},
+		{Gw: net.ParseIP("fe80::1")},
+	}
+
+	setIPv6DefaultRoutesDst(routes)
+	assert.Equal(t, testIP6AddressWithMask, routes[0].Dst.String())
+	assert.Equal(t, "::/0", routes[1].Dst.String())
+
+	w, _ := newTestWatcher(t)
+	got := w.convertRoutes(routes[1:])
+	assert.Len(t, got, 1)
+	assert.Equal(t, "::/0", got[0].Dest)
+	assert.Equal(t, "fe80::1", got[0].Gateway)
+}
+
+type testTeardownNetwork func()
+
+func testSetupNetwork(t *testing.T) testTeardownNetwork {
+	skipUnlessRoot(t)
+
+	// new temporary namespace so we don't pollute the host
+	// lock thread since the namespace is thread local
+	runtime.LockOSThread()
+	var err error
+	ns, err := netns.New()
+	if err != nil {
+		t.Fatal("Failed to create newns", ns)
+	}
+
+	return func() {
+		ns.Close()
+		runtime.UnlockOSThread()
+	}
+}
+
+func testCreateDummyNetwork(t *testing.T, handler *netlink.Handle) (int, vcTypes.Interface) {
+	hwAddr, err := net.ParseMAC(testHwAddr)
+	assert.Nil(t, err)
+
+	link := &netlink.Dummy{
+		LinkAttrs: netlink.LinkAttrs{
+			MTU:          testMTU,
+			TxQLen:       testTxQLen,
+			Name:         testIfaceName,
+			HardwareAddr: hwAddr,
+		},
+	}
+
+	err = handler.LinkAdd(link)
+	assert.Nil(t, err)
+	err = handler.LinkSetUp(link)
+	assert.Nil(t, err)
+
+	attrs := link.Attrs()
+	assert.NotNil(t, attrs)
+
+	addrs, err := handler.AddrList(link, netlinkFamily)
+	assert.Nil(t, err)
+
+	var ipAddrs []*vcTypes.IPAddress
+
+	// Scan addresses, skipping the ipv6 link local address which is
+	// automatically assigned
+	for _, addr := range addrs {
+		if addr.IPNet == nil || addr.IP.IsLinkLocalUnicast() {
+			continue
+		}
+
+		netMask, _ := addr.Mask.Size()
+
+		ipAddr := &vcTypes.IPAddress{
+			Address: addr.IP.String(),
+			Mask:    fmt.Sprintf("%d", netMask),
+		}
+
+		if addr.IP.To4() != nil {
+			ipAddr.Family = netlink.FAMILY_V4
+		} else {
+			ipAddr.Family = netlink.FAMILY_V6
+		}
+
+		ipAddrs = append(ipAddrs, ipAddr)
+	}
+
+	iface := vcTypes.Interface{
+		Device:      testIfaceName,
+		Name:        testIfaceName,
+		Mtu:         uint64(testMTU),
+		HwAddr:      testHwAddr,
+		LinkType:    link.Type(),
+		IPAddresses: ipAddrs,
+	}
+
+	return attrs.Index, iface
+}
+
+func TestScanNetwork(t *testing.T) {
+	tearDownNetworkCb := testSetupNetwork(t)
+	defer tearDownNetworkCb()
+
+	handler, err := netlink.NewHandle(netlinkFamily)
+	assert.Nil(t, err)
+	assert.NotNil(t, handler)
+	defer handler.Delete()
+
+	idx, expected := testCreateDummyNetwork(t, handler)
+
+	w := &Watcher{
+		netIfaces:  make(map[int]vcTypes.Interface),
+		netHandler: handler,
+	}
+
+	err = w.scanNetwork()
+	assert.Nil(t, err)
+	assert.True(t, reflect.DeepEqual(expected, w.netIfaces[idx]),
+		"Got %+v\nExpected %+v", w.netIfaces[idx], expected)
+}
+
+func TestUpdateRoutes(t *testing.T) {
+	tearDownNetworkCb := testSetupNetwork(t)
+	defer tearDownNetworkCb()
+
+	w, h := newTestWatcher(t)
+
+	// Test updateRoutes
+	err := w.updateRoutes()
+	assert.Nil(t, err)
+	assert.True(t, h.waitCall("routes"))
+
+	// Test handleRTMDelRoute
+	err = w.handleRTMDelRoute(netlink.RouteUpdate{})
+	assert.Nil(t, err)
+	assert.True(t, h.waitCall("routes"))
+
+	// Handler failure
+	h.err = fmt.Errorf("handler failure")
+	err = w.updateRoutes()
+	assert.NotNil(t, err)
+}
+
+func TestConvertNeighbor(t *testing.T) {
+	hwAddr, err := net.ParseMAC(testHwAddr)
+	assert.Nil(t, err)
+
+	// Dynamic neighbors are ignored
+	neigh := netlink.Neigh{
+		IP:           net.ParseIP(testIPAddress),
+		HardwareAddr: hwAddr,
+		State:        netlink.NUD_REACHABLE,
+	}
+	assert.Nil(t, convertNeighbor(neigh, testIfaceName))
+
+	// Neighbors without IP address are ignored
+	neigh = netlink.Neigh{
+		HardwareAddr: hwAddr,
+		State:        netlink.NUD_PERMANENT,
+	}
+	assert.Nil(t, convertNeighbor(neigh, testIfaceName))
+
+	neigh = netlink.Neigh{
+		IP:           net.ParseIP(testIPAddress),
+		HardwareAddr: hwAddr,
+		State:        netlink.NUD_PERMANENT,
+	}
+	expected := &vcTypes.ARPNeighbor{
+		ToIPAddress: &vcTypes.IPAddress{
+			Family:  netlink.FAMILY_V4,
+			Address: testIPAddress,
+		},
+		Device: testIfaceName,
+		LLAddr: testHwAddr,
+		State:  netlink.NUD_PERMANENT,
+	}
+	assert.Equal(t, expected, convertNeighbor(neigh, testIfaceName))
+
+	neigh.IP = net.ParseIP(testIP6Address)
+	expected.ToIPAddress = &vcTypes.IPAddress{
+		Family:  netlink.FAMILY_V6,
+		Address: testIP6Address,
+	}
+	assert.Equal(t, expected, convertNeighbor(neigh, testIfaceName))
+}
+
+func TestHandleRTMAddr(t *testing.T) {
+	w := &Watcher{
+		netIfaces: make(map[int]vcTypes.Interface),
+	}
+	ev := netlink.AddrUpdate{
+		LinkIndex: testIfaceIndex,
+	}
+
+	// Interface does not exist in list
+	err := w.handleRTMAddr(ev)
+	assert.Nil(t, err)
+
+	// Interface name contains "kata" suffix
+	w.netIfaces[testIfaceIndex] = vcTypes.Interface{Name: "foo_kata"}
+	err = w.handleRTMAddr(ev)
+	assert.Nil(t, err)
+
+	// Link not found anymore
+	handler, err := netlink.NewHandle(netlinkFamily)
+	assert.Nil(t, err)
+	assert.NotNil(t, handler)
+	defer handler.Delete()
+	w.netHandler = handler
+	ev.LinkIndex = 1 << 20
+	w.netIfaces[ev.LinkIndex] = vcTypes.Interface{Name: "foo0"}
+	err = w.handleRTMAddr(ev)
+	assert.Nil(t, err)
+}
+
+func TestHandleRTMNewNeigh(t *testing.T) {
+	hwAddr, err := net.ParseMAC(testHwAddr)
+	assert.Nil(t, err)
+
+	h := newTestHandler()
+	w := &Watcher{
+		handler:   h,
+		netIfaces: make(map[int]vcTypes.Interface),
+	}
+	ev := netlink.NeighUpdate{
+		Type: unix.RTM_NEWNEIGH,
+		Neigh: netlink.Neigh{
+			LinkIndex:    testIfaceIndex,
+			IP:           net.ParseIP(testIPAddress),
+			HardwareAddr: hwAddr,
+			State:        netlink.NUD_PERMANENT,
+		},
+	}
+
+	// Interface does not exist in list
+	err = w.handleRTMNewNeigh(ev)
+	assert.Nil(t, err)
+
+	// Interface name contains "kata" suffix
+	w.netIfaces[testIfaceIndex] = vcTypes.Interface{Name: "foo_kata"}
+	err = w.handleRTMNewNeigh(ev)
+	assert.Nil(t, err)
+
+	// Neighbor is not static
+	w.netIfaces[testIfaceIndex] = vcTypes.Interface{Name: "foo0"}
+	ev.State = netlink.NUD_STALE
+	err = w.handleRTMNewNeigh(ev)
+	assert.Nil(t, err)
+
+	ev.State = netlink.NUD_PERMANENT
+	err = w.handleRTMNewNeigh(ev)
+	assert.Nil(t, err)
+	assert.True(t, h.waitCall("neighbor "+testIPAddress))
+
+	// Handler failure
+	h.err = fmt.Errorf("handler failure")
+	err = w.handleRTMNewNeigh(ev)
+	assert.NotNil(t, err)
+}
+
+func TestHandleRTMNewLink(t *testing.T) {
+	w := &Watcher{}
+	ev := netlink.LinkUpdate{
+		Link: &netlink.Dummy{},
+	}
+
+	// LinkAttrs is nil
+	err := w.handleRTMNewLink(ev)
+	assert.Nil(t, err)
+
+	// Link name contains "kata" suffix
+	ev = netlink.LinkUpdate{
+		Link: &netlink.Dummy{
+			LinkAttrs: netlink.LinkAttrs{
+				Name: "foo_kata",
+			},
+		},
+	}
+	err = w.handleRTMNewLink(ev)
+	assert.Nil(t, err)
+
+	// Interface already exist in list
+	w.netIfaces = make(map[int]vcTypes.Interface)
+	w.netIfaces[testIfaceIndex] = vcTypes.Interface{}
+	ev = netlink.LinkUpdate{
+		Link: &netlink.Dummy{
+			LinkAttrs: netlink.LinkAttrs{
+				Name: "foo0",
+			},
+		},
+	}
+	ev.Index = testIfaceIndex
+	err = w.handleRTMNewLink(ev)
+	assert.Nil(t, err)
+
+	// Flags are not up and running
+	w.netIfaces = make(map[int]vcTypes.Interface)
+	ev = netlink.LinkUpdate{
+		Link: &netlink.Dummy{
+			LinkAttrs: netlink.LinkAttrs{
+				Name: "foo0",
+			},
+		},
+	}
+	ev.Index = testIfaceIndex
+	err = w.handleRTMNewLink(ev)
+	assert.Nil(t, err)
+
+	// Handler failure
+	w.netIfaces = make(map[int]vcTypes.Interface)
+	ev = netlink.LinkUpdate{
+		Link: &netlink.Dummy{
+			LinkAttrs: netlink.LinkAttrs{
+				Name: "foo0",
+			},
+		},
+	}
+	ev.Index = testIfaceIndex
+	ev.Flags = unix.IFF_UP | unix.IFF_RUNNING
+	handler, err := netlink.NewHandle(netlinkFamily)
+	assert.Nil(t, err)
+	assert.NotNil(t, handler)
+	defer handler.Delete()
+	w.netHandler = handler
+	h := newTestHandler()
+	h.err = fmt.Errorf("handler failure")
+	w.handler = h
+	err = w.handleRTMNewLink(ev)
+	assert.NotNil(t, err)
+	assert.NotContains(t, w.netIfaces, testIfaceIndex)
+}
+
+func TestHandleRTMDelLink(t *testing.T) {
+	w := &Watcher{}
+	ev := netlink.LinkUpdate{
+		Link: &netlink.Dummy{},
+	}
+
+	// LinkAttrs is nil
+	err := w.handleRTMDelLink(ev)
+	assert.Nil(t, err)
+
+	// Link name contains "kata" suffix
+	ev = netlink.LinkUpdate{
+		Link: &netlink.Dummy{
+			LinkAttrs: netlink.LinkAttrs{
+				Name: "foo_kata",
+			},
+		},
+	}
+	err = w.handleRTMDelLink(ev)
+	assert.Nil(t, err)
+
+	// Interface does not exist in list
+	w.netIfaces = make(map[int]vcTypes.Interface)
+	ev = netlink.LinkUpdate{
+		Link: &netlink.Dummy{
+			LinkAttrs: netlink.LinkAttrs{
+				Name: "foo0",
+			},
+		},
+	}
+	ev.Index = testIfaceIndex
+	err = w.handleRTMDelLink(ev)
+	assert.Nil(t, err)
+
+	// Handler failure
+	h := newTestHandler()
+	h.err = fmt.Errorf("handler failure")
+	w.handler = h
+	w.netIfaces[testIfaceIndex] = vcTypes.Interface{Name: "foo0"}
+	err = w.handleRTMDelLink(ev)
+	assert.NotNil(t, err)
+	assert.Contains(t, w.netIfaces, testIfaceIndex)
+}
+
+func TestHandleRTMNewRouteIfaceNotFound(t *testing.T) {
+	w := &Watcher{
+		netIfaces: make(map[int]vcTypes.Interface),
+	}
+
+	err := w.handleRTMNewRoute(netlink.RouteUpdate{})
+	assert.Nil(t, err)
+}
+
+func TestHandleLinkEvent(t *testing.T) {
+	w := &Watcher{}
+	ev := netlink.LinkUpdate{}
+
+	// Unknown event
+	err := w.handleLinkEvent(ev)
+	assert.Nil(t, err)
+
+	// DONE event
+	ev.Header.Type = unix.NLMSG_DONE
+	err = w.handleLinkEvent(ev)
+	assert.Nil(t, err)
+
+	// ERROR event
+	ev.Header.Type = unix.NLMSG_ERROR
+	err = w.handleLinkEvent(ev)
+	assert.NotNil(t, err)
+
+	// NEWLINK event
+	ev.Header.Type = unix.RTM_NEWLINK
+	ev.Link = &netlink.Dummy{}
+	err = w.handleLinkEvent(ev)
+	assert.Nil(t, err)
+
+	// DELLINK event
+	ev.Header.Type = unix.RTM_DELLINK
+	ev.Link = &netlink.Dummy{}
+	err = w.handleLinkEvent(ev)
+	assert.Nil(t, err)
+}
+
+func TestHandleRouteEvent(t *testing.T) {
+	w := &Watcher{}
+	ev := netlink.RouteUpdate{}
+
+	// Unknown event
+	err := w.handleRouteEvent(ev)
+	assert.Nil(t, err)
+
+	// RTM_NEWROUTE event
+	ev.Type = unix.RTM_NEWROUTE
+	err = w.handleRouteEvent(ev)
+	assert.Nil(t, err)
+
+	tearDownNetworkCb := testSetupNetwork(t)
+	defer tearDownNetworkCb()
+
+	w, _ = newTestWatcher(t)
+
+	// RTM_DELROUTE event
+	ev.Type = unix.RTM_DELROUTE
+	err = w.handleRouteEvent(ev)
+	assert.Nil(t, err)
+}
+
+func TestHandleAddrEvent(t *testing.T) {
+	w := &Watcher{
+		netIfaces: make(map[int]vcTypes.Interface),
+	}
+	ev := netlink.AddrUpdate{}
+
+	// NEWADDR event
+	ev.NewAddr = true
+	err := w.handleAddrEvent(ev)
+	assert.Nil(t, err)
+
+	// DELADDR event
+	ev.NewAddr = false
+	err = w.handleAddrEvent(ev)
+	assert.Nil(t, err)
+}
+
+func TestHandleNeighEvent(t *testing.T) {
+	w := &Watcher{
+		netIfaces: make(map[int]vcTypes.Interface),
+	}
+	ev := netlink.NeighUpdate{}
+
+	// Unknown event
+	err := w.handleNeighEvent(ev)
+	assert.Nil(t, err)
+
+	// RTM_NEWNEIGH event
+	ev.Type = unix.RTM_NEWNEIGH
+	err = w.handleNeighEvent(ev)
+	assert.Nil(t, err)
+
+	// RTM_DELNEIGH event
+	ev.Type = unix.RTM_DELNEIGH
+	err = w.handleNeighEvent(ev)
+	assert.Nil(t, err)
+}
+
+func TestWatcherRun(t *testing.T) {
+	skipUnlessRoot(t)
+
+	assert := assert.New(t)
+
+	// Create a network namespace without switching the test to it, as
+	// the watcher must work from any network namespace.
+	runtime.LockOSThread()
+	origin, err := netns.Get()
+	assert.NoError(err)
+	ns, err := netns.New()
+	assert.NoError(err)
+	assert.NoError(netns.Set(origin))
+	runtime.UnlockOSThread()
+	origin.Close()
+	defer ns.Close()
+
+	nsHandler, err := netlink.NewHandleAt(ns)
+	assert.NoError(err)
+	defer nsHandler.Delete()
+
+	h := newTestHandler()
+	w, err := NewWatcher(fmt.Sprintf("/proc/%d/fd/%d", unix.Getpid(), ns), h)
+	assert.NoError(err)
+
+	// Start the watcher before creating the interface, so that the
+	// interface is not considered as existing.
+	assert.NoError(w.start())
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- w.handleEvents()
+	}()
+
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: testIfaceName},
+		PeerName:  testPeerName,
+	}
+	assert.NoError(nsHandler.LinkAdd(veth))
+
+	peer, err := nsHandler.LinkByName(testPeerName)
+	assert.NoError(err)
+	assert.NoError(nsHandler.LinkSetUp(peer))
+	assert.NoError(nsHandler.LinkSetUp(veth))
+
+	// The interface is added once UP and RUNNING, and the routes follow.
+	assert.True(h.waitCall("add " + testIfaceName))
+	assert.True(h.waitCall("routes"))
+
+	// Adding an address updates the interface.
+	_, ipNet, err := net.ParseCIDR(testIPAddressWithMask)
+	assert.NoError(err)
+	assert.NoError(nsHandler.AddrAdd(veth, &netlink.Addr{IPNet: ipNet}))
+	assert.True(h.waitCall("update " + testIfaceName))
+
+	// Adding a static neighbor adds it to the guest.
+	hwAddr, err := net.ParseMAC(testHwAddr)
+	assert.NoError(err)
+	assert.NoError(nsHandler.NeighAdd(&netlink.Neigh{
+		LinkIndex:    veth.Attrs().Index,
+		IP:           net.ParseIP(testIPAddress),
+		HardwareAddr: hwAddr,
+		State:        netlink.NUD_PERMANENT,
+	}))
+	assert.True(h.waitCall("neighbor " + testIPAddress))
+
+	assert.NoError(nsHandler.LinkDel(veth))
+	assert.True(h.waitCall("remove " + testIfaceName))
+
+	w.Stop()
+	assert.NoError(<-errCh)
+}
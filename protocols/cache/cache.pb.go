@@ -12,6 +12,7 @@
 		GrpcVM
 		GrpcStatus
 		GrpcVMStatus
+		GrpcCacheStats
 */
 package cache
 
@@ -127,8 +128,9 @@ func (m *GrpcVM) GetCpuDelta() uint32 {
 }
 
 type GrpcStatus struct {
-	Pid      int64           `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
-	Vmstatus []*GrpcVMStatus `protobuf:"bytes,2,rep,name=vmstatus" json:"vmstatus,omitempty"`
+	Pid        int64           `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Vmstatus   []*GrpcVMStatus `protobuf:"bytes,2,rep,name=vmstatus" json:"vmstatus,omitempty"`
+	Cachestats *GrpcCacheStats `protobuf:"bytes,3,opt,name=cachestats" json:"cachestats,omitempty"`
 }
 
 func (m *GrpcStatus) Reset()                    { *m = GrpcStatus{} }
@@ -150,6 +152,13 @@ func (m *GrpcStatus) GetVmstatus() []*GrpcVMStatus {
 	return nil
 }
 
+func (m *GrpcStatus) GetCachestats() *GrpcCacheStats {
+	if m != nil {
+		return m.Cachestats
+	}
+	return nil
+}
+
 type GrpcVMStatus struct {
 	Pid    int64  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
 	Cpu    uint32 `protobuf:"varint,2,opt,name=cpu,proto3" json:"cpu,omitempty"`
@@ -182,11 +191,76 @@ func (m *GrpcVMStatus) GetMemory() uint32 {
 	return 0
 }
 
+type GrpcCacheStats struct {
+	Hits        uint64 `protobuf:"varint,1,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses      uint64 `protobuf:"varint,2,opt,name=misses,proto3" json:"misses,omitempty"`
+	Evictions   uint64 `protobuf:"varint,3,opt,name=evictions,proto3" json:"evictions,omitempty"`
+	Boots       uint64 `protobuf:"varint,4,opt,name=boots,proto3" json:"boots,omitempty"`
+	BootTime    int64  `protobuf:"varint,5,opt,name=bootTime,proto3" json:"bootTime,omitempty"`
+	MaxBootTime int64  `protobuf:"varint,6,opt,name=maxBootTime,proto3" json:"maxBootTime,omitempty"`
+	Target      uint32 `protobuf:"varint,7,opt,name=target,proto3" json:"target,omitempty"`
+}
+
+func (m *GrpcCacheStats) Reset()                    { *m = GrpcCacheStats{} }
+func (m *GrpcCacheStats) String() string            { return proto.CompactTextString(m) }
+func (*GrpcCacheStats) ProtoMessage()               {}
+func (*GrpcCacheStats) Descriptor() ([]byte, []int) { return fileDescriptorCache, []int{4} }
+
+func (m *GrpcCacheStats) GetHits() uint64 {
+	if m != nil {
+		return m.Hits
+	}
+	return 0
+}
+
+func (m *GrpcCacheStats) GetMisses() uint64 {
+	if m != nil {
+		return m.Misses
+	}
+	return 0
+}
+
+func (m *GrpcCacheStats) GetEvictions() uint64 {
+	if m != nil {
+		return m.Evictions
+	}
+	return 0
+}
+
+func (m *GrpcCacheStats) GetBoots() uint64 {
+	if m != nil {
+		return m.Boots
+	}
+	return 0
+}
+
+func (m *GrpcCacheStats) GetBootTime() int64 {
+	if m != nil {
+		return m.BootTime
+	}
+	return 0
+}
+
+func (m *GrpcCacheStats) GetMaxBootTime() int64 {
+	if m != nil {
+		return m.MaxBootTime
+	}
+	return 0
+}
+
+func (m *GrpcCacheStats) GetTarget() uint32 {
+	if m != nil {
+		return m.Target
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*GrpcVMConfig)(nil), "cache.GrpcVMConfig")
 	proto.RegisterType((*GrpcVM)(nil), "cache.GrpcVM")
 	proto.RegisterType((*GrpcStatus)(nil), "cache.GrpcStatus")
 	proto.RegisterType((*GrpcVMStatus)(nil), "cache.GrpcVMStatus")
+	proto.RegisterType((*GrpcCacheStats)(nil), "cache.GrpcCacheStats")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -478,6 +552,16 @@ func (m *GrpcStatus) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if m.Cachestats != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintCache(dAtA, i, uint64(m.Cachestats.Size()))
+		n1, err := m.Cachestats.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
 	return i, nil
 }
 
@@ -514,6 +598,59 @@ func (m *GrpcVMStatus) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *GrpcCacheStats) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GrpcCacheStats) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Hits != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintCache(dAtA, i, uint64(m.Hits))
+	}
+	if m.Misses != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintCache(dAtA, i, uint64(m.Misses))
+	}
+	if m.Evictions != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintCache(dAtA, i, uint64(m.Evictions))
+	}
+	if m.Boots != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintCache(dAtA, i, uint64(m.Boots))
+	}
+	if m.BootTime != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintCache(dAtA, i, uint64(m.BootTime))
+	}
+	if m.MaxBootTime != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintCache(dAtA, i, uint64(m.MaxBootTime))
+	}
+	if m.Target != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintCache(dAtA, i, uint64(m.Target))
+	}
+	return i, nil
+}
+
 func encodeVarintCache(dAtA []byte, offset int, v uint64) int {
 	for v >= 1<<7 {
 		dAtA[offset] = uint8(v&0x7f | 0x80)
@@ -579,6 +716,10 @@ func (m *GrpcStatus) Size() (n int) {
 			n += 1 + l + sovCache(uint64(l))
 		}
 	}
+	if m.Cachestats != nil {
+		l = m.Cachestats.Size()
+		n += 1 + l + sovCache(uint64(l))
+	}
 	return n
 }
 
@@ -597,6 +738,33 @@ func (m *GrpcVMStatus) Size() (n int) {
 	return n
 }
 
+func (m *GrpcCacheStats) Size() (n int) {
+	var l int
+	_ = l
+	if m.Hits != 0 {
+		n += 1 + sovCache(uint64(m.Hits))
+	}
+	if m.Misses != 0 {
+		n += 1 + sovCache(uint64(m.Misses))
+	}
+	if m.Evictions != 0 {
+		n += 1 + sovCache(uint64(m.Evictions))
+	}
+	if m.Boots != 0 {
+		n += 1 + sovCache(uint64(m.Boots))
+	}
+	if m.BootTime != 0 {
+		n += 1 + sovCache(uint64(m.BootTime))
+	}
+	if m.MaxBootTime != 0 {
+		n += 1 + sovCache(uint64(m.MaxBootTime))
+	}
+	if m.Target != 0 {
+		n += 1 + sovCache(uint64(m.Target))
+	}
+	return n
+}
+
 func sovCache(x uint64) (n int) {
 	for {
 		n++
@@ -1016,6 +1184,39 @@ func (m *GrpcStatus) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cachestats", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCache
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCache
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Cachestats == nil {
+				m.Cachestats = &GrpcCacheStats{}
+			}
+			if err := m.Cachestats.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCache(dAtA[iNdEx:])
@@ -1144,6 +1345,189 @@ func (m *GrpcVMStatus) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *GrpcCacheStats) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCache
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GrpcCacheStats: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GrpcCacheStats: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hits", wireType)
+			}
+			m.Hits = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCache
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Hits |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Misses", wireType)
+			}
+			m.Misses = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCache
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Misses |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Evictions", wireType)
+			}
+			m.Evictions = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCache
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Evictions |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Boots", wireType)
+			}
+			m.Boots = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCache
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Boots |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BootTime", wireType)
+			}
+			m.BootTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCache
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BootTime |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxBootTime", wireType)
+			}
+			m.MaxBootTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCache
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxBootTime |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			m.Target = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCache
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Target |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCache(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCache
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipCache(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -1252,29 +1636,36 @@ var (
 func init() { proto.RegisterFile("cache.proto", fileDescriptorCache) }
 
 var fileDescriptorCache = []byte{
-	// 374 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x51, 0xcd, 0x0a, 0xda, 0x40,
-	0x10, 0x66, 0x13, 0x4d, 0x75, 0x8c, 0xa5, 0xdd, 0x82, 0x2c, 0x16, 0x42, 0xc8, 0x29, 0xa7, 0x08,
-	0x4a, 0x7b, 0xaf, 0x5a, 0x84, 0x52, 0x69, 0x1b, 0xa9, 0xf7, 0x18, 0xd7, 0x18, 0x30, 0xee, 0x92,
-	0x6c, 0xa4, 0x79, 0xb1, 0x3e, 0x43, 0x8f, 0x7d, 0x84, 0xe2, 0xa1, 0xcf, 0x51, 0x76, 0xb3, 0x86,
-	0x08, 0xcd, 0x6d, 0x66, 0xbe, 0x9f, 0xfd, 0x76, 0x06, 0x46, 0x71, 0x14, 0x9f, 0x69, 0xc0, 0x73,
-	0x26, 0x18, 0xee, 0xab, 0x66, 0xfa, 0x36, 0x61, 0x2c, 0xb9, 0xd0, 0x99, 0x1a, 0x1e, 0xca, 0xd3,
-	0x8c, 0x66, 0x5c, 0x54, 0x35, 0xc7, 0x5b, 0x83, 0xbd, 0xc9, 0x79, 0xbc, 0xdf, 0xae, 0xd8, 0xf5,
-	0x94, 0x26, 0x18, 0x43, 0x6f, 0x1d, 0x89, 0x88, 0x20, 0x17, 0xf9, 0x76, 0xa8, 0x6a, 0xec, 0xc2,
-	0xe8, 0x43, 0x42, 0xaf, 0xa2, 0xa6, 0x10, 0x43, 0x41, 0xed, 0x91, 0xf7, 0x13, 0x81, 0x55, 0xdb,
-	0xe0, 0x97, 0x60, 0xa4, 0x47, 0x25, 0x1f, 0x86, 0x46, 0x7a, 0xc4, 0x0e, 0xc0, 0xb9, 0xe2, 0x34,
-	0xbf, 0xa5, 0x05, 0xcb, 0xb5, 0xb6, 0x35, 0xc1, 0x53, 0x18, 0xf0, 0x9c, 0xfd, 0xa8, 0xbe, 0xa6,
-	0x47, 0x62, 0xba, 0xc8, 0x37, 0xc3, 0xa6, 0x6f, 0xb0, 0xef, 0xe1, 0x67, 0xd2, 0x53, 0x8e, 0x4d,
-	0x8f, 0x5f, 0x81, 0x19, 0xf3, 0x92, 0xf4, 0x5d, 0xe4, 0x8f, 0x43, 0x59, 0xe2, 0x09, 0x58, 0x19,
-	0xcd, 0x58, 0x5e, 0x11, 0x4b, 0x0d, 0x75, 0x27, 0x5d, 0x62, 0x5e, 0xae, 0xe9, 0x45, 0x44, 0xe4,
-	0x85, 0x42, 0x9a, 0xde, 0xfb, 0x02, 0x20, 0x73, 0xef, 0x44, 0x24, 0xca, 0x42, 0x7a, 0x72, 0x1d,
-	0xde, 0x0c, 0x65, 0x89, 0x67, 0x30, 0xb8, 0x65, 0x85, 0x42, 0x89, 0xe1, 0x9a, 0xfe, 0x68, 0xfe,
-	0x26, 0xa8, 0x57, 0x5c, 0x7f, 0xb7, 0x16, 0x86, 0x0d, 0xc9, 0xfb, 0xf4, 0xd8, 0x67, 0xa7, 0xa5,
-	0x0e, 0x6e, 0xfc, 0x2f, 0xb8, 0xd9, 0x0e, 0x3e, 0xff, 0x8b, 0xc0, 0x5e, 0xc9, 0xc7, 0x76, 0x72,
-	0x59, 0x31, 0xc5, 0xef, 0xc0, 0xd2, 0x67, 0x9a, 0x04, 0xf5, 0x51, 0x83, 0xc7, 0x51, 0x83, 0x8f,
-	0xf2, 0xa8, 0xd3, 0xe7, 0x74, 0x9a, 0x3c, 0x87, 0xe1, 0x86, 0x8a, 0x65, 0x54, 0xd0, 0xfd, 0xb6,
-	0x53, 0x39, 0x7e, 0x52, 0xe2, 0x05, 0x58, 0xfa, 0x07, 0x5d, 0x82, 0xd7, 0x2d, 0x81, 0xa6, 0xbe,
-	0x87, 0xde, 0xb7, 0x32, 0x15, 0x9d, 0x92, 0x8e, 0xf9, 0xd2, 0xfe, 0x75, 0x77, 0xd0, 0xef, 0xbb,
-	0x83, 0xfe, 0xdc, 0x1d, 0x74, 0xb0, 0x14, 0xba, 0xf8, 0x17, 0x00, 0x00, 0xff, 0xff, 0x40, 0x5e,
-	0x19, 0x6e, 0xcc, 0x02, 0x00, 0x00,
+	// 481 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x52, 0xc1, 0x6e, 0xd3, 0x40,
+	0x10, 0xd5, 0xc6, 0xae, 0x69, 0x26, 0x69, 0x05, 0x0b, 0x54, 0x56, 0x40, 0x91, 0xe5, 0x53, 0x4e,
+	0x8e, 0x94, 0xaa, 0xdc, 0x49, 0x83, 0x2a, 0x21, 0x2a, 0xc1, 0x02, 0xbd, 0x3b, 0xce, 0xd6, 0x59,
+	0xa9, 0xce, 0x5a, 0xde, 0x75, 0xd4, 0xfc, 0x00, 0x9f, 0xc4, 0x27, 0x20, 0x8e, 0x7c, 0x02, 0xca,
+	0x81, 0xef, 0x40, 0x3b, 0xbb, 0x31, 0x8e, 0x84, 0x6f, 0xf3, 0xe6, 0xcd, 0x1b, 0xef, 0xf3, 0x1b,
+	0x18, 0x64, 0x69, 0xb6, 0xe6, 0x49, 0x59, 0x49, 0x2d, 0xe9, 0x09, 0x82, 0xd1, 0xab, 0x5c, 0xca,
+	0xfc, 0x81, 0x4f, 0xb1, 0xb9, 0xac, 0xef, 0xa7, 0xbc, 0x28, 0xf5, 0xce, 0xce, 0xc4, 0x0b, 0x18,
+	0xde, 0x54, 0x65, 0x76, 0x77, 0x7b, 0x2d, 0x37, 0xf7, 0x22, 0xa7, 0x14, 0xfc, 0x45, 0xaa, 0xd3,
+	0x90, 0x44, 0x64, 0x32, 0x64, 0x58, 0xd3, 0x08, 0x06, 0x6f, 0x73, 0xbe, 0xd1, 0x76, 0x24, 0xec,
+	0x21, 0xd5, 0x6e, 0xc5, 0xdf, 0x09, 0x04, 0x76, 0x0d, 0x3d, 0x87, 0x9e, 0x58, 0xa1, 0xbc, 0xcf,
+	0x7a, 0x62, 0x45, 0xc7, 0x00, 0xeb, 0x5d, 0xc9, 0xab, 0xad, 0x50, 0xb2, 0x72, 0xda, 0x56, 0x87,
+	0x8e, 0xe0, 0xb4, 0xac, 0xe4, 0xe3, 0xee, 0xa3, 0x58, 0x85, 0x5e, 0x44, 0x26, 0x1e, 0x6b, 0x70,
+	0xc3, 0x7d, 0x65, 0x1f, 0x42, 0x1f, 0x37, 0x36, 0x98, 0x3e, 0x05, 0x2f, 0x2b, 0xeb, 0xf0, 0x24,
+	0x22, 0x93, 0x33, 0x66, 0x4a, 0x7a, 0x01, 0x41, 0xc1, 0x0b, 0x59, 0xed, 0xc2, 0x00, 0x9b, 0x0e,
+	0x99, 0x2d, 0x59, 0x59, 0x2f, 0xf8, 0x83, 0x4e, 0xc3, 0x27, 0xc8, 0x34, 0x38, 0xfe, 0x46, 0x00,
+	0xcc, 0xc3, 0x3f, 0xeb, 0x54, 0xd7, 0xca, 0x2c, 0x2d, 0xdd, 0xeb, 0x3d, 0x66, 0x4a, 0x3a, 0x85,
+	0xd3, 0x6d, 0xa1, 0x90, 0x0d, 0x7b, 0x91, 0x37, 0x19, 0xcc, 0x9e, 0x27, 0xf6, 0x1f, 0x5b, 0xbf,
+	0x56, 0xc8, 0x9a, 0x21, 0x7a, 0x05, 0x80, 0xbc, 0x81, 0x0a, 0x1d, 0x0d, 0x66, 0x2f, 0x5b, 0x92,
+	0x6b, 0x53, 0x19, 0x95, 0x62, 0xad, 0xc1, 0xf8, 0xfd, 0x21, 0x87, 0xce, 0x97, 0x38, 0xc3, 0xbd,
+	0xff, 0x19, 0xf6, 0xda, 0x86, 0xe3, 0x1f, 0x04, 0xce, 0x8f, 0x3f, 0x65, 0x62, 0x5d, 0x0b, 0xad,
+	0x70, 0x9f, 0xcf, 0xb0, 0x46, 0xb9, 0x50, 0x8a, 0x2b, 0xdc, 0xe9, 0x33, 0x87, 0xe8, 0x6b, 0xe8,
+	0xf3, 0xad, 0xc8, 0xb4, 0x90, 0x1b, 0x6b, 0xc0, 0x67, 0xff, 0x1a, 0xf4, 0x05, 0x9c, 0x2c, 0xa5,
+	0xd4, 0x0a, 0x03, 0xf1, 0x99, 0x05, 0xe6, 0x1f, 0x9b, 0xe2, 0x8b, 0x28, 0x38, 0x46, 0xe2, 0xb1,
+	0x06, 0x9b, 0xf3, 0x29, 0xd2, 0xc7, 0xf9, 0x81, 0x0e, 0x90, 0x6e, 0xb7, 0xcc, 0x4b, 0x74, 0x5a,
+	0xe5, 0x5c, 0xbb, 0x7c, 0x1c, 0x9a, 0xfd, 0x21, 0x30, 0xb4, 0x26, 0xcc, 0xb5, 0x64, 0x9c, 0x5e,
+	0x41, 0xe0, 0xee, 0xf4, 0x22, 0xb1, 0x57, 0x9d, 0x1c, 0xae, 0x3a, 0x79, 0x67, 0xae, 0x7a, 0x74,
+	0x9c, 0x8e, 0x1b, 0x9e, 0x41, 0xff, 0x86, 0xeb, 0x79, 0xaa, 0xf8, 0xdd, 0x6d, 0xa7, 0xf2, 0xec,
+	0x48, 0x49, 0x2f, 0x21, 0x70, 0x51, 0x74, 0x09, 0x9e, 0xb5, 0x04, 0x6e, 0xf4, 0x0d, 0xf8, 0x9f,
+	0x6a, 0xa1, 0x3b, 0x25, 0x1d, 0xfd, 0xf9, 0xf0, 0xe7, 0x7e, 0x4c, 0x7e, 0xed, 0xc7, 0xe4, 0xf7,
+	0x7e, 0x4c, 0x96, 0x01, 0xb2, 0x97, 0x7f, 0x07, 0x00, 0x7a, 0xfe, 0xbc, 0x89, 0xcd, 0x03, 0x00,
+	0x00,
 }
@@ -153,6 +153,14 @@ func (a *Acrn) capabilities() types.Capabilities {
 	return a.arch.capabilities()
 }
 
+func (a *Acrn) checkTemplateConfig(conf *HypervisorConfig) error {
+	return fmt.Errorf("acrn does not support vm templating")
+}
+
+func (a *Acrn) templateFiles(conf *HypervisorConfig) []string {
+	return nil
+}
+
 func (a *Acrn) hypervisorConfig() HypervisorConfig {
 	return a.config
 }
@@ -600,6 +608,10 @@ func (a *Acrn) pauseSandbox() error {
 	return nil
 }
 
+func (a *Acrn) migrateSandbox(uri string) error {
+	return errors.New("acrn does not support live migration")
+}
+
 func (a *Acrn) resumeSandbox() error {
 	span, _ := a.trace("resumeSandbox")
 	defer span.Finish()
@@ -651,7 +663,7 @@ func (a *Acrn) getSandboxConsole(id string) (string, error) {
 	return utils.BuildSocketPath(a.store.RunVMStoragePath(), id, acrnConsoleSocket)
 }
 
-func (a *Acrn) saveSandbox() error {
+func (a *Acrn) saveSandbox(statePath string) error {
 	a.Logger().Info("save sandbox")
 
 	// Not supported. return success
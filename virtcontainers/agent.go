@@ -239,6 +239,9 @@ type agent interface {
 	// listRoutes will tell the agent to list routes of an existed Sandbox
 	listRoutes() ([]*vcTypes.Route, error)
 
+	// addARPNeighbors will tell the agent to add ARP and NDP neighbors to an existed Sandbox.
+	addARPNeighbors(neighs []*vcTypes.ARPNeighbor) error
+
 	// getGuestDetails will tell the agent to get some information of guest
 	getGuestDetails(*grpc.GuestDetailsRequest) (*grpc.GuestDetailsResponse, error)
 
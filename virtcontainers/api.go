@@ -7,6 +7,7 @@ package virtcontainers
 
 import (
 	"context"
+	"crypto/tls"
 	"os"
 	"runtime"
 	"syscall"
@@ -284,6 +285,39 @@ func StopSandbox(ctx context.Context, sandboxID string, force bool) (VCSandbox,
 	return s, nil
 }
 
+// MigrateSandbox is the virtcontainers sandbox live migration entry point.
+// MigrateSandbox moves a running sandbox to the destination runtime
+// listening on target, and deletes it from this host once restored there.
+// tlsConfig is required to migrate over tcp.
+func MigrateSandbox(ctx context.Context, sandboxID, target string, tlsConfig *tls.Config) error {
+	span, ctx := trace(ctx, "MigrateSandbox")
+	defer span.Finish()
+
+	if sandboxID == "" {
+		return vcTypes.ErrNeedSandboxID
+	}
+
+	unlock, err := rwLockSandbox(sandboxID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := fetchSandbox(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer s.releaseStatelessSandbox()
+
+	if err := s.Migrate(target, tlsConfig); err != nil {
+		return err
+	}
+
+	// The sandbox runs on the destination, what is left here only
+	// has to be cleaned up.
+	return s.releaseMigrated()
+}
+
 // RunSandbox is the virtcontainers sandbox running entry point.
 // RunSandbox creates a sandbox and its containers and then it starts them.
 func RunSandbox(ctx context.Context, sandboxConfig SandboxConfig, factory Factory) (VCSandbox, error) {
@@ -623,6 +657,7 @@ func statusContainer(sandbox *Sandbox, containerID string) (ContainerStatus, err
 			ID:          container.id,
 			State:       container.state,
 			PID:         container.process.Pid,
+			GuestPID:    container.process.GuestPid,
 			StartTime:   container.process.StartTime,
 			RootFs:      container.config.RootFs.Target,
 			Spec:        container.GetPatchedOCISpec(),
@@ -898,6 +933,30 @@ func RemoveInterface(ctx context.Context, sandboxID string, inf *vcTypes.Interfa
 	return toggleInterface(ctx, sandboxID, inf, false)
 }
 
+// UpdateInterface is the virtcontainers update interface entry point.
+func UpdateInterface(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	span, ctx := trace(ctx, "UpdateInterface")
+	defer span.Finish()
+
+	if sandboxID == "" {
+		return nil, vcTypes.ErrNeedSandboxID
+	}
+
+	unlock, err := rwLockSandbox(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	s, err := fetchSandbox(ctx, sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	defer s.releaseStatelessSandbox()
+
+	return s.UpdateInterface(inf)
+}
+
 // ListInterfaces is the virtcontainers list interfaces entry point.
 func ListInterfaces(ctx context.Context, sandboxID string) ([]*vcTypes.Interface, error) {
 	span, ctx := trace(ctx, "ListInterfaces")
@@ -970,6 +1029,30 @@ func ListRoutes(ctx context.Context, sandboxID string) ([]*vcTypes.Route, error)
 	return s.ListRoutes()
 }
 
+// AddNeighbors is the virtcontainers add ARP neighbors entry point.
+func AddNeighbors(ctx context.Context, sandboxID string, neighs []*vcTypes.ARPNeighbor) error {
+	span, ctx := trace(ctx, "AddNeighbors")
+	defer span.Finish()
+
+	if sandboxID == "" {
+		return vcTypes.ErrNeedSandboxID
+	}
+
+	unlock, err := rwLockSandbox(sandboxID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := fetchSandbox(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer s.releaseStatelessSandbox()
+
+	return s.AddNeighbors(neighs)
+}
+
 // CleanupContaienr is used by shimv2 to stop and delete a container exclusively, once there is no container
 // in the sandbox left, do stop the sandbox and delete it. Those serial operations will be done exclusively by
 // locking the sandbox.
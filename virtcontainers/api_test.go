@@ -25,6 +25,7 @@ import (
 	"github.com/kata-containers/runtime/virtcontainers/types"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
 )
 
 const (
@@ -1696,6 +1697,13 @@ func TestNetworkOperation(t *testing.T) {
 	_, err = RemoveInterface(ctx, s.ID(), inf)
 	assert.NoError(err)
 
+	_, err = UpdateInterface(ctx, "", inf)
+	assert.Error(err)
+
+	// The update of an unknown interface is ignored.
+	_, err = UpdateInterface(ctx, s.ID(), inf)
+	assert.NoError(err)
+
 	_, err = ListInterfaces(ctx, s.ID())
 	assert.NoError(err)
 
@@ -1704,6 +1712,22 @@ func TestNetworkOperation(t *testing.T) {
 
 	_, err = ListRoutes(ctx, s.ID())
 	assert.NoError(err)
+
+	err = AddNeighbors(ctx, "", nil)
+	assert.Error(err)
+
+	err = AddNeighbors(ctx, s.ID(), []*vcTypes.ARPNeighbor{
+		{
+			ToIPAddress: &vcTypes.IPAddress{Address: "192.168.0.1"},
+			Device:      "eno1",
+			LLAddr:      "02:00:ca:fe:00:01",
+			State:       netlink.NUD_PERMANENT,
+		},
+	})
+	assert.NoError(err)
+
+	err = AddNeighbors(ctx, s.ID(), []*vcTypes.ARPNeighbor{{Device: "eno1"}})
+	assert.Error(err)
 }
 
 func TestCleanupContainer(t *testing.T) {
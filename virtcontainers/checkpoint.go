@@ -0,0 +1,238 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	persistapi "github.com/kata-containers/runtime/virtcontainers/persist/api"
+	"github.com/kata-containers/runtime/virtcontainers/types"
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// checkpointVMStateFile is the file, relative to the checkpoint
+	// directory, holding the VM memory and devices state.
+	checkpointVMStateFile = "vm.state"
+
+	// checkpointStateFile is the file, relative to the checkpoint
+	// directory, holding the sandbox and containers persisted states.
+	checkpointStateFile = "state.json"
+)
+
+// checkpointState is what gets saved next to the VM state of a checkpoint.
+type checkpointState struct {
+	Sandbox    persistapi.SandboxState
+	Containers map[string]persistapi.ContainerState
+}
+
+// CheckpointVMStatePath returns the path of the VM state file of the
+// checkpoint saved in dir. This is the HypervisorConfig.DevicesStatePath
+// to use together with BootFromCheckpoint in order to restore a sandbox.
+func CheckpointVMStatePath(dir string) string {
+	return filepath.Join(dir, checkpointVMStateFile)
+}
+
+// Checkpoint saves the sandbox VM state, along with the sandbox and
+// containers persisted states, to dir. The sandbox keeps running once
+// the checkpoint is done.
+func (s *Sandbox) Checkpoint(dir string) (err error) {
+	span, _ := s.trace("checkpoint")
+	defer span.Finish()
+
+	if err := s.checkVMStateSave("checkpoint"); err != nil {
+		return err
+	}
+
+	caps := s.hypervisor.capabilities()
+	if !caps.IsSnapshotSupported() {
+		return fmt.Errorf("Hypervisor %s does not support checkpoint", s.config.HypervisorType)
+	}
+
+	if err := os.MkdirAll(dir, DirMode); err != nil {
+		return err
+	}
+
+	// The agent is disconnected and the VM paused while checkpointing,
+	// which the monitor must not take for the sandbox being unhealthy.
+	if s.monitor != nil {
+		s.monitor.suspend()
+		defer s.monitor.resume()
+	}
+
+	// The agent connection is tied to this VM instance, it will be
+	// established again on the next agent request.
+	if err := s.agent.disconnect(); err != nil {
+		s.Logger().WithError(err).Warn("failed to disconnect agent before checkpoint")
+	}
+
+	if err := s.hypervisor.pauseSandbox(); err != nil {
+		return err
+	}
+
+	defer func() {
+		if resumeErr := s.hypervisor.resumeSandbox(); resumeErr != nil {
+			s.Logger().WithError(resumeErr).Error("failed to resume sandbox after checkpoint")
+			if err == nil {
+				err = resumeErr
+			}
+		}
+	}()
+
+	if err := s.hypervisor.saveSandbox(CheckpointVMStatePath(dir)); err != nil {
+		return err
+	}
+
+	ss, cs := s.dump()
+	data, err := json.Marshal(checkpointState{
+		Sandbox:    ss,
+		Containers: cs,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, checkpointStateFile), data, 0600); err != nil {
+		return err
+	}
+
+	s.Logger().WithField("checkpoint", dir).Info("Sandbox checkpointed")
+
+	return nil
+}
+
+// checkVMStateSave checks the sandbox VM state can be saved, in order to
+// restore the sandbox from it later on.
+func (s *Sandbox) checkVMStateSave(op string) error {
+	if s.state.State != types.StateRunning {
+		return fmt.Errorf("Sandbox not running, impossible to %s", op)
+	}
+
+	hConfig := s.hypervisor.hypervisorConfig()
+	if hConfig.BootToBeTemplate || hConfig.BootFromTemplate {
+		return fmt.Errorf("Cannot %s a sandbox using VM templating", op)
+	}
+
+	// The restored VM is created with the devices known at boot time,
+	// it cannot match the state of a VM with hotplugged devices.
+	if devices := s.devManager.GetAllDevices(); len(devices) > 0 {
+		return fmt.Errorf("Cannot %s a sandbox with %d hotplugged devices", op, len(devices))
+	}
+
+	return nil
+}
+
+// LoadCheckpointConfig returns the configuration of the sandbox saved by
+// the checkpoint in dir. The containers configurations only carry their
+// ID, annotations, resources and rootfs target.
+func LoadCheckpointConfig(dir string) (*SandboxConfig, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, checkpointStateFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var cp checkpointState
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+
+	return sandboxConfigFromState(cp.Sandbox.SandboxContainer, cp.Sandbox.Config), nil
+}
+
+// loadCheckpoint loads the containers states saved along with the VM
+// state the sandbox is being restored from.
+func (s *Sandbox) loadCheckpoint(dir string) error {
+	data, err := ioutil.ReadFile(filepath.Join(dir, checkpointStateFile))
+	if err != nil {
+		return err
+	}
+
+	var cp checkpointState
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return err
+	}
+
+	// The guest agent knows the sandbox and its containers by ID.
+	if cp.Sandbox.SandboxContainer != s.id {
+		return fmt.Errorf("Checkpoint of sandbox %s cannot restore sandbox %s", cp.Sandbox.SandboxContainer, s.id)
+	}
+
+	s.checkpointed = cp.Containers
+	if s.checkpointed == nil {
+		s.checkpointed = make(map[string]persistapi.ContainerState)
+	}
+
+	s.checkpointedEndpoints = nil
+	for _, e := range cp.Sandbox.Network.Endpoints {
+		if ep := loadEndpoint(e); ep != nil {
+			s.checkpointedEndpoints = append(s.checkpointedEndpoints, ep)
+		}
+	}
+
+	return nil
+}
+
+// restoreGuestHardwareAddr gives the interface backing endpoint the MAC
+// address the guest of the VM restored from a checkpoint knows it by, as
+// its network devices keep the state they were saved with. This is what
+// lets a sandbox be restored in a network namespace other than its own,
+// on another host for instance. It must be called from the network
+// namespace of the endpoint.
+func (s *Sandbox) restoreGuestHardwareAddr(endpoint Endpoint) error {
+	if endpoint.NetworkPair() == nil {
+		return nil
+	}
+
+	for _, e := range s.checkpointedEndpoints {
+		if e.Name() != endpoint.Name() || e.HardwareAddr() == "" {
+			continue
+		}
+
+		hwAddr, err := net.ParseMAC(e.HardwareAddr())
+		if err != nil {
+			return err
+		}
+
+		props := endpoint.Properties()
+		if props.Iface.HardwareAddr.String() == hwAddr.String() {
+			return nil
+		}
+
+		link, err := netlink.LinkByName(endpoint.Name())
+		if err != nil {
+			return fmt.Errorf("Could not find link %s: %s", endpoint.Name(), err)
+		}
+
+		if err := netlink.LinkSetHardwareAddr(link, hwAddr); err != nil {
+			return fmt.Errorf("Could not set MAC address %s of link %s: %s", hwAddr, endpoint.Name(), err)
+		}
+
+		props.Iface.HardwareAddr = hwAddr
+		endpoint.SetProperties(props)
+
+		s.Logger().WithFields(logrus.Fields{
+			"endpoint": endpoint.Name(),
+			"mac":      hwAddr.String(),
+		}).Info("Restored guest MAC address of endpoint")
+
+		return nil
+	}
+
+	return nil
+}
+
+// isCheckpointed tells if the container already exists in the guest
+// of a VM restored from a checkpoint.
+func (s *Sandbox) isCheckpointed(containerID string) bool {
+	_, ok := s.checkpointed[containerID]
+	return ok
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/containernetworking/plugins/pkg/testutils"
+	ktu "github.com/kata-containers/runtime/pkg/katatestutils"
+	persistapi "github.com/kata-containers/runtime/virtcontainers/persist/api"
+	"github.com/kata-containers/runtime/virtcontainers/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+)
+
+func TestCheckpointVMStatePath(t *testing.T) {
+	assert.Equal(t, "/foo/bar/"+checkpointVMStateFile, CheckpointVMStatePath("/foo/bar"))
+}
+
+func TestSandboxCheckpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, nil, nil)
+	assert.NoError(err)
+	defer cleanUp()
+
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	// sandbox is not running yet
+	err = s.Checkpoint(dir)
+	assert.Error(err)
+
+	err = s.Start()
+	assert.NoError(err)
+
+	contID := "999"
+	_, err = s.CreateContainer(newTestContainerConfigNoop(contID))
+	assert.NoError(err)
+
+	// The health checks are suspended while checkpointing, and start
+	// again afterwards.
+	s.monitor = newMonitor(s)
+	s.monitor.failures = 1
+
+	err = s.Checkpoint(dir)
+	assert.NoError(err)
+	assert.False(s.monitor.suspended)
+	assert.Zero(s.monitor.failures)
+
+	_, err = os.Stat(filepath.Join(dir, checkpointStateFile))
+	assert.NoError(err)
+
+	restored := &Sandbox{id: testSandboxID}
+	err = restored.loadCheckpoint(dir)
+	assert.NoError(err)
+	assert.True(restored.isCheckpointed(contID))
+	assert.False(restored.isCheckpointed("foo"))
+
+	other := &Sandbox{id: "foo"}
+	err = other.loadCheckpoint(dir)
+	assert.Error(err)
+
+	err = other.loadCheckpoint(filepath.Join(dir, "missing"))
+	assert.Error(err)
+}
+
+func TestStartCheckpointedContainer(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, nil, nil)
+	assert.NoError(err)
+	defer cleanUp()
+
+	err = s.Start()
+	assert.NoError(err)
+
+	contID := "999"
+	s.checkpointed = map[string]persistapi.ContainerState{
+		contID: {State: string(types.StateRunning)},
+	}
+
+	_, err = s.CreateContainer(newTestContainerConfigNoop(contID))
+	assert.NoError(err)
+
+	c, err := s.StartContainer(contID)
+	assert.NoError(err)
+	assert.Equal(types.StateRunning, c.(*Container).state.State)
+	assert.False(s.isCheckpointed(contID))
+}
+
+func TestRestoreGuestHardwareAddr(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	assert := assert.New(t)
+
+	netNS, err := testutils.NewNS()
+	assert.NoError(err)
+	defer testutils.UnmountNS(netNS)
+
+	guestMAC := "02:00:ca:fe:00:01"
+	checkpointed, err := createVethNetworkEndpoint(0, "eth0", NetXConnectTCFilterModel)
+	assert.NoError(err)
+	checkpointed.NetPair.TAPIface.HardAddr = guestMAC
+
+	s := &Sandbox{
+		checkpointedEndpoints: []Endpoint{checkpointed},
+	}
+
+	endpoint, err := createVethNetworkEndpoint(0, "eth0", NetXConnectTCFilterModel)
+	assert.NoError(err)
+	other, err := createVethNetworkEndpoint(1, "eth1", NetXConnectTCFilterModel)
+	assert.NoError(err)
+
+	err = netNS.Do(func(_ ns.NetNS) error {
+		veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}, PeerName: "foo"}
+		if err := netlink.LinkAdd(veth); err != nil {
+			return err
+		}
+
+		if err := s.restoreGuestHardwareAddr(endpoint); err != nil {
+			return err
+		}
+
+		link, err := netlink.LinkByName("eth0")
+		if err != nil {
+			return err
+		}
+		assert.Equal(guestMAC, link.Attrs().HardwareAddr.String())
+
+		// not part of the checkpoint
+		return s.restoreGuestHardwareAddr(other)
+	})
+	assert.NoError(err)
+
+	mac, err := net.ParseMAC(guestMAC)
+	assert.NoError(err)
+	assert.Equal(mac, endpoint.Properties().Iface.HardwareAddr)
+}
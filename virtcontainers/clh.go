@@ -6,6 +6,7 @@
 package virtcontainers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -48,6 +49,7 @@ const (
 const (
 	clhStateCreated = "Created"
 	clhStateRunning = "Running"
+	clhStatePaused  = "Paused"
 )
 
 const (
@@ -66,6 +68,12 @@ const (
 	supportedMinorVersion = 5
 	defaultClhPath        = "/usr/local/bin/cloud-hypervisor"
 	virtioFsCacheAlways   = "always"
+	// clhTemplateIDFile is the file of a VM template snapshot holding the
+	// id of the template VM.
+	clhTemplateIDFile = "template-id"
+	// clhTemplateSnapshot is the directory of the snapshot a VM is
+	// restored from when booting from a VM template.
+	clhTemplateSnapshot = "template-snapshot"
 )
 
 // Interface that hides the implementation of openAPI client
@@ -90,8 +98,18 @@ type clhClient interface {
 	VmAddDevicePut(ctx context.Context, vmAddDevice chclient.VmAddDevice) (chclient.PciDeviceInfo, *http.Response, error)
 	// Add a new disk device to the VM
 	VmAddDiskPut(ctx context.Context, diskConfig chclient.DiskConfig) (chclient.PciDeviceInfo, *http.Response, error)
+	// Add a new network device to the VM
+	VmAddNetPut(ctx context.Context, netConfig chclient.NetConfig) (chclient.PciDeviceInfo, *http.Response, error)
 	// Remove a device from the VM
 	VmRemoveDevicePut(ctx context.Context, vmRemoveDevice chclient.VmRemoveDevice) (*http.Response, error)
+	// Pause the VM
+	PauseVM(ctx context.Context) (*http.Response, error)
+	// Resume the paused VM
+	ResumeVM(ctx context.Context) (*http.Response, error)
+	// Snapshot the paused VM
+	VmSnapshotPut(ctx context.Context, vmSnapshotConfig chclient.VmSnapshotConfig) (*http.Response, error)
+	// Restore the VM from a snapshot
+	VmRestorePut(ctx context.Context, restoreConfig chclient.RestoreConfig) (*http.Response, error)
 }
 
 type CloudHypervisorVersion struct {
@@ -375,7 +393,19 @@ func (clh *cloudHypervisor) startSandbox(timeout int) error {
 	}
 	clh.state.PID = pid
 
-	if err := clh.bootVM(ctx); err != nil {
+	switch {
+	case clh.config.BootFromCheckpoint:
+		err = clh.restoreVM(clh.config.DevicesStatePath)
+	case clh.config.BootFromTemplate:
+		snapshotPath := filepath.Join(vmPath, clhTemplateSnapshot)
+		if err = clhCloneSnapshot(clh.config.DevicesStatePath, snapshotPath, clh.id); err != nil {
+			return err
+		}
+		err = clh.restoreVM(snapshotPath)
+	default:
+		err = clh.bootVM(ctx)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -456,6 +486,100 @@ func (clh *cloudHypervisor) hotPlugVFIODevice(device config.VFIODev) error {
 	return err
 }
 
+// clhPciPathFromBdf returns the PCI path of a device cloud hypervisor
+// plugged at bdf. Devices are all plugged on the root bus.
+func clhPciPathFromBdf(bdf string) (vcTypes.PciPath, error) {
+	// Expected format: "0000:00:05.0"
+	var domain, bus, slot, function int
+	if _, err := fmt.Sscanf(bdf, "%x:%x:%x.%x", &domain, &bus, &slot, &function); err != nil {
+		return vcTypes.PciPath{}, fmt.Errorf("Malformed PCI address %q: %s", bdf, err)
+	}
+
+	if domain != 0 || bus != 0 {
+		return vcTypes.PciPath{}, fmt.Errorf("Unexpected PCI address %q, expecting a device on the root bus", bdf)
+	}
+
+	pciSlot, err := vcTypes.PciSlotFromInt(slot)
+	if err != nil {
+		return vcTypes.PciPath{}, err
+	}
+
+	return vcTypes.PciPathFromSlots(pciSlot)
+}
+
+// clhNetID returns the cloud hypervisor device ID of the endpoint, the
+// TAP name is unique in the sandbox network namespace.
+func clhNetID(netPair *NetworkInterfacePair) string {
+	return netPair.TAPIface.Name
+}
+
+// netConfig returns the cloud hypervisor network device of the endpoint
+// connected through netPair.
+func (clh *cloudHypervisor) netConfig(endpoint Endpoint, netPair *NetworkInterfacePair) chclient.NetConfig {
+	device := chclient.NetConfig{
+		Mac: endpoint.HardwareAddr(),
+		Tap: netPair.TAPIface.Name,
+		Id:  clhNetID(netPair),
+	}
+
+	// A queue per direction for each queue pair.
+	if queues := len(netPair.VMFds); queues > 1 {
+		device.NumQueues = int32(2 * queues)
+	}
+
+	// cloud hypervisor has a single size for all the device queues.
+	c := netPair.Config
+	if c.RxQueueSize > c.TxQueueSize {
+		device.QueueSize = int32(c.RxQueueSize)
+	} else {
+		device.QueueSize = int32(c.TxQueueSize)
+	}
+
+	if c.DisableChecksum || c.DisableTSO || c.DisableGSO {
+		clh.Logger().WithField("endpoint", endpoint.Name()).Warn("cloud hypervisor network device offloads can't be disabled")
+	}
+
+	// The rate limiter applies to both directions, the bandwidth is capped
+	// on the host side otherwise.
+	if c.RxRateLimiterMaxRate > 0 && c.RxRateLimiterMaxRate == c.TxRateLimiterMaxRate {
+		device.RateLimiterConfig = chclient.RateLimiterConfig{
+			Bandwidth: chclient.TokenBucket{
+				Size:       int64(c.RxRateLimiterMaxRate / 8),
+				RefillTime: 1000,
+			},
+		}
+	}
+
+	return device
+}
+
+func (clh *cloudHypervisor) hotplugAddNetDevice(endpoint Endpoint) error {
+	netPair := endpoint.NetworkPair()
+	if netPair == nil {
+		return errors.New("net Pair to be added is nil, needed to get TAP path")
+	}
+
+	cl := clh.client()
+	ctx, cancel := context.WithTimeout(context.Background(), clhHotPlugAPITimeout*time.Second)
+	defer cancel()
+
+	netDevice := clh.netConfig(endpoint, netPair)
+
+	pciInfo, _, err := cl.VmAddNetPut(ctx, netDevice)
+	if err != nil {
+		return fmt.Errorf("failed to hotplug network device %+v %s", netDevice, openAPIClientError(err))
+	}
+
+	// The agent finds the interface in the guest from its PCI path
+	pciPath, err := clhPciPathFromBdf(pciInfo.Bdf)
+	if err != nil {
+		return err
+	}
+	endpoint.SetPciPath(pciPath)
+
+	return nil
+}
+
 func (clh *cloudHypervisor) hotplugAddDevice(devInfo interface{}, devType deviceType) (interface{}, error) {
 	span, _ := clh.trace("hotplugAddDevice")
 	defer span.Finish()
@@ -467,6 +591,9 @@ func (clh *cloudHypervisor) hotplugAddDevice(devInfo interface{}, devType device
 	case vfioDev:
 		device := devInfo.(*config.VFIODev)
 		return nil, clh.hotPlugVFIODevice(*device)
+	case netDev:
+		endpoint := devInfo.(Endpoint)
+		return nil, clh.hotplugAddNetDevice(endpoint)
 	default:
 		return nil, fmt.Errorf("cannot hotplug device: unsupported device type '%v'", devType)
 	}
@@ -484,6 +611,12 @@ func (clh *cloudHypervisor) hotplugRemoveDevice(devInfo interface{}, devType dev
 		deviceID = clhDriveIndexToID(devInfo.(*config.BlockDrive).Index)
 	case vfioDev:
 		deviceID = devInfo.(*config.VFIODev).ID
+	case netDev:
+		netPair := devInfo.(Endpoint).NetworkPair()
+		if netPair == nil {
+			return nil, errors.New("net Pair to be removed is nil, needed to get TAP path")
+		}
+		deviceID = clhNetID(netPair)
 	default:
 		clh.Logger().WithFields(log.Fields{"devInfo": devInfo,
 			"deviceType": devType}).Error("hotplugRemoveDevice: unsupported device")
@@ -619,20 +752,75 @@ func (clh *cloudHypervisor) cleanup() error {
 }
 
 func (clh *cloudHypervisor) pauseSandbox() error {
+	span, _ := clh.trace("pauseSandbox")
+	defer span.Finish()
+
 	clh.Logger().WithField("function", "pauseSandbox").Info("Pause Sandbox")
+
+	ctx, cancel := context.WithTimeout(context.Background(), clhAPITimeout*time.Second)
+	defer cancel()
+
+	if _, err := clh.client().PauseVM(ctx); err != nil {
+		return fmt.Errorf("Failed to pause VM: %s", openAPIClientError(err))
+	}
+
 	return nil
 }
 
-func (clh *cloudHypervisor) saveSandbox() error {
-	clh.Logger().WithField("function", "saveSandboxC").Info("Save Sandbox")
+// saveSandbox snapshots the paused VM. Cloud hypervisor saves its
+// snapshot as a set of files, statePath is used as their directory.
+func (clh *cloudHypervisor) saveSandbox(statePath string) error {
+	span, _ := clh.trace("saveSandbox")
+	defer span.Finish()
+
+	clh.Logger().WithField("function", "saveSandbox").WithField("path", statePath).Info("Save Sandbox")
+
+	if statePath == "" {
+		return errors.New("Missing cloud-hypervisor snapshot path")
+	}
+
+	if err := os.MkdirAll(statePath, DirMode); err != nil {
+		return err
+	}
+
+	// Saving the guest memory can take a while, use the longer timeout
+	ctx, cancel := context.WithTimeout(context.Background(), clhHotPlugAPITimeout*time.Second)
+	defer cancel()
+
+	snapshot := chclient.VmSnapshotConfig{DestinationUrl: clhSnapshotURL(statePath)}
+	if _, err := clh.client().VmSnapshotPut(ctx, snapshot); err != nil {
+		return fmt.Errorf("Failed to snapshot VM: %s", openAPIClientError(err))
+	}
+
+	if clh.config.BootToBeTemplate {
+		// The snapshot refers to the template VM files by its id, keep
+		// it to let the VMs booted from the template use their own.
+		return ioutil.WriteFile(filepath.Join(statePath, clhTemplateIDFile), []byte(clh.id), 0640)
+	}
+
 	return nil
 }
 
 func (clh *cloudHypervisor) resumeSandbox() error {
+	span, _ := clh.trace("resumeSandbox")
+	defer span.Finish()
+
 	clh.Logger().WithField("function", "resumeSandbox").Info("Resume Sandbox")
+
+	ctx, cancel := context.WithTimeout(context.Background(), clhAPITimeout*time.Second)
+	defer cancel()
+
+	if _, err := clh.client().ResumeVM(ctx); err != nil {
+		return fmt.Errorf("Failed to resume VM: %s", openAPIClientError(err))
+	}
+
 	return nil
 }
 
+func (clh *cloudHypervisor) migrateSandbox(uri string) error {
+	return errors.New("cloud hypervisor does not support live migration")
+}
+
 // stopSandbox will stop the Sandbox's VM.
 func (clh *cloudHypervisor) stopSandbox() (err error) {
 	span, _ := clh.trace("stopSandbox")
@@ -722,9 +910,28 @@ func (clh *cloudHypervisor) capabilities() types.Capabilities {
 	var caps types.Capabilities
 	caps.SetFsSharingSupport()
 	caps.SetBlockDeviceHotplugSupport()
+	caps.SetSnapshotSupport()
+	caps.SetTemplateSupport()
+	caps.SetNetSharedRateLimiterSupport()
 	return caps
 }
 
+// checkTemplateConfig checks the cloud hypervisor VM template requirements.
+// The template is a snapshot, saved at DevicesStatePath.
+func (clh *cloudHypervisor) checkTemplateConfig(conf *HypervisorConfig) error {
+	if conf.DevicesStatePath == "" {
+		return fmt.Errorf("Missing DevicesStatePath for vm template")
+	}
+
+	return nil
+}
+
+// templateFiles returns the snapshot directory of the template VM and the
+// file holding its id.
+func (clh *cloudHypervisor) templateFiles(conf *HypervisorConfig) []string {
+	return []string{conf.DevicesStatePath, filepath.Join(conf.DevicesStatePath, clhTemplateIDFile)}
+}
+
 func (clh *cloudHypervisor) trace(name string) (opentracing.Span, context.Context) {
 
 	if clh.ctx == nil {
@@ -1164,6 +1371,86 @@ func (clh *cloudHypervisor) bootVM(ctx context.Context) error {
 	return nil
 }
 
+// clhSnapshotURL returns the URL cloud hypervisor expects to locate the
+// snapshot saved in dir.
+func clhSnapshotURL(dir string) string {
+	return "file://" + dir
+}
+
+// clhCloneSnapshot creates at dst a copy of the VM template snapshot saved
+// at src for the VM id. The guest memory files are linked to, the
+// configuration files are rewritten to use the paths of the VM id instead
+// of the ones of the template VM.
+func clhCloneSnapshot(src, dst, id string) error {
+	templateID, err := ioutil.ReadFile(filepath.Join(src, clhTemplateIDFile))
+	if err != nil {
+		return fmt.Errorf("Invalid cloud-hypervisor vm template %s: %v", src, err)
+	}
+
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dst, DirMode); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		srcPath := filepath.Join(src, f.Name())
+		dstPath := filepath.Join(dst, f.Name())
+
+		switch {
+		case f.Name() == clhTemplateIDFile:
+		case filepath.Ext(f.Name()) == ".json":
+			data, err := ioutil.ReadFile(srcPath)
+			if err != nil {
+				return err
+			}
+
+			data = bytes.Replace(data, templateID, []byte(id), -1)
+			if err := ioutil.WriteFile(dstPath, data, 0640); err != nil {
+				return err
+			}
+		default:
+			if err := os.Symlink(srcPath, dstPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// restoreVM restores the VM from the snapshot saved at snapshotPath.
+// The restored VM is paused and needs to be resumed.
+func (clh *cloudHypervisor) restoreVM(snapshotPath string) error {
+	cl := clh.client()
+
+	// Loading the guest memory can take a while, use the longer timeout
+	ctx, cancel := context.WithTimeout(context.Background(), clhHotPlugAPITimeout*time.Second)
+	defer cancel()
+
+	clh.Logger().WithField("snapshot", snapshotPath).Debug("Restoring VM")
+	restore := chclient.RestoreConfig{SourceUrl: clhSnapshotURL(snapshotPath)}
+	if _, err := cl.VmRestorePut(ctx, restore); err != nil {
+		return openAPIClientError(err)
+	}
+
+	info, err := clh.vmInfo()
+	if err != nil {
+		return err
+	}
+
+	clh.Logger().Debugf("VM state after restore: %#v", info)
+
+	if info.State != clhStatePaused {
+		return fmt.Errorf("VM state is not 'Paused' after 'VmRestorePut'")
+	}
+
+	return nil
+}
+
 func (clh *cloudHypervisor) addVSock(cid int64, path string) {
 	clh.Logger().WithFields(log.Fields{
 		"path": path,
@@ -1194,7 +1481,7 @@ func (clh *cloudHypervisor) addNet(e Endpoint) error {
 		"tap": tapPath,
 	}).Info("Adding Net")
 
-	clh.vmconfig.Net = append(clh.vmconfig.Net, chclient.NetConfig{Mac: mac, Tap: tapPath})
+	clh.vmconfig.Net = append(clh.vmconfig.Net, clh.netConfig(e, netPair))
 	return nil
 }
 
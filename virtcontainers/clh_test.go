@@ -8,6 +8,7 @@ package virtcontainers
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -104,11 +105,37 @@ func (c *clhClientMock) VmAddDiskPut(ctx context.Context, diskConfig chclient.Di
 	return chclient.PciDeviceInfo{}, nil, nil
 }
 
+//nolint:golint
+func (c *clhClientMock) VmAddNetPut(ctx context.Context, netConfig chclient.NetConfig) (chclient.PciDeviceInfo, *http.Response, error) {
+	return chclient.PciDeviceInfo{Id: netConfig.Id, Bdf: "0000:00:05.0"}, nil, nil
+}
+
 //nolint:golint
 func (c *clhClientMock) VmRemoveDevicePut(ctx context.Context, vmRemoveDevice chclient.VmRemoveDevice) (*http.Response, error) {
 	return nil, nil
 }
 
+func (c *clhClientMock) PauseVM(ctx context.Context) (*http.Response, error) {
+	c.vmInfo.State = clhStatePaused
+	return nil, nil
+}
+
+func (c *clhClientMock) ResumeVM(ctx context.Context) (*http.Response, error) {
+	c.vmInfo.State = clhStateRunning
+	return nil, nil
+}
+
+//nolint:golint
+func (c *clhClientMock) VmSnapshotPut(ctx context.Context, vmSnapshotConfig chclient.VmSnapshotConfig) (*http.Response, error) {
+	return nil, nil
+}
+
+//nolint:golint
+func (c *clhClientMock) VmRestorePut(ctx context.Context, restoreConfig chclient.RestoreConfig) (*http.Response, error) {
+	c.vmInfo.State = clhStatePaused
+	return nil, nil
+}
+
 func TestCloudHypervisorAddVSock(t *testing.T) {
 	assert := assert.New(t)
 	clh := cloudHypervisor{}
@@ -151,6 +178,44 @@ func TestCloudHypervisorAddNetCheckNetConfigListValues(t *testing.T) {
 	}
 }
 
+func TestCloudHypervisorAddNetConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	clh := cloudHypervisor{}
+
+	tmpfile, err := ioutil.TempFile("", "vc-clh-net-")
+	assert.NoError(err)
+	defer os.Remove(tmpfile.Name())
+
+	e := &VethEndpoint{}
+	e.NetPair.TAPIface.Name = "tap0_kata"
+	e.NetPair.Config = NetInterfaceConfig{RxQueueSize: 256, TxQueueSize: 512}
+
+	assert.NoError(clh.addNet(e))
+	assert.Equal(int32(0), clh.vmconfig.Net[0].NumQueues)
+	assert.Equal(int32(512), clh.vmconfig.Net[0].QueueSize)
+
+	e.NetPair.VMFds = []*os.File{tmpfile, tmpfile}
+	e.NetPair.Config = NetInterfaceConfig{}
+
+	assert.NoError(clh.addNet(e))
+	assert.Equal(int32(4), clh.vmconfig.Net[1].NumQueues)
+	assert.Equal(int32(0), clh.vmconfig.Net[1].QueueSize)
+	assert.Zero(clh.vmconfig.Net[1].RateLimiterConfig.Bandwidth.Size)
+
+	// The rate limiter applies to both directions.
+	e.NetPair.Config = NetInterfaceConfig{RxRateLimiterMaxRate: 8000000, TxRateLimiterMaxRate: 8000000}
+
+	assert.NoError(clh.addNet(e))
+	assert.Equal(int64(1000000), clh.vmconfig.Net[2].RateLimiterConfig.Bandwidth.Size)
+	assert.Equal(int64(1000), clh.vmconfig.Net[2].RateLimiterConfig.Bandwidth.RefillTime)
+
+	e.NetPair.Config = NetInterfaceConfig{RxRateLimiterMaxRate: 8000000}
+
+	assert.NoError(clh.addNet(e))
+	assert.Zero(clh.vmconfig.Net[3].RateLimiterConfig.Bandwidth.Size)
+}
+
 // Check addNet with valid values, and fail with invalid values
 // For Cloud Hypervisor only tap is be required
 func TestCloudHypervisorAddNetCheckEnpointTypes(t *testing.T) {
@@ -406,6 +471,151 @@ func TestCloudHypervisorHotplugRemoveDevice(t *testing.T) {
 	_, err = clh.hotplugRemoveDevice(&config.VFIODev{}, vfioDev)
 	assert.NoError(err, "Hotplug remove vfio block device expected no error")
 
-	_, err = clh.hotplugRemoveDevice(nil, netDev)
-	assert.Error(err, "Hotplug remove pmem block device expected error")
+	_, err = clh.hotplugRemoveDevice(&PhysicalEndpoint{}, netDev)
+	assert.Error(err, "Hotplug remove net device without network pair expected error")
+
+	endpoint, err := createVethNetworkEndpoint(1, "eth1", NetXConnectTCFilterModel)
+	assert.NoError(err)
+	_, err = clh.hotplugRemoveDevice(endpoint, netDev)
+	assert.NoError(err, "Hotplug remove net device expected no error")
+
+	_, err = clh.hotplugRemoveDevice(nil, imgDev)
+	assert.Error(err, "Hotplug remove image device expected error")
+}
+
+func TestCloudHypervisorPauseResumeSandbox(t *testing.T) {
+	assert := assert.New(t)
+
+	mockClient := &clhClientMock{}
+	clh := &cloudHypervisor{}
+	clh.APIClient = mockClient
+
+	err := clh.pauseSandbox()
+	assert.NoError(err)
+	assert.Equal(clhStatePaused, mockClient.vmInfo.State)
+
+	err = clh.resumeSandbox()
+	assert.NoError(err)
+	assert.Equal(clhStateRunning, mockClient.vmInfo.State)
+}
+
+func TestCloudHypervisorSaveSandbox(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "clh-snapshot")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	clh := &cloudHypervisor{}
+	clh.APIClient = &clhClientMock{}
+
+	err = clh.saveSandbox("")
+	assert.Error(err)
+
+	statePath := filepath.Join(dir, "vm.state")
+	err = clh.saveSandbox(statePath)
+	assert.NoError(err)
+
+	info, err := os.Stat(statePath)
+	assert.NoError(err)
+	assert.True(info.IsDir())
+}
+
+func TestCloudHypervisorRestoreVM(t *testing.T) {
+	assert := assert.New(t)
+
+	clh := &cloudHypervisor{}
+	clh.APIClient = &clhClientMock{}
+	clh.config.DevicesStatePath = "/foo/vm.state"
+
+	err := clh.restoreVM(clh.config.DevicesStatePath)
+	assert.NoError(err)
+
+	caps := clh.capabilities()
+	assert.True(caps.IsSnapshotSupported())
+}
+
+func TestCloudHypervisorTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "clh-template")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	clh := &cloudHypervisor{id: "template-vm-id"}
+	clh.APIClient = &clhClientMock{}
+	clh.config.BootToBeTemplate = true
+
+	caps := clh.capabilities()
+	assert.True(caps.IsTemplateSupported())
+
+	err = clh.checkTemplateConfig(&clh.config)
+	assert.Error(err)
+	clh.config.DevicesStatePath = filepath.Join(dir, "state")
+	err = clh.checkTemplateConfig(&clh.config)
+	assert.NoError(err)
+
+	// The mock client does not write the snapshot, fake it
+	templatePath := clh.config.DevicesStatePath
+	assert.NoError(os.MkdirAll(templatePath, DirMode))
+	config := []byte(`{"vsock":{"sock":"/run/vc/vm/template-vm-id/clh.sock"}}`)
+	assert.NoError(ioutil.WriteFile(filepath.Join(templatePath, "config.json"), config, 0640))
+	assert.NoError(ioutil.WriteFile(filepath.Join(templatePath, "memory-ranges"), []byte("memory"), 0640))
+
+	err = clh.saveSandbox(templatePath)
+	assert.NoError(err)
+
+	snapshotPath := filepath.Join(dir, "vm-snapshot")
+	err = clhCloneSnapshot(templatePath, snapshotPath, "vm-id")
+	assert.NoError(err)
+
+	config, err = ioutil.ReadFile(filepath.Join(snapshotPath, "config.json"))
+	assert.NoError(err)
+	assert.Equal(`{"vsock":{"sock":"/run/vc/vm/vm-id/clh.sock"}}`, string(config))
+
+	link, err := os.Readlink(filepath.Join(snapshotPath, "memory-ranges"))
+	assert.NoError(err)
+	assert.Equal(filepath.Join(templatePath, "memory-ranges"), link)
+
+	_, err = os.Stat(filepath.Join(snapshotPath, clhTemplateIDFile))
+	assert.True(os.IsNotExist(err))
+
+	// Not a template snapshot
+	err = clhCloneSnapshot(snapshotPath, filepath.Join(dir, "foo"), "vm-id")
+	assert.Error(err)
+}
+
+func TestCloudHypervisorHotplugAddNetDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	clhConfig, err := newClhConfig()
+	assert.NoError(err)
+
+	clh := &cloudHypervisor{}
+	clh.config = clhConfig
+	clh.APIClient = &clhClientMock{}
+
+	_, err = clh.hotplugAddDevice(&PhysicalEndpoint{}, netDev)
+	assert.Error(err, "Hotplug net device without network pair expected error")
+
+	endpoint, err := createVethNetworkEndpoint(1, "eth1", NetXConnectTCFilterModel)
+	assert.NoError(err)
+
+	_, err = clh.hotplugAddDevice(endpoint, netDev)
+	assert.NoError(err, "Hotplug net device expected no error")
+	assert.Equal("05", endpoint.PciPath().String())
+}
+
+func TestClhPciPathFromBdf(t *testing.T) {
+	assert := assert.New(t)
+
+	pciPath, err := clhPciPathFromBdf("0000:00:1f.0")
+	assert.NoError(err)
+	assert.Equal("1f", pciPath.String())
+
+	_, err = clhPciPathFromBdf("0000:01:02.0")
+	assert.Error(err)
+
+	_, err = clhPciPathFromBdf("foo")
+	assert.Error(err)
 }
@@ -70,6 +70,10 @@ type Process struct {
 	// shim PID.
 	Pid int
 
+	// GuestPid is the process ID as seen in the VM, 0 when
+	// it is not known.
+	GuestPid int
+
 	StartTime time.Time
 }
 
@@ -78,6 +82,7 @@ type ContainerStatus struct {
 	ID        string
 	State     types.ContainerState
 	PID       int
+	GuestPID  int
 	StartTime time.Time
 	RootFs    string
 	Spec      *specs.Spec
@@ -955,6 +960,8 @@ func (c *Container) create() (err error) {
 		return
 	}
 
+	c.sandbox.emit(EventContainerCreated, c.id, nil)
+
 	return nil
 }
 
@@ -1023,6 +1030,16 @@ func (c *Container) start() error {
 		return err
 	}
 
+	if cs, ok := c.sandbox.checkpointed[c.id]; ok {
+		delete(c.sandbox.checkpointed, c.id)
+
+		// The container was already running when the sandbox got checkpointed.
+		if types.StateString(cs.State) == types.StateRunning {
+			c.Logger().Info("container restored from checkpoint is running")
+			return c.setContainerState(types.StateRunning)
+		}
+	}
+
 	if err := c.sandbox.agent.startContainer(c.sandbox, c); err != nil {
 		c.Logger().WithError(err).Error("Failed to start container")
 
@@ -1032,7 +1049,43 @@ func (c *Container) start() error {
 		return err
 	}
 
-	return c.setContainerState(types.StateRunning)
+	if err := c.setContainerState(types.StateRunning); err != nil {
+		return err
+	}
+
+	c.sandbox.emit(EventContainerStarted, c.id, nil)
+
+	return nil
+}
+
+// restartIn brings a container stopped along with the sandbox VM back to
+// the state it was in, in the restarted VM.
+func (c *Container) restartIn(state types.StateString) error {
+	switch state {
+	case types.StateReady, types.StateRunning, types.StatePaused:
+	default:
+		return nil
+	}
+
+	c.Logger().WithField("state", state).Info("Restoring container in restarted VM")
+
+	if err := c.create(); err != nil {
+		return err
+	}
+
+	if state == types.StateReady {
+		return nil
+	}
+
+	if err := c.start(); err != nil {
+		return err
+	}
+
+	if state == types.StatePaused {
+		return c.pause()
+	}
+
+	return nil
 }
 
 func (c *Container) stop(force bool) error {
@@ -1148,6 +1201,8 @@ func (c *Container) stop(force bool) error {
 		return err
 	}
 
+	c.sandbox.emit(EventContainerStopped, c.id, nil)
+
 	return nil
 }
 
@@ -1167,6 +1222,10 @@ func (c *Container) enter(cmd types.Cmd) (*Process, error) {
 		return nil, err
 	}
 
+	if process != nil && process.GuestPid != 0 {
+		c.sandbox.setExecGuestPid(process.Token, process.GuestPid)
+	}
+
 	return process, nil
 }
 
@@ -1177,6 +1236,8 @@ func (c *Container) wait(processID string) (int32, error) {
 			"impossible to wait")
 	}
 
+	defer c.sandbox.removeExecGuestPid(processID)
+
 	return c.sandbox.agent.waitProcess(c, processID)
 }
 
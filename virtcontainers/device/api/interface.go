@@ -46,6 +46,13 @@ type DeviceReceiver interface {
 	AppendDevice(Device) error
 }
 
+// DeviceEventReceiver is implemented by the device receivers notified
+// of the devices the device manager attaches to, or detaches from, them.
+type DeviceEventReceiver interface {
+	DeviceAttached(Device)
+	DeviceDetached(Device)
+}
+
 // Device is the virtcontainers device interface.
 type Device interface {
 	Attach(DeviceReceiver) error
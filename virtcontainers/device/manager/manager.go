@@ -201,6 +201,10 @@ func (dm *deviceManager) AttachDevice(id string, dr api.DeviceReceiver) error {
 	if err := d.Attach(dr); err != nil {
 		return err
 	}
+
+	if er, ok := dr.(api.DeviceEventReceiver); ok {
+		er.DeviceAttached(d)
+	}
 	return nil
 }
 
@@ -219,6 +223,10 @@ func (dm *deviceManager) DetachDevice(id string, dr api.DeviceReceiver) error {
 	if err := d.Detach(dr); err != nil {
 		return err
 	}
+
+	if er, ok := dr.(api.DeviceEventReceiver); ok {
+		er.DeviceDetached(d)
+	}
 	return nil
 }
 
@@ -344,3 +344,45 @@ func TestAttachDetachDevice(t *testing.T) {
 	err = dm.RemoveDevice(device.DeviceID())
 	assert.Nil(t, err)
 }
+
+type eventDeviceReceiver struct {
+	api.MockDeviceReceiver
+
+	attached []string
+	detached []string
+}
+
+func (r *eventDeviceReceiver) DeviceAttached(d api.Device) {
+	r.attached = append(r.attached, d.DeviceID())
+}
+
+func (r *eventDeviceReceiver) DeviceDetached(d api.Device) {
+	r.detached = append(r.detached, d.DeviceID())
+}
+
+func TestAttachDetachDeviceEvents(t *testing.T) {
+	dm := NewDeviceManager(VirtioBlock, false, "", nil)
+	path := "/dev/tty2"
+	deviceInfo := config.DeviceInfo{
+		HostPath:      path,
+		ContainerPath: path,
+		DevType:       "c",
+	}
+
+	device, err := dm.NewDevice(deviceInfo)
+	assert.Nil(t, err)
+
+	devReceiver := &eventDeviceReceiver{}
+	err = dm.AttachDevice(device.DeviceID(), devReceiver)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{device.DeviceID()}, devReceiver.attached)
+
+	err = dm.DetachDevice(device.DeviceID(), devReceiver)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{device.DeviceID()}, devReceiver.detached)
+
+	// failed detaches are not notified
+	err = dm.DetachDevice(device.DeviceID(), devReceiver)
+	assert.Error(t, err)
+	assert.Len(t, devReceiver.detached, 1)
+}
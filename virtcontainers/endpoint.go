@@ -168,6 +168,8 @@ func saveNetIfPair(pair *NetworkInterfacePair) *persistapi.NetworkInterfacePair
 		TapInterface:         *tapif,
 		VirtIface:            virtif,
 		NetInterworkingModel: int(pair.NetInterworkingModel),
+		Bridge:               pair.Bridge,
+		Config:               persistapi.NetInterfaceConfig(pair.Config),
 	}
 }
 
@@ -190,6 +192,8 @@ func loadNetIfPair(pair *persistapi.NetworkInterfacePair) *NetworkInterfacePair
 		TapInterface:         *tapif,
 		VirtIface:            virtif,
 		NetInterworkingModel: NetInterworkingModel(pair.NetInterworkingModel),
+		Bridge:               pair.Bridge,
+		Config:               NetInterfaceConfig(pair.Config),
 	}
 }
 
@@ -107,6 +107,12 @@ func TestSaveLoadIfPair(t *testing.T) {
 			HardAddr: macAddr.String(),
 		},
 		NetInterworkingModel: DefaultNetInterworkingModel,
+		Bridge:               "br4_kata",
+		Config: NetInterfaceConfig{
+			Queues:     2,
+			MTU:        1400,
+			DisableTSO: true,
+		},
 	}
 
 	// Save to disk then load it back.
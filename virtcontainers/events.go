@@ -0,0 +1,290 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kata-containers/runtime/virtcontainers/device/api"
+	"github.com/kata-containers/runtime/virtcontainers/device/config"
+)
+
+// eventChannelSize is the number of events a subscriber can lag behind
+// before events get dropped for it.
+const eventChannelSize = 128
+
+// EventType is the type of a sandbox lifecycle event.
+type EventType string
+
+const (
+	// EventVMStarted is emitted once the sandbox VM is booted.
+	EventVMStarted EventType = "vm-started"
+
+	// EventVMStopped is emitted once the sandbox VM is stopped.
+	EventVMStopped EventType = "vm-stopped"
+
+	// EventVMRestarted is emitted once the sandbox VM got replaced by a
+	// new one.
+	EventVMRestarted EventType = "vm-restarted"
+
+	// EventAgentConnected is emitted once the agent is up in the sandbox
+	// VM.
+	EventAgentConnected EventType = "agent-connected"
+
+	// EventContainerCreated is emitted once a container is created.
+	EventContainerCreated EventType = "container-created"
+
+	// EventContainerStarted is emitted once a container is started.
+	EventContainerStarted EventType = "container-started"
+
+	// EventContainerStopped is emitted once a container is stopped.
+	EventContainerStopped EventType = "container-stopped"
+
+	// EventDeviceHotplugged is emitted once a device is hotplugged into
+	// the sandbox VM.
+	EventDeviceHotplugged EventType = "device-hotplugged"
+
+	// EventDeviceHotunplugged is emitted once a device is hot unplugged
+	// from the sandbox VM.
+	EventDeviceHotunplugged EventType = "device-hotunplugged"
+
+	// EventDeviceAttached is emitted once the device manager attached a
+	// device to the sandbox.
+	EventDeviceAttached EventType = "device-attached"
+
+	// EventDeviceDetached is emitted once the device manager detached a
+	// device from the sandbox.
+	EventDeviceDetached EventType = "device-detached"
+
+	// EventVCPUsResized is emitted once the number of vCPUs of the
+	// sandbox VM changed.
+	EventVCPUsResized EventType = "vcpus-resized"
+
+	// EventMemoryResized is emitted once the memory size of the sandbox
+	// VM changed.
+	EventMemoryResized EventType = "memory-resized"
+
+	// EventOOM is emitted when a container process got killed because
+	// it ran out of memory.
+	EventOOM EventType = "oom"
+)
+
+// Event is a sandbox lifecycle event.
+type Event struct {
+	Type      EventType
+	SandboxID string
+	// ContainerID is the container the event is about, if any.
+	ContainerID string
+	Timestamp   time.Time
+	// Data holds the event details, e.g. the new memory size.
+	Data map[string]string
+}
+
+// eventEmitter broadcasts the sandbox events to its subscribers. Events
+// are dropped for the subscribers not keeping up. The events emitted
+// before the first subscription, such as the VM boot ones, are kept for
+// the first subscriber.
+type eventEmitter struct {
+	sync.Mutex
+
+	subscribers []chan Event
+	backlog     []Event
+	closed      bool
+}
+
+func (e *eventEmitter) subscribe() <-chan Event {
+	e.Lock()
+	defer e.Unlock()
+
+	ch := make(chan Event, eventChannelSize)
+	if e.closed {
+		close(ch)
+		return ch
+	}
+
+	for _, event := range e.backlog {
+		ch <- event
+	}
+	e.backlog = nil
+
+	e.subscribers = append(e.subscribers, ch)
+
+	return ch
+}
+
+func (e *eventEmitter) emit(event Event) {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.closed {
+		return
+	}
+
+	if len(e.subscribers) == 0 {
+		if len(e.backlog) < eventChannelSize {
+			e.backlog = append(e.backlog, event)
+		}
+		return
+	}
+
+	for _, ch := range e.subscribers {
+		select {
+		case ch <- event:
+		default:
+			virtLog.WithField("event", event.Type).Warn("event channel is full, dropping event")
+		}
+	}
+}
+
+func (e *eventEmitter) close() {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.closed {
+		return
+	}
+	e.closed = true
+
+	for _, ch := range e.subscribers {
+		close(ch)
+	}
+	e.subscribers = nil
+	e.backlog = nil
+}
+
+// Events returns a channel the sandbox lifecycle events are sent on. The
+// channel is closed when the sandbox is deleted.
+func (s *Sandbox) Events() <-chan Event {
+	if s.events == nil {
+		return nil
+	}
+
+	return s.events.subscribe()
+}
+
+func (s *Sandbox) emit(eventType EventType, containerID string, data map[string]string) {
+	if s.events == nil {
+		return
+	}
+
+	s.events.emit(Event{
+		Type:        eventType,
+		SandboxID:   s.id,
+		ContainerID: containerID,
+		Timestamp:   time.Now(),
+		Data:        data,
+	})
+}
+
+func (s *Sandbox) emitDeviceEvent(eventType EventType, device api.Device, devType config.DeviceType) {
+	s.emit(eventType, "", map[string]string{
+		"device-id":   device.DeviceID(),
+		"device-type": string(devType),
+	})
+}
+
+// DeviceAttached implements api.DeviceEventReceiver.
+func (s *Sandbox) DeviceAttached(device api.Device) {
+	s.emitAttachEvent(EventDeviceAttached, device)
+}
+
+// DeviceDetached implements api.DeviceEventReceiver.
+func (s *Sandbox) DeviceDetached(device api.Device) {
+	s.emitAttachEvent(EventDeviceDetached, device)
+}
+
+func (s *Sandbox) emitAttachEvent(eventType EventType, device api.Device) {
+	s.emit(eventType, "", map[string]string{
+		"device-id":    device.DeviceID(),
+		"device-type":  string(device.DeviceType()),
+		"host-path":    device.GetHostPath(),
+		"attach-count": strconv.FormatUint(uint64(device.GetAttachCount()), 10),
+	})
+}
+
+// eventHypervisor emits the events of the sandbox VM it wraps, from
+// whichever path the VM gets booted, stopped or resized: sandbox start,
+// VM restart, resources update.
+type eventHypervisor struct {
+	hypervisor
+
+	sandbox *Sandbox
+	// memoryMB is the VM memory size as of the last resize, the
+	// configured one if not resized yet.
+	memoryMB uint32
+}
+
+func newEventHypervisor(s *Sandbox, h hypervisor) hypervisor {
+	return &eventHypervisor{
+		hypervisor: h,
+		sandbox:    s,
+	}
+}
+
+func (h *eventHypervisor) startSandbox(timeout int) error {
+	if err := h.hypervisor.startSandbox(timeout); err != nil {
+		return err
+	}
+
+	h.sandbox.emit(EventVMStarted, "", nil)
+
+	return nil
+}
+
+func (h *eventHypervisor) stopSandbox() error {
+	if err := h.hypervisor.stopSandbox(); err != nil {
+		return err
+	}
+
+	h.sandbox.emit(EventVMStopped, "", nil)
+
+	return nil
+}
+
+func (h *eventHypervisor) resizeVCPUs(vcpus uint32) (uint32, uint32, error) {
+	oldCPUs, newCPUs, err := h.hypervisor.resizeVCPUs(vcpus)
+	if err != nil {
+		return oldCPUs, newCPUs, err
+	}
+
+	if oldCPUs != newCPUs {
+		h.sandbox.emit(EventVCPUsResized, "", map[string]string{
+			"old-vcpus": strconv.FormatUint(uint64(oldCPUs), 10),
+			"vcpus":     strconv.FormatUint(uint64(newCPUs), 10),
+		})
+	}
+
+	return oldCPUs, newCPUs, nil
+}
+
+func (h *eventHypervisor) resizeMemory(memMB uint32, memoryBlockSizeMB uint32, probe bool) (uint32, memoryDevice, error) {
+	newMemMB, memDev, err := h.hypervisor.resizeMemory(memMB, memoryBlockSizeMB, probe)
+	if err != nil {
+		return newMemMB, memDev, err
+	}
+
+	// Hypervisors not supporting memory resizing report no size.
+	if newMemMB == 0 {
+		return newMemMB, memDev, nil
+	}
+
+	oldMemMB := h.memoryMB
+	if oldMemMB == 0 {
+		oldMemMB = h.hypervisorConfig().MemorySize
+	}
+	h.memoryMB = newMemMB
+
+	if oldMemMB != newMemMB {
+		h.sandbox.emit(EventMemoryResized, "", map[string]string{
+			"old-memory-mb": strconv.FormatUint(uint64(oldMemMB), 10),
+			"memory-mb":     strconv.FormatUint(uint64(newMemMB), 10),
+		})
+	}
+
+	return newMemMB, memDev, nil
+}
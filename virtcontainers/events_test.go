@@ -0,0 +1,202 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"testing"
+
+	"github.com/kata-containers/runtime/virtcontainers/device/config"
+	"github.com/kata-containers/runtime/virtcontainers/device/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+func drainEvents(ch <-chan Event) []EventType {
+	var types []EventType
+
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return types
+			}
+			types = append(types, e.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func TestEventEmitter(t *testing.T) {
+	assert := assert.New(t)
+
+	e := &eventEmitter{}
+
+	// events emitted before the first subscription are kept for it
+	e.emit(Event{Type: EventVMStarted})
+	e.emit(Event{Type: EventAgentConnected})
+
+	first := e.subscribe()
+	second := e.subscribe()
+
+	e.emit(Event{Type: EventVMStopped})
+
+	assert.Equal([]EventType{EventVMStarted, EventAgentConnected, EventVMStopped}, drainEvents(first))
+	assert.Equal([]EventType{EventVMStopped}, drainEvents(second))
+
+	// events are dropped for the subscribers not keeping up
+	for i := 0; i < eventChannelSize+1; i++ {
+		e.emit(Event{Type: EventOOM})
+	}
+	assert.Len(drainEvents(first), eventChannelSize)
+
+	e.close()
+	_, ok := <-first
+	assert.False(ok)
+	_, ok = <-e.subscribe()
+	assert.False(ok)
+
+	// emitting or closing again after close is harmless
+	e.emit(Event{Type: EventOOM})
+	e.close()
+}
+
+func TestSandboxEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	// a sandbox not created through newSandbox has no events
+	assert.Nil((&Sandbox{}).Events())
+	(&Sandbox{}).emit(EventOOM, "", nil)
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, nil, nil)
+	assert.NoError(err)
+	defer cleanUp()
+
+	ch := s.Events()
+
+	err = s.startVM()
+	assert.NoError(err)
+	assert.Equal([]EventType{EventVMStarted, EventAgentConnected}, drainEvents(ch))
+
+	err = s.Start()
+	assert.NoError(err)
+
+	contID := "999"
+	_, err = s.CreateContainer(newTestContainerConfigNoop(contID))
+	assert.NoError(err)
+	_, err = s.StartContainer(contID)
+	assert.NoError(err)
+
+	events := drainEvents(ch)
+	assert.Contains(events, EventContainerCreated)
+	assert.Contains(events, EventContainerStarted)
+
+	_, err = s.GetOOMEvent()
+	assert.NoError(err)
+	assert.Equal([]EventType{EventOOM}, drainEvents(ch))
+
+	err = s.Stop(true)
+	assert.NoError(err)
+
+	events = drainEvents(ch)
+	assert.Contains(events, EventContainerStopped)
+	assert.Contains(events, EventVMStopped)
+
+	err = s.Delete()
+	assert.NoError(err)
+
+	_, ok := <-ch
+	assert.False(ok)
+}
+
+type resizeHypervisor struct {
+	mockHypervisor
+
+	vcpus    uint32
+	memoryMB uint32
+}
+
+func (h *resizeHypervisor) resizeVCPUs(vcpus uint32) (uint32, uint32, error) {
+	oldVCPUs := h.vcpus
+	h.vcpus = vcpus
+	return oldVCPUs, vcpus, nil
+}
+
+func (h *resizeHypervisor) resizeMemory(memMB uint32, memoryBlockSizeMB uint32, probe bool) (uint32, memoryDevice, error) {
+	h.memoryMB = memMB
+	return memMB, memoryDevice{}, nil
+}
+
+func (h *resizeHypervisor) hypervisorConfig() HypervisorConfig {
+	return HypervisorConfig{MemorySize: 2048}
+}
+
+func TestEventHypervisor(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Sandbox{id: testSandboxID, events: &eventEmitter{}}
+	h := newEventHypervisor(s, &resizeHypervisor{vcpus: 1})
+	ch := s.Events()
+
+	err := h.startSandbox(vmStartTimeout)
+	assert.NoError(err)
+	err = h.stopSandbox()
+	assert.NoError(err)
+	assert.Equal([]EventType{EventVMStarted, EventVMStopped}, drainEvents(ch))
+
+	// nothing is emitted unless resized
+	_, _, err = h.resizeVCPUs(1)
+	assert.NoError(err)
+	_, _, err = h.resizeMemory(2048, 0, false)
+	assert.NoError(err)
+	assert.Empty(drainEvents(ch))
+
+	_, _, err = h.resizeVCPUs(2)
+	assert.NoError(err)
+	e := <-ch
+	assert.Equal(EventVCPUsResized, e.Type)
+	assert.Equal(map[string]string{"old-vcpus": "1", "vcpus": "2"}, e.Data)
+
+	_, _, err = h.resizeMemory(4096, 0, false)
+	assert.NoError(err)
+	e = <-ch
+	assert.Equal(EventMemoryResized, e.Type)
+	assert.Equal(map[string]string{"old-memory-mb": "2048", "memory-mb": "4096"}, e.Data)
+
+	_, _, err = h.resizeMemory(3072, 0, false)
+	assert.NoError(err)
+	e = <-ch
+	assert.Equal(map[string]string{"old-memory-mb": "4096", "memory-mb": "3072"}, e.Data)
+
+	// hypervisors not supporting resizing report no size
+	h = newEventHypervisor(s, &mockHypervisor{})
+	_, _, err = h.resizeVCPUs(2)
+	assert.NoError(err)
+	_, _, err = h.resizeMemory(4096, 0, false)
+	assert.NoError(err)
+	assert.Empty(drainEvents(ch))
+}
+
+func TestSandboxDeviceEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Sandbox{id: testSandboxID, events: &eventEmitter{}}
+	ch := s.Events()
+
+	device := drivers.NewGenericDevice(&config.DeviceInfo{
+		ID:       "foo",
+		HostPath: "/dev/foo",
+		DevType:  "c",
+	})
+
+	s.DeviceAttached(device)
+	s.DeviceDetached(device)
+
+	e := <-ch
+	assert.Equal(EventDeviceAttached, e.Type)
+	assert.Equal("foo", e.Data["device-id"])
+	assert.Equal("/dev/foo", e.Data["host-path"])
+	assert.Equal(EventDeviceDetached, (<-ch).Type)
+}
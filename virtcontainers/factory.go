@@ -19,6 +19,9 @@ type Factory interface {
 	// GetVMStatus returns the status of the paused VM created by the base factory.
 	GetVMStatus() []*pb.GrpcVMStatus
 
+	// GetCacheStats returns the stats of the VM cache of the base factory.
+	GetCacheStats() *pb.GrpcCacheStats
+
 	// GetVM gets a new VM from the factory.
 	GetVM(ctx context.Context, config VMConfig) (*VM, error)
 
@@ -24,6 +24,9 @@ type FactoryBase interface {
 	// GetVMStatus returns the status of the paused VM created by the base factory.
 	GetVMStatus() []*pb.GrpcVMStatus
 
+	// GetCacheStats returns the stats of the VM cache of the base factory.
+	GetCacheStats() *pb.GrpcCacheStats
+
 	// GetBaseVM returns a paused VM created by the base factory.
 	GetBaseVM(ctx context.Context, config vc.VMConfig) (*vc.VM, error)
 
@@ -10,81 +10,208 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	pb "github.com/kata-containers/runtime/protocols/cache"
 	vc "github.com/kata-containers/runtime/virtcontainers"
 	"github.com/kata-containers/runtime/virtcontainers/factory/base"
 )
 
+// Config is the configuration of a cache vm factory.
+type Config struct {
+	// MinVMs is the low watermark, the number of VMs the cache holds
+	// when idle.
+	MinVMs uint
+
+	// MaxVMs is the high watermark, the number of VMs the cache grows up
+	// to when it runs out of VMs. It is raised to MinVMs when lower.
+	MaxVMs uint
+
+	// RefillInterval is the minimum interval between two VM boots of
+	// the cache.
+	RefillInterval time.Duration
+
+	// IdleTimeout is the time after which an unused VM above the low
+	// watermark is stopped, 0 keeping the VMs until the cache is closed.
+	IdleTimeout time.Duration
+}
+
+type cachedVM struct {
+	vm *vc.VM
+
+	// since is the time the VM was added to the cache.
+	since time.Time
+}
+
 type cache struct {
-	base base.FactoryBase
+	base   base.FactoryBase
+	config Config
+
+	// vms are the cached VMs, from the least to the most recently added.
+	vms    []cachedVM
+	target uint
+	stats  pb.GrpcCacheStats
+	closed bool
+	lock   sync.Mutex
 
-	cacheCh   chan *vc.VM
-	closed    chan<- int
+	refill    chan struct{}
+	done      chan struct{}
 	wg        sync.WaitGroup
 	closeOnce sync.Once
-
-	vmm     map[*vc.VM]interface{}
-	vmmLock sync.RWMutex
 }
 
 // New creates a new cached vm factory.
-func New(ctx context.Context, count uint, b base.FactoryBase) base.FactoryBase {
-	if count < 1 {
+func New(ctx context.Context, config Config, b base.FactoryBase) base.FactoryBase {
+	if config.MaxVMs < config.MinVMs {
+		config.MaxVMs = config.MinVMs
+	}
+
+	if config.MaxVMs < 1 {
 		return b
 	}
 
-	cacheCh := make(chan *vc.VM)
-	closed := make(chan int, count)
 	c := cache{
-		base:    b,
-		cacheCh: cacheCh,
-		closed:  closed,
-		vmm:     make(map[*vc.VM]interface{}),
+		base:   b,
+		config: config,
+		target: config.MinVMs,
+		refill: make(chan struct{}, 1),
+		done:   make(chan struct{}),
 	}
-	for i := 0; i < int(count); i++ {
-		c.wg.Add(1)
-		go func() {
-			for {
-				vm, err := b.GetBaseVM(ctx, c.Config())
-				if err != nil {
+
+	c.wg.Add(1)
+	go c.run(ctx)
+
+	return &c
+}
+
+// run boots VMs until the cache holds as many as targeted, and stops the
+// idle ones.
+func (c *cache) run(ctx context.Context) {
+	var lastBoot time.Time
+
+	for {
+		next := c.expire()
+
+		if c.needVM() {
+			if wait := time.Until(lastBoot.Add(c.config.RefillInterval)); wait > 0 {
+				next = wait
+			} else {
+				lastBoot = time.Now()
+				if err := c.boot(ctx); err != nil {
 					c.wg.Done()
 					c.CloseFactory(ctx)
 					return
 				}
-				c.addToVmm(vm)
-
-				select {
-				case cacheCh <- vm:
-					// Because vm will not be relased or changed
-					// by cacheServer.GetBaseVM or removeFromVmm.
-					// So removeFromVmm can be called after vm send to cacheCh.
-					c.removeFromVmm(vm)
-				case <-closed:
-					c.removeFromVmm(vm)
-					vm.Stop()
-					vm.Disconnect()
-					c.wg.Done()
-					return
-				}
+				continue
 			}
-		}()
+		}
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if next > 0 {
+			timer = time.NewTimer(next)
+			timeout = timer.C
+		}
+
+		select {
+		case <-c.done:
+			c.wg.Done()
+			return
+		case <-c.refill:
+		case <-timeout:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
 	}
-	return &c
 }
 
-func (c *cache) addToVmm(vm *vc.VM) {
-	c.vmmLock.Lock()
-	defer c.vmmLock.Unlock()
+func (c *cache) needVM() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return !c.closed && uint(len(c.vms)) < c.target
+}
+
+func (c *cache) boot(ctx context.Context) error {
+	vm, err := c.getBaseVM(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.vms = append(c.vms, cachedVM{vm: vm, since: time.Now()})
+
+	return nil
+}
+
+// getBaseVM boots a VM from the base factory.
+func (c *cache) getBaseVM(ctx context.Context) (*vc.VM, error) {
+	start := time.Now()
+
+	vm, err := c.base.GetBaseVM(ctx, c.Config())
+	if err != nil {
+		return nil, err
+	}
+
+	bootTime := int64(time.Since(start))
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stats.Boots++
+	c.stats.BootTime += bootTime
+	if bootTime > c.stats.MaxBootTime {
+		c.stats.MaxBootTime = bootTime
+	}
 
-	c.vmm[vm] = nil
+	return vm, nil
 }
 
-func (c *cache) removeFromVmm(vm *vc.VM) {
-	c.vmmLock.Lock()
-	defer c.vmmLock.Unlock()
+// expire stops the VMs idle for longer than the idle timeout, the cache
+// shrinking back to its low watermark. It returns the time until the next
+// VM expires, 0 if none does.
+func (c *cache) expire() time.Duration {
+	if c.config.IdleTimeout == 0 {
+		return 0
+	}
+
+	var expired []*vc.VM
+	var next time.Duration
+
+	c.lock.Lock()
+	for uint(len(c.vms)) > c.config.MinVMs {
+		idle := time.Since(c.vms[0].since)
+		if idle < c.config.IdleTimeout {
+			next = c.config.IdleTimeout - idle
+			break
+		}
+
+		expired = append(expired, c.vms[0].vm)
+		c.vms = c.vms[1:]
+		c.stats.Evictions++
+		if c.target > c.config.MinVMs {
+			c.target--
+		}
+	}
+	c.lock.Unlock()
+
+	for _, vm := range expired {
+		vm.Stop()
+		vm.Disconnect()
+	}
+
+	return next
+}
 
-	delete(c.vmm, vm)
+func (c *cache) kick() {
+	select {
+	case c.refill <- struct{}{}:
+	default:
+	}
 }
 
 // Config returns cache vm factory's base factory config.
@@ -96,33 +223,74 @@ func (c *cache) Config() vc.VMConfig {
 func (c *cache) GetVMStatus() []*pb.GrpcVMStatus {
 	vs := []*pb.GrpcVMStatus{}
 
-	c.vmmLock.RLock()
-	defer c.vmmLock.RUnlock()
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	for vm := range c.vmm {
-		vs = append(vs, vm.GetVMStatus())
+	for _, v := range c.vms {
+		vs = append(vs, v.vm.GetVMStatus())
 	}
 
 	return vs
 }
 
-// GetBaseVM returns a base VM from cache factory's base factory.
+// GetCacheStats returns the stats of the cache.
+func (c *cache) GetCacheStats() *pb.GrpcCacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats := c.stats
+	stats.Target = uint32(c.target)
+
+	return &stats
+}
+
+// GetBaseVM returns a cached VM, or a VM from cache factory's base factory
+// when the cache is empty, the cache then growing up to its high watermark.
 func (c *cache) GetBaseVM(ctx context.Context, config vc.VMConfig) (*vc.VM, error) {
-	vm, ok := <-c.cacheCh
-	if ok {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return nil, fmt.Errorf("cache factory is closed")
+	}
+
+	if n := len(c.vms); n > 0 {
+		// The most recently added VM is used, the least recently added
+		// ones expiring first.
+		vm := c.vms[n-1].vm
+		c.vms = c.vms[:n-1]
+		c.stats.Hits++
+		c.lock.Unlock()
+
+		c.kick()
 		return vm, nil
 	}
-	return nil, fmt.Errorf("cache factory is closed")
+
+	c.stats.Misses++
+	if c.target < c.config.MaxVMs {
+		c.target++
+	}
+	c.lock.Unlock()
+
+	c.kick()
+	return c.getBaseVM(ctx)
 }
 
 // CloseFactory closes the cache factory.
 func (c *cache) CloseFactory(ctx context.Context) {
 	c.closeOnce.Do(func() {
-		for len(c.closed) < cap(c.closed) { // send sufficient closed signal
-			c.closed <- 0
-		}
+		c.lock.Lock()
+		c.closed = true
+		c.lock.Unlock()
+
+		close(c.done)
 		c.wg.Wait()
-		close(c.cacheCh)
+
+		for _, v := range c.vms {
+			v.vm.Stop()
+			v.vm.Disconnect()
+		}
+		c.vms = nil
+
 		c.base.CloseFactory(ctx)
 	})
 }
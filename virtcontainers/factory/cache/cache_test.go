@@ -8,10 +8,12 @@ package cache
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/factory/base"
 	"github.com/kata-containers/runtime/virtcontainers/factory/direct"
 	"github.com/kata-containers/runtime/virtcontainers/persist/fs"
 )
@@ -36,7 +38,7 @@ func TestTemplateFactory(t *testing.T) {
 	ctx := context.Background()
 
 	// New
-	f := New(ctx, 2, direct.New(ctx, vmConfig))
+	f := New(ctx, Config{MinVMs: 2}, direct.New(ctx, vmConfig))
 
 	// Config
 	assert.Equal(f.Config(), vmConfig)
@@ -51,3 +53,110 @@ func TestTemplateFactory(t *testing.T) {
 	// CloseFactory
 	f.CloseFactory(ctx)
 }
+
+// waitCachedVMs waits for the cache factory f to hold n VMs.
+func waitCachedVMs(f base.FactoryBase, n int) bool {
+	for i := 0; i < 100; i++ {
+		if len(f.GetVMStatus()) == n {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return false
+}
+
+func TestCacheWatermarks(t *testing.T) {
+	assert := assert.New(t)
+
+	testDir := fs.MockStorageRootPath()
+	defer fs.MockStorageDestroy()
+
+	vmConfig := vc.VMConfig{
+		HypervisorType: vc.MockHypervisor,
+		AgentType:      vc.NoopAgentType,
+		ProxyType:      vc.NoopProxyType,
+		HypervisorConfig: vc.HypervisorConfig{
+			KernelPath: testDir,
+			ImagePath:  testDir,
+		},
+	}
+
+	ctx := context.Background()
+
+	// no cache
+	b := direct.New(ctx, vmConfig)
+	assert.Equal(b, New(ctx, Config{}, b))
+
+	// The refill interval keeps the cache empty between the first two
+	// GetBaseVM calls.
+	f := New(ctx, Config{
+		MinVMs:         1,
+		MaxVMs:         2,
+		RefillInterval: 200 * time.Millisecond,
+		IdleTimeout:    300 * time.Millisecond,
+	}, b)
+	defer f.CloseFactory(ctx)
+
+	// low watermark
+	assert.True(waitCachedVMs(f, 1))
+
+	vm, err := f.GetBaseVM(ctx, vmConfig)
+	assert.NoError(err)
+	assert.NoError(vm.Stop())
+
+	// a miss grows the cache up to the high watermark
+	vm, err = f.GetBaseVM(ctx, vmConfig)
+	assert.NoError(err)
+	assert.NoError(vm.Stop())
+
+	stats := f.GetCacheStats()
+	assert.Equal(uint64(1), stats.Hits)
+	assert.Equal(uint64(1), stats.Misses)
+	assert.Equal(uint64(2), stats.Boots)
+	assert.Equal(uint32(2), stats.Target)
+	assert.True(stats.MaxBootTime <= stats.BootTime)
+
+	assert.True(waitCachedVMs(f, 2))
+
+	// the idle VM above the low watermark expires
+	assert.True(waitCachedVMs(f, 1))
+
+	stats = f.GetCacheStats()
+	assert.Equal(uint64(1), stats.Evictions)
+	assert.Equal(uint32(1), stats.Target)
+
+	f.CloseFactory(ctx)
+	_, err = f.GetBaseVM(ctx, vmConfig)
+	assert.Error(err)
+}
+
+func TestCacheRefillInterval(t *testing.T) {
+	assert := assert.New(t)
+
+	testDir := fs.MockStorageRootPath()
+	defer fs.MockStorageDestroy()
+
+	vmConfig := vc.VMConfig{
+		HypervisorType: vc.MockHypervisor,
+		AgentType:      vc.NoopAgentType,
+		ProxyType:      vc.NoopProxyType,
+		HypervisorConfig: vc.HypervisorConfig{
+			KernelPath: testDir,
+			ImagePath:  testDir,
+		},
+	}
+
+	ctx := context.Background()
+
+	f := New(ctx, Config{MinVMs: 2, RefillInterval: 200 * time.Millisecond}, direct.New(ctx, vmConfig))
+	defer f.CloseFactory(ctx)
+
+	assert.True(waitCachedVMs(f, 1))
+
+	// the second VM is not booted before the refill interval
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(f.GetVMStatus(), 1)
+
+	assert.True(waitCachedVMs(f, 2))
+}
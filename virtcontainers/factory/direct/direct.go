@@ -52,3 +52,8 @@ func (d *direct) CloseFactory(ctx context.Context) {
 func (d *direct) GetVMStatus() []*pb.GrpcVMStatus {
 	panic("ERROR: package direct does not support GetVMStatus")
 }
+
+// GetCacheStats is not supported
+func (d *direct) GetCacheStats() *pb.GrpcCacheStats {
+	panic("ERROR: package direct does not support GetCacheStats")
+}
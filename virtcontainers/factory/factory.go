@@ -8,6 +8,8 @@ package factory
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"time"
 
 	pb "github.com/kata-containers/runtime/protocols/cache"
 	vc "github.com/kata-containers/runtime/virtcontainers"
@@ -31,11 +33,52 @@ type Config struct {
 	TemplatePath    string
 	VMCacheEndpoint string
 
+	// VMCacheTLS is the mutual TLS configuration of the VMCache client.
+	VMCacheTLS grpccache.TLSConfig
+
+	// CacheMax is the number of VMs the cache grows up to when it runs
+	// out of VMs, Cache being the number of VMs it holds when idle.
+	CacheMax uint
+
+	// CacheRefillInterval is the minimum interval between two VM boots
+	// of the cache.
+	CacheRefillInterval time.Duration
+
+	// CacheIdleTimeout is the time after which an unused VM above Cache
+	// is stopped, 0 keeping the VMs.
+	CacheIdleTimeout time.Duration
+
 	VMConfig vc.VMConfig
+
+	// Pools are the named pools of VMs the factory holds besides the
+	// VMs created from VMConfig.
+	Pools []PoolConfig
 }
 
-type factory struct {
+// PoolConfig is the configuration of a named pool of factory VMs.
+type PoolConfig struct {
+	Name string
+
+	// Cache is the number of VMs cached in the pool when idle.
+	Cache uint
+
+	// CacheMax is the number of VMs cached in the pool when busy.
+	CacheMax uint
+
+	VMConfig vc.VMConfig
+}
+
+type pool struct {
+	name string
 	base base.FactoryBase
+
+	// cached tells whether the VMs of the pool are cached by the factory.
+	cached bool
+}
+
+type factory struct {
+	pool
+	pools []pool
 }
 
 func trace(parent context.Context, name string) (opentracing.Span, context.Context) {
@@ -51,45 +94,94 @@ func NewFactory(ctx context.Context, config Config, fetchOnly bool) (vc.Factory,
 	span, _ := trace(ctx, "NewFactory")
 	defer span.Finish()
 
-	err := config.VMConfig.Valid()
+	names := make(map[string]bool)
+	for _, p := range config.Pools {
+		if p.Name == "" {
+			return nil, fmt.Errorf("missing factory pool name")
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("duplicated factory pool %s", p.Name)
+		}
+		names[p.Name] = true
+	}
+
+	p, err := newPool(ctx, config, "", config.cacheConfig(config.Cache, config.CacheMax), config.VMConfig, config.TemplatePath, fetchOnly)
 	if err != nil {
 		return nil, err
 	}
 
-	if fetchOnly && config.Cache > 0 {
-		return nil, fmt.Errorf("cache factory does not support fetch")
+	f := &factory{pool: p}
+	for _, pc := range config.Pools {
+		// Each pool VM template lives in its own directory.
+		p, err := newPool(ctx, config, pc.Name, config.cacheConfig(pc.Cache, pc.CacheMax), pc.VMConfig, filepath.Join(config.TemplatePath, pc.Name), fetchOnly)
+		if err != nil {
+			f.CloseFactory(ctx)
+			return nil, fmt.Errorf("factory pool %s: %v", pc.Name, err)
+		}
+
+		f.pools = append(f.pools, p)
+	}
+
+	return f, nil
+}
+
+func (config Config) cacheConfig(min, max uint) cache.Config {
+	if max < min {
+		max = min
+	}
+
+	return cache.Config{
+		MinVMs:         min,
+		MaxVMs:         max,
+		RefillInterval: config.CacheRefillInterval,
+		IdleTimeout:    config.CacheIdleTimeout,
+	}
+}
+
+// newPool returns the pool name, "" being the one of the VMs created from
+// config.VMConfig.
+func newPool(ctx context.Context, config Config, name string, cacheConfig cache.Config, vmConfig vc.VMConfig, templatePath string, fetchOnly bool) (pool, error) {
+	err := vmConfig.Valid()
+	if err != nil {
+		return pool{}, err
 	}
 
 	var b base.FactoryBase
+	cached := false
 	if config.VMCache && config.Cache == 0 {
-		// For VMCache client
-		b, err = grpccache.New(ctx, config.VMCacheEndpoint)
+		// For VMCache client, the VMs being cached by the server
+		b, err = grpccache.New(ctx, config.VMCacheEndpoint, config.VMCacheTLS, name)
 		if err != nil {
-			return nil, err
+			return pool{}, err
 		}
 	} else {
+		cached = cacheConfig.MaxVMs > 0
+		if fetchOnly && cached {
+			return pool{}, fmt.Errorf("cache factory does not support fetch")
+		}
+
 		if config.Template {
 			if fetchOnly {
-				b, err = template.Fetch(config.VMConfig, config.TemplatePath)
+				b, err = template.Fetch(vmConfig, templatePath)
 				if err != nil {
-					return nil, err
+					return pool{}, err
 				}
 			} else {
-				b, err = template.New(ctx, config.VMConfig, config.TemplatePath)
+				b, err = template.New(ctx, vmConfig, templatePath)
 				if err != nil {
-					return nil, err
+					return pool{}, err
 				}
 			}
 		} else {
-			b = direct.New(ctx, config.VMConfig)
+			b = direct.New(ctx, vmConfig)
 		}
 
-		if config.Cache > 0 {
-			b = cache.New(ctx, config.Cache, b)
+		if cached {
+			b = cache.New(ctx, cacheConfig, b)
 		}
 	}
 
-	return &factory{b}, nil
+	return pool{name: name, base: b, cached: cached}, nil
 }
 
 // SetLogger sets the logger for the factory.
@@ -136,10 +228,41 @@ func checkVMConfig(config1, config2 vc.VMConfig) error {
 	return nil
 }
 
-func (f *factory) checkConfig(config vc.VMConfig) error {
-	baseConfig := f.base.Config()
+// selectBase returns the base factory whose VMs are the closest to config:
+// the ones needing the fewest vCPUs, then the least memory, to be hot added.
+func (f *factory) selectBase(config vc.VMConfig) (base.FactoryBase, error) {
+	var selected base.FactoryBase
+	var selectedConfig vc.HypervisorConfig
+
+	// The base factory of the default VMs is the first candidate, its
+	// mismatch being the one reported when no pool is compatible.
+	err := checkVMConfig(f.base.Config(), config)
+	for _, p := range f.allPools() {
+		baseConfig := p.base.Config()
+		if checkVMConfig(baseConfig, config) != nil ||
+			baseConfig.HypervisorConfig.NumVCPUs > config.HypervisorConfig.NumVCPUs ||
+			baseConfig.HypervisorConfig.MemorySize > config.HypervisorConfig.MemorySize {
+			continue
+		}
+
+		if selected == nil ||
+			baseConfig.HypervisorConfig.NumVCPUs > selectedConfig.NumVCPUs ||
+			(baseConfig.HypervisorConfig.NumVCPUs == selectedConfig.NumVCPUs &&
+				baseConfig.HypervisorConfig.MemorySize > selectedConfig.MemorySize) {
+			selected = p.base
+			selectedConfig = baseConfig.HypervisorConfig
+		}
+	}
+
+	if selected == nil {
+		if err == nil {
+			err = fmt.Errorf("no factory pool with at most %d vCPUs and %dMiB of memory",
+				config.HypervisorConfig.NumVCPUs, config.HypervisorConfig.MemorySize)
+		}
+		return nil, err
+	}
 
-	return checkVMConfig(baseConfig, config)
+	return selected, nil
 }
 
 func (f *factory) validateNewVMConfig(config vc.VMConfig) error {
@@ -166,14 +289,14 @@ func (f *factory) GetVM(ctx context.Context, config vc.VMConfig) (*vc.VM, error)
 		return nil, err
 	}
 
-	err = f.checkConfig(config)
+	b, err := f.selectBase(config)
 	if err != nil {
 		f.log().WithError(err).Info("fallback to direct factory vm")
 		return direct.New(ctx, config).GetBaseVM(ctx, config)
 	}
 
 	f.log().Info("get base VM")
-	vm, err := f.base.GetBaseVM(ctx, config)
+	vm, err := b.GetBaseVM(ctx, config)
 	if err != nil {
 		f.log().WithError(err).Error("failed to get base VM")
 		return nil, err
@@ -205,7 +328,7 @@ func (f *factory) GetVM(ctx context.Context, config vc.VMConfig) (*vc.VM, error)
 	}
 
 	online := false
-	baseConfig := f.base.Config().HypervisorConfig
+	baseConfig := b.Config().HypervisorConfig
 	if baseConfig.NumVCPUs < hypervisorConfig.NumVCPUs {
 		err = vm.AddCPUs(hypervisorConfig.NumVCPUs - baseConfig.NumVCPUs)
 		if err != nil {
@@ -237,17 +360,64 @@ func (f *factory) Config() vc.VMConfig {
 	return f.base.Config()
 }
 
-// GetVMStatus returns the status of the paused VM created by the base factory.
+// allPools returns the pool of the VMs created from the factory VM config,
+// then the named pools.
+func (f *factory) allPools() []pool {
+	return append([]pool{f.pool}, f.pools...)
+}
+
+// GetVMStatus returns the status of the paused VMs created by the base
+// factories.
 func (f *factory) GetVMStatus() []*pb.GrpcVMStatus {
-	return f.base.GetVMStatus()
+	vs := f.base.GetVMStatus()
+	for _, p := range f.pools {
+		if p.cached {
+			vs = append(vs, p.base.GetVMStatus()...)
+		}
+	}
+
+	return vs
 }
 
-// GetBaseVM returns a paused VM created by the base factory.
+// GetCacheStats returns the stats of the VM caches of the base factories,
+// summed up.
+func (f *factory) GetCacheStats() *pb.GrpcCacheStats {
+	stats := &pb.GrpcCacheStats{}
+
+	for _, p := range f.allPools() {
+		if !p.cached {
+			continue
+		}
+
+		s := p.base.GetCacheStats()
+		stats.Hits += s.Hits
+		stats.Misses += s.Misses
+		stats.Evictions += s.Evictions
+		stats.Boots += s.Boots
+		stats.BootTime += s.BootTime
+		if s.MaxBootTime > stats.MaxBootTime {
+			stats.MaxBootTime = s.MaxBootTime
+		}
+		stats.Target += s.Target
+	}
+
+	return stats
+}
+
+// GetBaseVM returns a paused VM created by the base factory the closest to
+// config.
 func (f *factory) GetBaseVM(ctx context.Context, config vc.VMConfig) (*vc.VM, error) {
-	return f.base.GetBaseVM(ctx, config)
+	b, err := f.selectBase(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.GetBaseVM(ctx, config)
 }
 
 // CloseFactory closes the factory.
 func (f *factory) CloseFactory(ctx context.Context) {
-	f.base.CloseFactory(ctx)
+	for _, p := range f.allPools() {
+		p.base.CloseFactory(ctx)
+	}
 }
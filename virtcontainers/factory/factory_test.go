@@ -7,7 +7,9 @@ package factory
 
 import (
 	"context"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	vc "github.com/kata-containers/runtime/virtcontainers"
@@ -20,6 +22,21 @@ import (
 
 const testDisabledAsNonRoot = "Test disabled as requires root privileges"
 
+// testHypervisorConfig returns a hypervisor config whose assets are created
+// in dir. The template factory hashes the assets, the template path being
+// mounted over, dir must be outside of it.
+func testHypervisorConfig(assert *assert.Assertions, dir string) vc.HypervisorConfig {
+	config := vc.HypervisorConfig{
+		KernelPath: filepath.Join(dir, "kernel"),
+		ImagePath:  filepath.Join(dir, "image"),
+	}
+
+	assert.Nil(ioutil.WriteFile(config.KernelPath, []byte("kernel"), 0640))
+	assert.Nil(ioutil.WriteFile(config.ImagePath, []byte("image"), 0640))
+
+	return config
+}
+
 func TestNewFactory(t *testing.T) {
 	var config Config
 
@@ -41,10 +58,10 @@ func TestNewFactory(t *testing.T) {
 	assert.Error(err)
 
 	defer fs.MockStorageDestroy()
-	config.VMConfig.HypervisorConfig = vc.HypervisorConfig{
-		KernelPath: fs.MockStorageRootPath(),
-		ImagePath:  fs.MockStorageRootPath(),
-	}
+	assetDir, err := ioutil.TempDir("", "factory-assets")
+	assert.Nil(err)
+	defer os.RemoveAll(assetDir)
+	config.VMConfig.HypervisorConfig = testHypervisorConfig(assert, assetDir)
 
 	// direct
 	f, err := NewFactory(ctx, config, false)
@@ -180,10 +197,11 @@ func TestFactoryGetVM(t *testing.T) {
 	testDir := fs.MockStorageRootPath()
 	defer fs.MockStorageDestroy()
 
-	hyperConfig := vc.HypervisorConfig{
-		KernelPath: testDir,
-		ImagePath:  testDir,
-	}
+	assetDir, err := ioutil.TempDir("", "factory-assets")
+	assert.Nil(err)
+	defer os.RemoveAll(assetDir)
+
+	hyperConfig := testHypervisorConfig(assert, assetDir)
 	vmConfig := vc.VMConfig{
 		HypervisorType:   vc.MockHypervisor,
 		HypervisorConfig: hyperConfig,
@@ -191,7 +209,7 @@ func TestFactoryGetVM(t *testing.T) {
 		ProxyType:        vc.NoopProxyType,
 	}
 
-	err := vmConfig.Valid()
+	err = vmConfig.Valid()
 	assert.Nil(err)
 
 	ctx := context.Background()
@@ -288,6 +306,92 @@ func TestFactoryGetVM(t *testing.T) {
 	f.CloseFactory(ctx)
 }
 
+func TestFactoryPools(t *testing.T) {
+	assert := assert.New(t)
+
+	testDir := fs.MockStorageRootPath()
+	defer fs.MockStorageDestroy()
+
+	vmConfig := vc.VMConfig{
+		HypervisorType: vc.MockHypervisor,
+		HypervisorConfig: vc.HypervisorConfig{
+			KernelPath: testDir,
+			ImagePath:  testDir,
+			NumVCPUs:   1,
+			MemorySize: 128,
+		},
+		AgentType: vc.NoopAgentType,
+		ProxyType: vc.NoopProxyType,
+	}
+
+	poolConfig := func(vcpus, memory uint32) vc.VMConfig {
+		config := vmConfig
+		config.HypervisorConfig.NumVCPUs = vcpus
+		config.HypervisorConfig.MemorySize = memory
+		assert.NoError(config.Valid())
+		return config
+	}
+
+	ctx := context.Background()
+	config := Config{
+		VMConfig: vmConfig,
+		Pools: []PoolConfig{
+			{Name: "medium", VMConfig: poolConfig(2, 1024)},
+			{Name: "large", VMConfig: poolConfig(4, 4096)},
+			{Name: "wide", VMConfig: poolConfig(4, 256)},
+		},
+	}
+
+	vf, err := NewFactory(ctx, config, false)
+	assert.NoError(err)
+	defer vf.CloseFactory(ctx)
+
+	f, ok := vf.(*factory)
+	assert.True(ok)
+	assert.Len(f.pools, 3)
+
+	for _, d := range []struct {
+		vcpus      uint32
+		memory     uint32
+		poolVCPUs  uint32
+		poolMemory uint32
+	}{
+		{1, 128, 1, 128},
+		{2, 512, 1, 128},
+		{2, 2048, 2, 1024},
+		{4, 2048, 4, 256},
+		{8, 8192, 4, 4096},
+	} {
+		b, err := f.selectBase(poolConfig(d.vcpus, d.memory))
+		assert.NoError(err)
+		assert.Equal(d.poolVCPUs, b.Config().HypervisorConfig.NumVCPUs)
+		assert.Equal(d.poolMemory, b.Config().HypervisorConfig.MemorySize)
+	}
+
+	// no pool is small enough
+	_, err = f.selectBase(poolConfig(1, 64))
+	assert.Error(err)
+
+	// no pool is compatible
+	incompatible := poolConfig(1, 128)
+	incompatible.HypervisorConfig.Mlock = true
+	_, err = f.selectBase(incompatible)
+	assert.Error(err)
+
+	// invalid pools
+	config.Pools = append(config.Pools, PoolConfig{Name: "large", VMConfig: vmConfig})
+	_, err = NewFactory(ctx, config, false)
+	assert.Error(err)
+
+	config.Pools = []PoolConfig{{VMConfig: vmConfig}}
+	_, err = NewFactory(ctx, config, false)
+	assert.Error(err)
+
+	config.Pools = []PoolConfig{{Name: "invalid"}}
+	_, err = NewFactory(ctx, config, false)
+	assert.Error(err)
+}
+
 func TestDeepCompare(t *testing.T) {
 	assert := assert.New(t)
 
@@ -8,7 +8,6 @@ package grpccache
 
 import (
 	"context"
-	"fmt"
 
 	types "github.com/gogo/protobuf/types"
 	pb "github.com/kata-containers/runtime/protocols/cache"
@@ -16,22 +15,33 @@ import (
 	"github.com/kata-containers/runtime/virtcontainers/factory/base"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
+// PoolMetadataKey is the gRPC metadata key the VM cache pool a request is
+// for is given with, the VMs created from the server VM config being the
+// ones of the unnamed pool.
+const PoolMetadataKey = "kata-factory-pool"
+
 type grpccache struct {
 	conn   *grpc.ClientConn
 	config *vc.VMConfig
+	pool   string
 }
 
-// New returns a new direct vm factory.
-func New(ctx context.Context, endpoint string) (base.FactoryBase, error) {
-	conn, err := grpc.Dial(fmt.Sprintf("unix://%s", endpoint), grpc.WithInsecure())
+// New returns a new grpc vm factory, getting the VMs of pool from the VM
+// cache server.
+func New(ctx context.Context, endpoint string, tlsConfig TLSConfig, pool string) (base.FactoryBase, error) {
+	conn, err := Dial(endpoint, tlsConfig)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to connect %q", endpoint)
 	}
 
-	jConfig, err := pb.NewCacheServiceClient(conn).Config(ctx, &types.Empty{})
+	g := &grpccache{conn: conn, pool: pool}
+
+	jConfig, err := pb.NewCacheServiceClient(conn).Config(g.poolContext(ctx), &types.Empty{})
 	if err != nil {
+		conn.Close()
 		return nil, errors.Wrapf(err, "failed to Config")
 	}
 
@@ -40,7 +50,17 @@ func New(ctx context.Context, endpoint string) (base.FactoryBase, error) {
 		return nil, errors.Wrapf(err, "failed to convert JSON to VMConfig")
 	}
 
-	return &grpccache{conn: conn, config: config}, nil
+	g.config = config
+
+	return g, nil
+}
+
+func (g *grpccache) poolContext(ctx context.Context) context.Context {
+	if g.pool == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, PoolMetadataKey, g.pool)
 }
 
 // Config returns the direct factory's configuration.
@@ -51,18 +71,24 @@ func (g *grpccache) Config() vc.VMConfig {
 // GetBaseVM create a new VM directly.
 func (g *grpccache) GetBaseVM(ctx context.Context, config vc.VMConfig) (*vc.VM, error) {
 	defer g.conn.Close()
-	gVM, err := pb.NewCacheServiceClient(g.conn).GetBaseVM(ctx, &types.Empty{})
+	gVM, err := pb.NewCacheServiceClient(g.conn).GetBaseVM(g.poolContext(ctx), &types.Empty{})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to GetBaseVM")
 	}
 	return vc.NewVMFromGrpc(ctx, gVM, *g.config)
 }
 
-// CloseFactory closes the direct vm factory.
+// CloseFactory closes the grpc vm factory.
 func (g *grpccache) CloseFactory(ctx context.Context) {
+	g.conn.Close()
 }
 
 // GetVMStatus is not supported
 func (g *grpccache) GetVMStatus() []*pb.GrpcVMStatus {
 	panic("ERROR: package grpccache does not support GetVMStatus")
 }
+
+// GetCacheStats is not supported
+func (g *grpccache) GetCacheStats() *pb.GrpcCacheStats {
+	panic("ERROR: package grpccache does not support GetCacheStats")
+}
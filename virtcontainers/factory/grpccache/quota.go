@@ -0,0 +1,116 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package grpccache
+
+import (
+	"sync"
+	"time"
+)
+
+// Quota limits the VMs each client gets from a VM cache server. A client
+// gets up to limit VMs in a row, its quota being refilled with limit VMs
+// per interval. The quotas refilled up to limit are forgotten, once per
+// interval at most.
+type Quota struct {
+	limit    uint
+	interval time.Duration
+
+	lock    sync.Mutex
+	clients map[string]*quotaBucket
+	pruned  time.Time
+}
+
+type quotaBucket struct {
+	// vms is the number of VMs left to the client.
+	vms    float64
+	filled time.Time
+}
+
+// NewQuota returns a quota of limit VMs per interval and client, nil when
+// limit is 0, a nil quota not limiting the clients.
+func NewQuota(limit uint, interval time.Duration) *Quota {
+	if limit == 0 {
+		return nil
+	}
+
+	return &Quota{
+		limit:    limit,
+		interval: interval,
+		clients:  make(map[string]*quotaBucket),
+		pruned:   time.Now(),
+	}
+}
+
+// refill refills the quota of a client up to now.
+func (q *Quota) refill(b *quotaBucket, now time.Time) {
+	b.vms += float64(q.limit) * float64(now.Sub(b.filled)) / float64(q.interval)
+	if b.vms > float64(q.limit) {
+		b.vms = float64(q.limit)
+	}
+	b.filled = now
+}
+
+// prune forgets the full quotas, a client without a quota getting a full
+// one.
+func (q *Quota) prune(now time.Time) {
+	if now.Sub(q.pruned) < q.interval {
+		return
+	}
+	q.pruned = now
+
+	for client, b := range q.clients {
+		q.refill(b, now)
+		if b.vms >= float64(q.limit) {
+			delete(q.clients, client)
+		}
+	}
+}
+
+// Take takes a VM from the quota of client, it returns false when the
+// client exceeded its quota.
+func (q *Quota) Take(client string) bool {
+	if q == nil {
+		return true
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	now := time.Now()
+	q.prune(now)
+
+	b, ok := q.clients[client]
+	if !ok {
+		b = &quotaBucket{vms: float64(q.limit), filled: now}
+		q.clients[client] = b
+	}
+
+	q.refill(b, now)
+
+	if b.vms < 1 {
+		return false
+	}
+
+	b.vms--
+	return true
+}
+
+// Refund gives back to the quota of client a VM it took but did not get.
+func (q *Quota) Refund(client string) {
+	if q == nil {
+		return
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if b, ok := q.clients[client]; ok {
+		b.vms++
+		if b.vms > float64(q.limit) {
+			b.vms = float64(q.limit)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package grpccache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuota(t *testing.T) {
+	assert := assert.New(t)
+
+	var q *Quota
+	assert.Nil(NewQuota(0, time.Minute))
+	assert.True(q.Take("foo"))
+
+	q = NewQuota(2, 100*time.Millisecond)
+	assert.True(q.Take("foo"))
+	assert.True(q.Take("foo"))
+	assert.False(q.Take("foo"))
+
+	// the clients have their own quota
+	assert.True(q.Take("bar"))
+
+	// one VM every 50ms
+	time.Sleep(60 * time.Millisecond)
+	assert.True(q.Take("foo"))
+	assert.False(q.Take("foo"))
+
+	// the quota does not grow above its limit
+	time.Sleep(300 * time.Millisecond)
+	assert.True(q.Take("foo"))
+	assert.True(q.Take("foo"))
+	assert.False(q.Take("foo"))
+
+	// the VMs the client did not get are given back
+	q.Refund("foo")
+	assert.True(q.Take("foo"))
+	assert.False(q.Take("foo"))
+
+	q.Refund("bar")
+	q.Refund("bar")
+	assert.True(q.Take("bar"))
+	assert.True(q.Take("bar"))
+	assert.False(q.Take("bar"))
+}
+
+func TestQuotaPrune(t *testing.T) {
+	assert := assert.New(t)
+
+	q := NewQuota(2, 100*time.Millisecond)
+	assert.True(q.Take("foo"))
+	assert.True(q.Take("bar"))
+	assert.True(q.Take("bar"))
+	assert.Len(q.clients, 2)
+
+	// the quotas are not pruned more than once per interval
+	q.prune(time.Now())
+	assert.Len(q.clients, 2)
+
+	// the full quotas are forgotten
+	now := time.Now().Add(60 * time.Millisecond)
+	q.pruned = now.Add(-100 * time.Millisecond)
+	q.prune(now)
+	assert.Len(q.clients, 1)
+	assert.Contains(q.clients, "bar")
+
+	now = now.Add(100 * time.Millisecond)
+	q.pruned = now.Add(-100 * time.Millisecond)
+	q.prune(now)
+	assert.Empty(q.clients)
+}
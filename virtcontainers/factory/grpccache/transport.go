@@ -0,0 +1,303 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package grpccache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kata-containers/runtime/virtcontainers/pkg/mtls"
+	"github.com/mdlayher/vsock"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	unixScheme  = "unix"
+	tcpScheme   = "tcp"
+	vsockScheme = "vsock"
+)
+
+// TLSConfig is the mutual TLS configuration of the VM cache server and
+// clients, their certificates being signed by the same CA.
+type TLSConfig = mtls.Config
+
+// endpoint is a VM cache server endpoint.
+type endpoint struct {
+	scheme string
+
+	// path is the unix socket path, address the TCP address.
+	path    string
+	address string
+
+	// cid and port are the vsock address.
+	cid  uint32
+	port uint32
+}
+
+// parseEndpoint parses a VM cache endpoint, a unix socket path with an
+// optional unix:// scheme, tcp://host:port or vsock://cid:port.
+func parseEndpoint(e string) (endpoint, error) {
+	scheme := unixScheme
+	address := e
+	if i := strings.Index(e, "://"); i >= 0 {
+		scheme = e[:i]
+		address = e[i+len("://"):]
+	}
+
+	switch scheme {
+	case unixScheme:
+		if !filepath.IsAbs(address) {
+			return endpoint{}, fmt.Errorf("VM cache endpoint %s is not an absolute path", e)
+		}
+		return endpoint{scheme: scheme, path: address}, nil
+	case tcpScheme:
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return endpoint{}, fmt.Errorf("Invalid VM cache endpoint %s: %v", e, err)
+		}
+		return endpoint{scheme: scheme, address: address}, nil
+	case vsockScheme:
+		fields := strings.Split(address, ":")
+		if len(fields) != 2 {
+			return endpoint{}, fmt.Errorf("Invalid VM cache endpoint %s, expecting vsock://cid:port", e)
+		}
+
+		cid, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return endpoint{}, fmt.Errorf("Invalid VM cache endpoint %s: %v", e, err)
+		}
+
+		port, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return endpoint{}, fmt.Errorf("Invalid VM cache endpoint %s: %v", e, err)
+		}
+
+		return endpoint{scheme: scheme, cid: uint32(cid), port: uint32(port)}, nil
+	}
+
+	return endpoint{}, fmt.Errorf("Unknown VM cache endpoint scheme %s", scheme)
+}
+
+// CheckEndpoint checks the VM cache endpoint and TLS config. The TCP and
+// vsock endpoints are only served with mutual TLS.
+func CheckEndpoint(e string, tlsConfig TLSConfig) error {
+	ep, err := parseEndpoint(e)
+	if err != nil {
+		return err
+	}
+
+	if ep.scheme != unixScheme && !tlsConfig.Enabled() {
+		return fmt.Errorf("VM cache endpoint %s requires TLS", e)
+	}
+
+	if tlsConfig.Enabled() {
+		return tlsConfig.Check()
+	}
+
+	return nil
+}
+
+// Listen returns the listener of the VM cache server serving endpoint e,
+// along with its gRPC server options.
+func Listen(e string, tlsConfig TLSConfig) (net.Listener, []grpc.ServerOption, error) {
+	if err := CheckEndpoint(e, tlsConfig); err != nil {
+		return nil, nil, err
+	}
+
+	var opts []grpc.ServerOption
+	if tlsConfig.Enabled() {
+		config, err := tlsConfig.ServerConfig()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+	}
+
+	ep, _ := parseEndpoint(e)
+	switch ep.scheme {
+	case tcpScheme:
+		l, err := net.Listen("tcp", ep.address)
+		return l, opts, err
+	case vsockScheme:
+		l, err := vsock.Listen(ep.port)
+		return l, opts, err
+	}
+
+	l, err := listenUnix(ep.path)
+	return l, opts, err
+}
+
+func listenUnix(path string) (net.Listener, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+	_, err = os.Stat(path)
+	if err == nil {
+		return nil, fmt.Errorf("%s already exist.  Please stop running VMCache server and remove %s", path, path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return &unixListener{l}, nil
+}
+
+// unixListener is a unix socket listener whose connections are identified
+// by the user id of the peer.
+type unixListener struct {
+	*net.UnixListener
+}
+
+func (l *unixListener) Accept() (net.Conn, error) {
+	conn, err := l.AcceptUnix()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	var cred *unix.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err == nil {
+		err = credErr
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &unixConn{conn, unixPeerAddr{uid: cred.Uid}}, nil
+}
+
+type unixConn struct {
+	*net.UnixConn
+	peer unixPeerAddr
+}
+
+func (c *unixConn) RemoteAddr() net.Addr {
+	return c.peer
+}
+
+// unixPeerAddr is the user id of the peer of a unix socket connection.
+type unixPeerAddr struct {
+	uid uint32
+}
+
+func (a unixPeerAddr) Network() string {
+	return unixScheme
+}
+
+func (a unixPeerAddr) String() string {
+	return fmt.Sprintf("uid:%d", a.uid)
+}
+
+// ClientID returns the identity of the client of a VM cache server request:
+// the common name of its certificate with mutual TLS, its user id over a
+// unix socket.
+func ClientID(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+		return "cn:" + info.State.PeerCertificates[0].Subject.CommonName
+	}
+
+	return p.Addr.String()
+}
+
+// QuotaKey returns the key of the quota of the client of a VM cache server
+// request: its user id over a unix socket, its identity along with the
+// address of its host otherwise. The clients sharing an identity, e.g. the
+// runtimes of a host, get their own quota, the runtimes of a user on the
+// host of the server share theirs.
+func QuotaKey(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	switch addr := p.Addr.(type) {
+	case unixPeerAddr:
+		return fmt.Sprintf("uid:%d", addr.uid)
+	case *net.TCPAddr:
+		return fmt.Sprintf("%s@%s", ClientID(ctx), addr.IP)
+	case *vsock.Addr:
+		return fmt.Sprintf("%s@cid:%d", ClientID(ctx), addr.ContextID)
+	}
+
+	return ClientID(ctx)
+}
+
+// LocalClient tells whether the client of a VM cache server request is
+// connected over the unix socket of the server, only reachable from the
+// host.
+func LocalClient(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	_, ok = p.Addr.(unixPeerAddr)
+	return ok
+}
+
+// Dial connects to the VM cache server serving endpoint e.
+func Dial(e string, tlsConfig TLSConfig) (*grpc.ClientConn, error) {
+	if err := CheckEndpoint(e, tlsConfig); err != nil {
+		return nil, err
+	}
+
+	ep, _ := parseEndpoint(e)
+
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if tlsConfig.Enabled() {
+		// There is no host name to verify the server certificate with
+		// but over TCP, only its chain is.
+		config, err := tlsConfig.ClientConfig(ep.scheme == tcpScheme)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(config))}
+	}
+
+	switch ep.scheme {
+	case tcpScheme:
+		return grpc.Dial(ep.address, opts...)
+	case vsockScheme:
+		opts = append(opts, grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+			return vsock.Dial(ep.cid, ep.port)
+		}))
+		return grpc.Dial(e, opts...)
+	}
+
+	return grpc.Dial(fmt.Sprintf("unix://%s", ep.path), opts...)
+}
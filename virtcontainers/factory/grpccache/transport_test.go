@@ -0,0 +1,214 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package grpccache
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	types "github.com/gogo/protobuf/types"
+	pb "github.com/kata-containers/runtime/protocols/cache"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	for e, expected := range map[string]endpoint{
+		"/run/cache.sock":        {scheme: unixScheme, path: "/run/cache.sock"},
+		"unix:///run/cache.sock": {scheme: unixScheme, path: "/run/cache.sock"},
+		"tcp://127.0.0.1:7000":   {scheme: tcpScheme, address: "127.0.0.1:7000"},
+		"tcp://[::1]:7000":       {scheme: tcpScheme, address: "[::1]:7000"},
+		"vsock://2:1024":         {scheme: vsockScheme, cid: 2, port: 1024},
+	} {
+		ep, err := parseEndpoint(e)
+		assert.NoError(err, e)
+		assert.Equal(expected, ep, e)
+	}
+
+	for _, e := range []string{
+		"cache.sock",
+		"unix://cache.sock",
+		"tcp://127.0.0.1",
+		"vsock://2",
+		"vsock://foo:1024",
+		"vsock://2:foo",
+		"http://127.0.0.1:7000",
+	} {
+		_, err := parseEndpoint(e)
+		assert.Error(err, e)
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	tlsConfig := TLSConfig{CertFile: "/cert.pem", KeyFile: "/key.pem", CAFile: "/ca.pem"}
+
+	assert.NoError(CheckEndpoint("/run/cache.sock", TLSConfig{}))
+	assert.NoError(CheckEndpoint("/run/cache.sock", tlsConfig))
+	assert.NoError(CheckEndpoint("tcp://127.0.0.1:7000", tlsConfig))
+	assert.NoError(CheckEndpoint("vsock://2:1024", tlsConfig))
+
+	assert.Error(CheckEndpoint("tcp://127.0.0.1:7000", TLSConfig{}))
+	assert.Error(CheckEndpoint("vsock://2:1024", TLSConfig{}))
+	assert.Error(CheckEndpoint("tcp://127.0.0.1:7000", TLSConfig{CertFile: "/cert.pem", KeyFile: "/key.pem"}))
+	assert.Error(CheckEndpoint("cache.sock", TLSConfig{}))
+}
+
+// testServer is a VM cache server returning the client identity, quota key
+// and locality as the VM config data.
+type testServer struct{}
+
+func (s *testServer) Config(ctx context.Context, empty *types.Empty) (*pb.GrpcVMConfig, error) {
+	return &pb.GrpcVMConfig{Data: []byte(fmt.Sprintf("%s %s %t", ClientID(ctx), QuotaKey(ctx), LocalClient(ctx)))}, nil
+}
+
+func (s *testServer) GetBaseVM(ctx context.Context, empty *types.Empty) (*pb.GrpcVM, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (s *testServer) Status(ctx context.Context, empty *types.Empty) (*pb.GrpcStatus, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (s *testServer) Quit(ctx context.Context, empty *types.Empty) (*types.Empty, error) {
+	return &types.Empty{}, nil
+}
+
+// testClientID serves endpoint and returns the identity, the quota key and
+// the locality the server gets for the client dialing it.
+func testClientID(assert *assert.Assertions, endpoint string, serverTLS, clientTLS TLSConfig) (string, error) {
+	l, opts, err := Listen(endpoint, serverTLS)
+	assert.NoError(err)
+	defer l.Close()
+
+	s := grpc.NewServer(opts...)
+	pb.RegisterCacheServiceServer(s, &testServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	if tcp, ok := l.Addr().(*net.TCPAddr); ok {
+		endpoint = fmt.Sprintf("tcp://%s", tcp)
+	}
+
+	conn, err := Dial(endpoint, clientTLS)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	config, err := pb.NewCacheServiceClient(conn).Config(ctx, &types.Empty{}, grpc.FailFast(true))
+	if err != nil {
+		return "", err
+	}
+
+	return string(config.Data), nil
+}
+
+func TestUnixEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "grpccache")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	endpoint := filepath.Join(dir, "cache.sock")
+	id, err := testClientID(assert, endpoint, TLSConfig{}, TLSConfig{})
+	assert.NoError(err)
+	assert.Equal(fmt.Sprintf("uid:%d uid:%d true", os.Getuid(), os.Getuid()), id)
+
+	// the socket is removed with the listener
+	_, err = os.Stat(endpoint)
+	assert.True(os.IsNotExist(err))
+}
+
+// testCertificate writes to dir a certificate for cn signed by the CA, or
+// self-signed without CA, and its key. It returns their paths along with
+// the certificate and the key.
+func testCertificate(assert *assert.Assertions, dir, cn string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (string, string, *x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		ca = template
+		caKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	assert.NoError(err)
+
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(err)
+
+	certPath := filepath.Join(dir, cn+".pem")
+	keyPath := filepath.Join(dir, cn+"-key.pem")
+	assert.NoError(ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	return certPath, keyPath, cert, key
+}
+
+func TestTCPEndpointMutualTLS(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "grpccache")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	caPath, _, ca, caKey := testCertificate(assert, dir, "ca", nil, nil)
+	serverCert, serverKey, _, _ := testCertificate(assert, dir, "server", ca, caKey)
+	clientCert, clientKey, _, _ := testCertificate(assert, dir, "client", ca, caKey)
+	otherCAPath, _, otherCA, otherCAKey := testCertificate(assert, dir, "other-ca", nil, nil)
+	otherCert, otherKey, _, _ := testCertificate(assert, dir, "other", otherCA, otherCAKey)
+
+	serverTLS := TLSConfig{CertFile: serverCert, KeyFile: serverKey, CAFile: caPath}
+	clientTLS := TLSConfig{CertFile: clientCert, KeyFile: clientKey, CAFile: caPath}
+
+	id, err := testClientID(assert, "tcp://127.0.0.1:0", serverTLS, clientTLS)
+	assert.NoError(err)
+	assert.Equal("cn:client cn:client@127.0.0.1 false", id)
+
+	// client certificate signed by another CA
+	_, err = testClientID(assert, "tcp://127.0.0.1:0", serverTLS, TLSConfig{CertFile: otherCert, KeyFile: otherKey, CAFile: caPath})
+	assert.Error(err)
+
+	// server certificate signed by another CA
+	_, err = testClientID(assert, "tcp://127.0.0.1:0", serverTLS, TLSConfig{CertFile: clientCert, KeyFile: clientKey, CAFile: otherCAPath})
+	assert.Error(err)
+}
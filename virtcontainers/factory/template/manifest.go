@@ -0,0 +1,247 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/annotations"
+	"github.com/kata-containers/runtime/virtcontainers/types"
+)
+
+// templateManifestVersion is the version of the template manifest. It is
+// bumped when the templates created by a previous version can't be used.
+const templateManifestVersion = 2
+
+// manifest records what a VM template was created from, in order to
+// detect the templates made stale by an upgrade or a configuration change.
+type manifest struct {
+	Version int `json:"version"`
+
+	// Assets are the template VM assets by asset type.
+	Assets map[types.AssetType]manifestAsset `json:"assets"`
+
+	// HypervisorVersion is the version the hypervisor reported when the
+	// template was created, if any.
+	HypervisorVersion string `json:"hypervisor_version,omitempty"`
+
+	// VMConfig is the JSON encoded config of the template VM.
+	VMConfig json.RawMessage `json:"vm_config"`
+}
+
+// manifestAsset is an asset of a VM template. The hypervisor binary being
+// an asset too, its hash stands for the hypervisor version.
+type manifestAsset struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	// Hash is the SHA512 hash of the asset.
+	Hash string `json:"hash"`
+}
+
+// newManifest returns the manifest of a VM template created from config.
+func newManifest(config vc.VMConfig) (*manifest, error) {
+	paths, err := assetPaths(config.HypervisorConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := make(map[types.AssetType]manifestAsset)
+	for t, path := range paths {
+		if assets[t], err = newManifestAsset(t, path); err != nil {
+			return nil, err
+		}
+	}
+
+	vmConfig, err := manifestVMConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest{
+		Version:           templateManifestVersion,
+		Assets:            assets,
+		HypervisorVersion: hypervisorVersion(paths[types.HypervisorAsset]),
+		VMConfig:          vmConfig,
+	}, nil
+}
+
+// manifestVMConfig returns the JSON encoded config of a VM template created
+// from config.
+func manifestVMConfig(config vc.VMConfig) (json.RawMessage, error) {
+	// The proxy config is set for each VM created from the template.
+	config.ProxyType = ""
+	config.ProxyConfig = vc.ProxyConfig{}
+
+	return json.Marshal(config)
+}
+
+// assetPaths returns the paths of the assets of config by asset type.
+func assetPaths(config vc.HypervisorConfig) (map[types.AssetType]string, error) {
+	assetPaths := map[types.AssetType]func() (string, error){
+		types.KernelAsset:     config.KernelAssetPath,
+		types.ImageAsset:      config.ImageAssetPath,
+		types.InitrdAsset:     config.InitrdAssetPath,
+		types.FirmwareAsset:   config.FirmwareAssetPath,
+		types.HypervisorAsset: config.HypervisorAssetPath,
+	}
+
+	paths := make(map[types.AssetType]string)
+	for t, assetPath := range assetPaths {
+		path, err := assetPath()
+		if err != nil {
+			return nil, err
+		}
+
+		if path != "" {
+			paths[t] = path
+		}
+	}
+
+	return paths, nil
+}
+
+// newManifestAsset hashes the asset of type t at path.
+func newManifestAsset(t types.AssetType, path string) (manifestAsset, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return manifestAsset{}, err
+	}
+
+	pathAnnotation, _, err := t.Annotations()
+	if err != nil {
+		return manifestAsset{}, err
+	}
+
+	a, err := types.NewAsset(map[string]string{pathAnnotation: path}, t)
+	if err != nil {
+		return manifestAsset{}, err
+	}
+
+	hash, err := a.Hash(annotations.SHA512)
+	if err != nil {
+		return manifestAsset{}, err
+	}
+
+	return manifestAsset{
+		Path:    path,
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+		Hash:    hash,
+	}, nil
+}
+
+// unchanged tells whether the file at path is the asset, as long as it was
+// not modified. It saves hashing the assets each time a template is used.
+func (a manifestAsset) unchanged(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return a.Path == path && a.Size == fi.Size() && a.ModTime.Equal(fi.ModTime())
+}
+
+// hypervisorVersion returns the first line of the hypervisor version
+// output, or an empty string when it can't be run.
+func hypervisorVersion(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		templateLog.WithError(err).WithField("hypervisor", path).Debug("failed to get hypervisor version")
+		return ""
+	}
+
+	return strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+}
+
+func readManifest(path string) (*manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (m *manifest) write(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// check returns an error when the VM template the manifest describes can't
+// be used to create VMs from config. The assets are only hashed when they
+// were modified since the template was created.
+func (m *manifest) check(config vc.VMConfig) error {
+	if m.Version != templateManifestVersion {
+		return fmt.Errorf("VM template manifest version %d, expected %d", m.Version, templateManifestVersion)
+	}
+
+	paths, err := assetPaths(config.HypervisorConfig)
+	if err != nil {
+		return err
+	}
+
+	for t, path := range paths {
+		asset, ok := m.Assets[t]
+		if !ok {
+			return fmt.Errorf("VM template %s asset added", t)
+		}
+
+		if asset.unchanged(path) {
+			continue
+		}
+
+		current, err := newManifestAsset(t, path)
+		if err != nil {
+			return err
+		}
+
+		if current.Hash != asset.Hash {
+			if t == types.HypervisorAsset && m.HypervisorVersion != "" {
+				return fmt.Errorf("VM template %s asset changed, the template was created with %s", t, m.HypervisorVersion)
+			}
+			return fmt.Errorf("VM template %s asset changed", t)
+		}
+	}
+
+	for t := range m.Assets {
+		if _, ok := paths[t]; !ok {
+			return fmt.Errorf("VM template %s asset removed", t)
+		}
+	}
+
+	vmConfig, err := manifestVMConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(m.VMConfig, vmConfig) {
+		return fmt.Errorf("VM template config changed")
+	}
+
+	return nil
+}
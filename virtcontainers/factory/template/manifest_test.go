@@ -0,0 +1,132 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package template
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/types"
+)
+
+func TestTemplateManifest(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "template-manifest")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	config := vc.VMConfig{
+		HypervisorType: vc.QemuHypervisor,
+		HypervisorConfig: vc.HypervisorConfig{
+			KernelPath:     filepath.Join(dir, "kernel"),
+			InitrdPath:     filepath.Join(dir, "initrd"),
+			HypervisorPath: filepath.Join(dir, "qemu"),
+		},
+		ProxyType: vc.KataBuiltInProxyType,
+	}
+
+	// missing assets
+	_, err = newManifest(config)
+	assert.Error(err)
+
+	for _, path := range []string{config.HypervisorConfig.KernelPath, config.HypervisorConfig.InitrdPath, config.HypervisorConfig.HypervisorPath} {
+		assert.NoError(ioutil.WriteFile(path, []byte(filepath.Base(path)), 0640))
+	}
+
+	m, err := newManifest(config)
+	assert.NoError(err)
+	assert.Equal(templateManifestVersion, m.Version)
+	assert.Len(m.Assets, 3)
+	assert.Contains(m.Assets, types.HypervisorAsset)
+
+	manifestPath := filepath.Join(dir, "manifest.json")
+	assert.NoError(m.write(manifestPath))
+
+	m, err = readManifest(manifestPath)
+	assert.NoError(err)
+	assert.NoError(m.check(config))
+
+	// the proxy is set for each VM
+	c := config
+	c.ProxyType = vc.NoopProxyType
+	assert.NoError(m.check(c))
+
+	c = config
+	c.HypervisorConfig.NumVCPUs = 2
+	assert.Error(m.check(c))
+
+	c = config
+	c.HypervisorConfig.InitrdPath = ""
+	assert.Error(m.check(c))
+
+	assert.NoError(ioutil.WriteFile(config.HypervisorConfig.HypervisorPath, []byte("upgraded qemu"), 0640))
+	assert.Error(m.check(config))
+
+	m, err = newManifest(config)
+	assert.NoError(err)
+	assert.NoError(m.check(config))
+
+	m.Version++
+	assert.Error(m.check(config))
+
+	_, err = readManifest(filepath.Join(dir, "foo"))
+	assert.Error(err)
+}
+
+func TestTemplateManifestUnchangedAssets(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "template-manifest")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	config := vc.VMConfig{
+		HypervisorType: vc.QemuHypervisor,
+		HypervisorConfig: vc.HypervisorConfig{
+			KernelPath:     filepath.Join(dir, "kernel"),
+			ImagePath:      filepath.Join(dir, "image"),
+			HypervisorPath: filepath.Join(dir, "qemu"),
+		},
+	}
+
+	assert.NoError(ioutil.WriteFile(config.HypervisorConfig.KernelPath, []byte("kernel"), 0640))
+	assert.NoError(ioutil.WriteFile(config.HypervisorConfig.ImagePath, []byte("image"), 0640))
+	assert.NoError(ioutil.WriteFile(config.HypervisorConfig.HypervisorPath, []byte("#!/bin/sh\necho 'QEMU emulator version 5.0.0'\necho 'Copyright'\n"), 0750))
+
+	m, err := newManifest(config)
+	assert.NoError(err)
+	assert.Equal("QEMU emulator version 5.0.0", m.HypervisorVersion)
+
+	// the modified assets are hashed again
+	fi, err := os.Stat(config.HypervisorConfig.KernelPath)
+	assert.NoError(err)
+	mtime := fi.ModTime().Add(time.Second)
+	assert.NoError(os.Chtimes(config.HypervisorConfig.KernelPath, mtime, mtime))
+	assert.NoError(m.check(config))
+
+	assert.NoError(ioutil.WriteFile(config.HypervisorConfig.KernelPath, []byte("KERNEL"), 0640))
+	assert.Error(m.check(config))
+
+	// the unmodified ones are not
+	m, err = newManifest(config)
+	assert.NoError(err)
+	kernel := m.Assets[types.KernelAsset]
+	kernel.Hash = "foo"
+	m.Assets[types.KernelAsset] = kernel
+	assert.NoError(m.check(config))
+
+	assert.NoError(ioutil.WriteFile(config.HypervisorConfig.HypervisorPath, []byte("#!/bin/sh\necho 'QEMU emulator version 5.1.0'\n"), 0750))
+	err = m.check(config)
+	assert.Error(err)
+	assert.Contains(err.Error(), "QEMU emulator version 5.0.0")
+}
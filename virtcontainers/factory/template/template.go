@@ -27,8 +27,8 @@ type template struct {
 var templateWaitForAgent = 2 * time.Second
 var templateLog = logrus.WithField("source", "virtcontainers/factory")
 
-// Fetch finds and returns a pre-built template factory.
-// TODO: save template metadata and fetch from storage.
+// Fetch finds and returns a pre-built template factory, created from the
+// same assets and config.
 func Fetch(config vc.VMConfig, templatePath string) (base.FactoryBase, error) {
 	t := &template{templatePath, config}
 
@@ -44,9 +44,15 @@ func Fetch(config vc.VMConfig, templatePath string) (base.FactoryBase, error) {
 func New(ctx context.Context, config vc.VMConfig, templatePath string) (base.FactoryBase, error) {
 	t := &template{templatePath, config}
 
-	err := t.checkTemplateVM()
+	err := t.checkTemplateFiles()
 	if err == nil {
-		return nil, fmt.Errorf("There is already a VM template in %s", templatePath)
+		if err = t.checkManifest(); err == nil {
+			return nil, fmt.Errorf("There is already a VM template in %s", templatePath)
+		}
+
+		// Booting VMs from a stale template would make them crash.
+		t.Logger().WithError(err).Warn("replacing stale VM template")
+		t.close()
 	}
 
 	err = t.prepareTemplateFiles()
@@ -87,6 +93,11 @@ func (t *template) GetVMStatus() []*pb.GrpcVMStatus {
 	panic("ERROR: package template does not support GetVMStatus")
 }
 
+// GetCacheStats is not supported
+func (t *template) GetCacheStats() *pb.GrpcCacheStats {
+	panic("ERROR: package template does not support GetCacheStats")
+}
+
 func (t *template) close() {
 	if err := syscall.Unmount(t.statePath, syscall.MNT_DETACH); err != nil {
 		t.Logger().WithError(err).Errorf("failed to unmount %s", t.statePath)
@@ -98,7 +109,8 @@ func (t *template) close() {
 }
 
 func (t *template) prepareTemplateFiles() error {
-	// create and mount tmpfs for the shared memory file
+	// create and mount tmpfs for the shared memory file and the devices
+	// state, or the snapshot of hypervisors templating VMs from snapshots
 	err := os.MkdirAll(t.statePath, 0700)
 	if err != nil {
 		return err
@@ -121,11 +133,9 @@ func (t *template) prepareTemplateFiles() error {
 
 func (t *template) createTemplateVM(ctx context.Context) error {
 	// create the template vm
-	config := t.config
+	config := t.templateConfig()
 	config.HypervisorConfig.BootToBeTemplate = true
 	config.HypervisorConfig.BootFromTemplate = false
-	config.HypervisorConfig.MemoryPath = t.statePath + "/memory"
-	config.HypervisorConfig.DevicesStatePath = t.statePath + "/state"
 
 	vm, err := vc.NewVM(ctx, config)
 	if err != nil {
@@ -154,15 +164,18 @@ func (t *template) createTemplateVM(ctx context.Context) error {
 		return err
 	}
 
-	return nil
+	m, err := newManifest(t.config)
+	if err != nil {
+		return err
+	}
+
+	return m.write(t.statePath + "/manifest.json")
 }
 
 func (t *template) createFromTemplateVM(ctx context.Context, c vc.VMConfig) (*vc.VM, error) {
-	config := t.config
+	config := t.templateConfig()
 	config.HypervisorConfig.BootToBeTemplate = false
 	config.HypervisorConfig.BootFromTemplate = true
-	config.HypervisorConfig.MemoryPath = t.statePath + "/memory"
-	config.HypervisorConfig.DevicesStatePath = t.statePath + "/state"
 	config.ProxyType = c.ProxyType
 	config.ProxyConfig = c.ProxyConfig
 
@@ -170,13 +183,50 @@ func (t *template) createFromTemplateVM(ctx context.Context, c vc.VMConfig) (*vc
 }
 
 func (t *template) checkTemplateVM() error {
-	_, err := os.Stat(t.statePath + "/memory")
+	if err := t.checkTemplateFiles(); err != nil {
+		return err
+	}
+
+	return t.checkManifest()
+}
+
+// templateConfig returns the factory config with the paths of the template
+// files set.
+func (t *template) templateConfig() vc.VMConfig {
+	config := t.config
+	config.HypervisorConfig.MemoryPath = t.statePath + "/memory"
+	config.HypervisorConfig.DevicesStatePath = t.statePath + "/state"
+
+	return config
+}
+
+// checkTemplateFiles checks the files the hypervisor saves the template VM
+// to exist.
+func (t *template) checkTemplateFiles() error {
+	config := t.templateConfig()
+	files, err := config.TemplateFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkManifest checks the template was created from the assets and the
+// config of the factory.
+func (t *template) checkManifest() error {
+	m, err := readManifest(t.statePath + "/manifest.json")
 	if err != nil {
 		return err
 	}
 
-	_, err = os.Stat(t.statePath + "/state")
-	return err
+	return m.check(t.config)
 }
 
 // Logger returns a logrus logger appropriate for logging template messages
@@ -7,7 +7,10 @@ package template
 
 import (
 	"context"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,10 +34,16 @@ func TestTemplateFactory(t *testing.T) {
 	testDir := fs.MockStorageRootPath()
 	defer fs.MockStorageDestroy()
 
+	assetDir, err := ioutil.TempDir("", "template-assets")
+	assert.Nil(err)
+	defer os.RemoveAll(assetDir)
+
 	hyperConfig := vc.HypervisorConfig{
-		KernelPath: testDir,
-		ImagePath:  testDir,
+		KernelPath: filepath.Join(assetDir, "kernel"),
+		ImagePath:  filepath.Join(assetDir, "image"),
 	}
+	assert.Nil(ioutil.WriteFile(hyperConfig.KernelPath, []byte("kernel"), 0640))
+	assert.Nil(ioutil.WriteFile(hyperConfig.ImagePath, []byte("image"), 0640))
 	vmConfig := vc.VMConfig{
 		HypervisorType:   vc.MockHypervisor,
 		HypervisorConfig: hyperConfig,
@@ -42,7 +51,7 @@ func TestTemplateFactory(t *testing.T) {
 		ProxyType:        vc.NoopProxyType,
 	}
 
-	err := vmConfig.Valid()
+	err = vmConfig.Valid()
 	assert.Nil(err)
 
 	ctx := context.Background()
@@ -112,6 +121,25 @@ func TestTemplateFactory(t *testing.T) {
 	err = vm.Stop()
 	assert.Nil(err)
 
+	// stale template
+	assert.Nil(ioutil.WriteFile(hyperConfig.KernelPath, []byte("new kernel"), 0640))
+	err = tt.checkTemplateVM()
+	assert.Error(err)
+
+	_, err = Fetch(vmConfig, testDir)
+	assert.Error(err)
+
+	f, err = New(ctx, vmConfig, testDir)
+	assert.Nil(err)
+
+	_, err = os.Create(tt.statePath + "/state")
+	assert.Nil(err)
+	err = tt.checkTemplateVM()
+	assert.Nil(err)
+
+	_, err = New(ctx, vmConfig, testDir)
+	assert.Error(err)
+
 	// make tt.statePath is busy
 	os.Chdir(tt.statePath)
 
@@ -123,3 +151,54 @@ func TestTemplateFactory(t *testing.T) {
 	assert.Error(err)
 	assert.True(os.IsNotExist(err))
 }
+
+func TestFetchTemplateFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	assetDir, err := ioutil.TempDir("", "template-assets")
+	assert.Nil(err)
+	defer os.RemoveAll(assetDir)
+
+	hyperConfig := vc.HypervisorConfig{
+		KernelPath: filepath.Join(assetDir, "kernel"),
+		ImagePath:  filepath.Join(assetDir, "image"),
+	}
+	assert.Nil(ioutil.WriteFile(hyperConfig.KernelPath, []byte("kernel"), 0640))
+	assert.Nil(ioutil.WriteFile(hyperConfig.ImagePath, []byte("image"), 0640))
+
+	// the files the template VM is saved to by each hypervisor
+	for hType, files := range map[vc.HypervisorType][]string{
+		vc.QemuHypervisor:        {"memory", "state"},
+		vc.ClhHypervisor:         {"state/", "state/template-id"},
+		vc.FirecrackerHypervisor: {"state", "state.mem"},
+	} {
+		testDir, err := ioutil.TempDir("", "template")
+		assert.Nil(err)
+		defer os.RemoveAll(testDir)
+
+		vmConfig := vc.VMConfig{
+			HypervisorType:   hType,
+			HypervisorConfig: hyperConfig,
+			AgentType:        vc.NoopAgentType,
+		}
+
+		m, err := newManifest(vmConfig)
+		assert.Nil(err)
+		assert.Nil(m.write(filepath.Join(testDir, "manifest.json")))
+
+		for _, f := range files {
+			_, err = Fetch(vmConfig, testDir)
+			assert.Error(err, "%s template without %s", hType, f)
+
+			path := filepath.Join(testDir, f)
+			if strings.HasSuffix(f, "/") {
+				assert.Nil(os.Mkdir(path, 0700))
+			} else {
+				assert.Nil(ioutil.WriteFile(path, nil, 0600))
+			}
+		}
+
+		_, err = Fetch(vmConfig, testDir)
+		assert.Nil(err, "%s template", hType)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/containerd/fifo"
+	"github.com/containernetworking/plugins/pkg/ns"
 	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
 	kataclient "github.com/kata-containers/agent/protocols/client"
@@ -35,6 +36,7 @@ import (
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
 
 	"github.com/blang/semver"
 	"github.com/containerd/console"
@@ -77,6 +79,15 @@ const (
 	fcMetricsFifo = "metrics.fifo"
 
 	defaultFcConfig = "fcConfig.json"
+
+	// Name of the snapshot files within jailer root, used when
+	// firecracker can only write into its jail
+	fcSnapshot    = "vm.snap"
+	fcSnapshotMem = "vm.mem"
+
+	// fcSnapshotMemSuffix is appended to the snapshot path in order to
+	// name the guest memory file saved along with the VM state
+	fcSnapshotMemSuffix = ".mem"
 	// storagePathSuffix mirrors persist/fs/fs.go:storagePathSuffix
 	storagePathSuffix = "vc"
 )
@@ -84,6 +95,14 @@ const (
 // Specify the minimum version of firecracker supported
 var fcMinSupportedVersion = semver.MustParse("0.21.1")
 
+// Specify the minimum version of firecracker able to pause, resume and
+// snapshot the VM
+var fcSnapshotMinSupportedVersion = semver.MustParse("0.23.0")
+
+// Specify the minimum version of firecracker providing the balloon device
+// used to resize the VM memory
+var fcBalloonMinSupportedVersion = semver.MustParse("0.24.0")
+
 var fcKernelParams = []Param{
 	// The boot source is the first partition of the first block device added
 	{"pci", "off"},
@@ -326,6 +345,43 @@ func (fc *firecracker) checkVersion(version string) error {
 	return nil
 }
 
+// checkFeatureVersion checks the firecracker in use is recent enough to
+// provide the feature.
+func (fc *firecracker) checkFeatureVersion(feature string, minVersion semver.Version) error {
+	// The version is not part of the persisted state, e.g. when the
+	// sandbox is handled by another kata-runtime process.
+	if fc.info.Version == "" {
+		version, err := fc.getVersionNumber()
+		if err != nil {
+			return err
+		}
+		fc.info.Version = version
+	}
+
+	v, err := semver.Make(fc.info.Version)
+	if err != nil {
+		return fmt.Errorf("Malformed firecracker version: %v", err)
+	}
+
+	if v.LT(minVersion) {
+		return fmt.Errorf("firecracker version %v cannot %s. Minimum required version is %v", v.String(), feature, minVersion.String())
+	}
+
+	return nil
+}
+
+// checkSnapshotVersion checks the firecracker in use can pause, resume
+// and snapshot the VM.
+func (fc *firecracker) checkSnapshotVersion() error {
+	return fc.checkFeatureVersion("pause, resume or snapshot the VM", fcSnapshotMinSupportedVersion)
+}
+
+// checkBalloonVersion checks the firecracker in use provides the balloon
+// device used to resize the VM memory.
+func (fc *firecracker) checkBalloonVersion() error {
+	return fc.checkFeatureVersion("resize the VM memory", fcBalloonMinSupportedVersion)
+}
+
 // waitVMMRunning will wait for timeout seconds for the VMM to be up and running.
 func (fc *firecracker) waitVMMRunning(timeout int) error {
 	span, _ := fc.trace("wait VMM to be running")
@@ -365,11 +421,18 @@ func (fc *firecracker) fcInit(timeout int) error {
 
 	var cmd *exec.Cmd
 	var args []string
+	var configArgs []string
 
 	if fc.fcConfigPath, err = fc.fcJailResource(fc.fcConfigPath, defaultFcConfig); err != nil {
 		return err
 	}
 
+	// A VM booted from a template is configured by its snapshot, loaded
+	// once firecracker runs.
+	if !fc.config.BootFromTemplate {
+		configArgs = []string{"--config-file", fc.fcConfigPath}
+	}
+
 	if !fc.config.Debug && fc.stateful {
 		args = append(args, "--daemonize")
 	}
@@ -392,13 +455,13 @@ func (fc *firecracker) fcInit(timeout int) error {
 		if fc.netNSPath != "" {
 			args = append(args, "--netns", fc.netNSPath)
 		}
-		args = append(args, "--", "--config-file", fc.fcConfigPath)
+		args = append(args, "--")
+		args = append(args, configArgs...)
 
 		cmd = exec.Command(fc.config.JailerPath, args...)
 	} else {
-		args = append(args,
-			"--api-sock", fc.socketPath,
-			"--config-file", fc.fcConfigPath)
+		args = append(args, "--api-sock", fc.socketPath)
+		args = append(args, configArgs...)
 		cmd = exec.Command(fc.config.HypervisorPath, args...)
 	}
 
@@ -619,6 +682,46 @@ func (fc *firecracker) fcSetVMBaseConfig(mem int64, vcpus int64, htEnabled bool)
 	fc.fcConfig.MachineConfig = cfg
 }
 
+// fcSetBalloon configures a balloon device reserving all the memory the
+// VM can grow to, up to DefaultMaxMemorySize, besides the default memory. The VM memory is resized by
+// deflating this balloon. It returns the memory size the VM boots with.
+func (fc *firecracker) fcSetBalloon() (uint32, error) {
+	span, _ := fc.trace("fcSetBalloon")
+	defer span.Finish()
+
+	if err := fc.checkBalloonVersion(); err != nil {
+		fc.Logger().WithError(err).Warn("VM memory cannot be resized")
+		return fc.config.MemorySize, nil
+	}
+
+	hostMemKb, err := getHostMemorySizeKb(procMemInfo)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to read memory info: %s", err)
+	}
+
+	maxMem := uint32(hostMemKb >> 10)
+	if fc.config.DefaultMaxMemorySize > 0 && fc.config.DefaultMaxMemorySize < uint64(maxMem) {
+		maxMem = uint32(fc.config.DefaultMaxMemorySize)
+	}
+	if maxMem <= fc.config.MemorySize {
+		return fc.config.MemorySize, nil
+	}
+
+	amount := int64(maxMem - fc.config.MemorySize)
+	deflateOnOom := false
+	fc.fcConfig.Balloon = &models.Balloon{
+		AmountMib:    &amount,
+		DeflateOnOom: &deflateOnOom,
+	}
+
+	fc.Logger().WithFields(logrus.Fields{
+		"max-memory": maxMem,
+		"balloon":    amount,
+	}).Debug("fcSetBalloon")
+
+	return maxMem, nil
+}
+
 func (fc *firecracker) fcSetLogger() error {
 	span, _ := fc.trace("fcSetLogger")
 	defer span.Finish()
@@ -703,7 +806,12 @@ func (fc *firecracker) fcInitConfiguration() error {
 		}
 	}
 
-	fc.fcSetVMBaseConfig(int64(fc.config.MemorySize),
+	memSize, err := fc.fcSetBalloon()
+	if err != nil {
+		return err
+	}
+
+	fc.fcSetVMBaseConfig(int64(memSize),
 		int64(fc.config.NumVCPUs), false)
 
 	kernelPath, err := fc.config.KernelAssetPath()
@@ -753,6 +861,10 @@ func (fc *firecracker) fcInitConfiguration() error {
 		return err
 	}
 
+	if err := fc.createNetPool(); err != nil {
+		return err
+	}
+
 	if err := fc.fcSetLogger(); err != nil {
 		return err
 	}
@@ -808,6 +920,12 @@ func (fc *firecracker) startSandbox(timeout int) error {
 		return err
 	}
 
+	if fc.config.BootFromTemplate {
+		if err = fc.fcLoadSnapshot(fc.config.DevicesStatePath); err != nil {
+			return err
+		}
+	}
+
 	// make sure 'others' don't have access to this socket
 	err = os.Chmod(filepath.Join(fc.jailerRoot, defaultHybridVSocketName), 0640)
 	if err != nil {
@@ -822,6 +940,27 @@ func fcDriveIndexToID(i int) string {
 	return "drive_" + strconv.Itoa(i)
 }
 
+func fcSpareNetIndexToID(i int) string {
+	return "spare_" + strconv.Itoa(i)
+}
+
+// fcSpareTapName returns the name of the placeholder TAP interface backing
+// the spare network device i. The kata suffix keeps netmon away from it.
+func fcSpareTapName(i int) string {
+	return fmt.Sprintf("spare%d_kata", i)
+}
+
+// fcSpareTapNetID returns the ID of the spare network device backed by the
+// TAP interface tapName.
+func fcSpareTapNetID(tapName string) (string, error) {
+	var i int
+	if _, err := fmt.Sscanf(tapName, "spare%d_kata", &i); err != nil {
+		return "", fmt.Errorf("Invalid spare TAP interface %s", tapName)
+	}
+
+	return fcSpareNetIndexToID(i), nil
+}
+
 // Creates a disk pool to attach container virtio-block devices with
 // fcUpdateBlockDrive
 func (fc *firecracker) createDiskPool() error {
@@ -852,6 +991,227 @@ func (fc *firecracker) createDiskPool() error {
 	return nil
 }
 
+// Creates a pool of SpareNetworkDevices spare network devices to attach
+// the interfaces added to the running sandbox with hotplugNetDevice, since
+// firecracker cannot hotplug them. Interfaces are connected to one of the
+// placeholder TAP interfaces backing these devices. This runs in the
+// sandbox network namespace.
+func (fc *firecracker) createNetPool() error {
+	span, _ := fc.trace("createNetPool")
+	defer span.Finish()
+
+	if fc.netNSPath == "" || fc.config.SpareNetworkDevices == 0 {
+		return nil
+	}
+
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Delete()
+
+	for i := 0; i < int(fc.config.SpareNetworkDevices); i++ {
+		ifaceID := fcSpareNetIndexToID(i)
+		tapName := fcSpareTapName(i)
+
+		guestMac, err := generateRandomPrivateMacAddr()
+		if err != nil {
+			return err
+		}
+
+		hardAddr, err := net.ParseMAC(guestMac)
+		if err != nil {
+			return err
+		}
+
+		// The TAP interface is persistent, firecracker opens it by name.
+		tapLink, fds, err := createLink(netHandle, tapName, &netlink.Tuntap{}, 0)
+		for _, f := range fds {
+			f.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("Could not create spare TAP interface: %s", err)
+		}
+
+		// Keep track of the MAC address the guest sees for this device.
+		if err := netHandle.LinkSetHardwareAddr(tapLink, hardAddr); err != nil {
+			return fmt.Errorf("Could not set MAC address %s for TAP %s: %s", guestMac, tapName, err)
+		}
+
+		ifaceCfg := &models.NetworkInterface{
+			AllowMmdsRequests: false,
+			GuestMac:          guestMac,
+			IfaceID:           &ifaceID,
+			HostDevName:       &tapName,
+		}
+
+		fc.fcConfig.NetworkInterfaces = append(fc.fcConfig.NetworkInterfaces, ifaceCfg)
+	}
+
+	return nil
+}
+
+// removeNetPool removes the placeholder TAP interfaces of the spare network
+// devices, the network namespace may outlive the VM.
+func (fc *firecracker) removeNetPool() {
+	if fc.netNSPath == "" || fc.config.SpareNetworkDevices == 0 {
+		return
+	}
+
+	err := doNetNS(fc.netNSPath, func(_ ns.NetNS) error {
+		netHandle, err := netlink.NewHandle()
+		if err != nil {
+			return err
+		}
+		defer netHandle.Delete()
+
+		for i := 0; i < int(fc.config.SpareNetworkDevices); i++ {
+			tapLink, err := getLinkByName(netHandle, fcSpareTapName(i), &netlink.Tuntap{})
+			if err != nil {
+				continue
+			}
+			if err := netHandle.LinkDel(tapLink); err != nil {
+				fc.Logger().WithError(err).WithField("tap", fcSpareTapName(i)).Warn("Failed to remove spare TAP")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		fc.Logger().WithError(err).Warn("Failed to remove spare network devices")
+	}
+}
+
+// fcGetSpareTap returns the placeholder TAP interface of the spare network
+// device attached to the endpoint named owner, or a free one if owner is
+// empty. The endpoint name is kept as the TAP interface alias.
+func (fc *firecracker) fcGetSpareTap(netHandle *netlink.Handle, owner string) (netlink.Link, error) {
+	for i := 0; i < int(fc.config.SpareNetworkDevices); i++ {
+		tapLink, err := getLinkByName(netHandle, fcSpareTapName(i), &netlink.Tuntap{})
+		if err != nil {
+			return nil, err
+		}
+
+		if tapLink.Attrs().Alias == owner {
+			return tapLink, nil
+		}
+	}
+
+	if owner == "" {
+		return nil, fmt.Errorf("No spare network device left out of %d", fc.config.SpareNetworkDevices)
+	}
+
+	return nil, fmt.Errorf("No spare network device attached to %s", owner)
+}
+
+// fcAttachSpareTap redirects the endpoint traffic to a free spare network
+// device. The TAP interface created for the endpoint by xConnectVMNetwork
+// is left unused, it is removed along with the endpoint.
+func (fc *firecracker) fcAttachSpareTap(endpoint Endpoint) error {
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Delete()
+
+	netPair := endpoint.NetworkPair()
+
+	link, err := getLinkForEndpoint(endpoint, netHandle)
+	if err != nil {
+		return err
+	}
+
+	tapLink, err := fc.fcGetSpareTap(netHandle, "")
+	if err != nil {
+		return err
+	}
+
+	if err := netHandle.LinkSetAlias(tapLink, endpoint.Name()); err != nil {
+		return fmt.Errorf("Could not reserve spare TAP %s: %s", tapLink.Attrs().Name, err)
+	}
+
+	if err := netHandle.LinkSetMTU(tapLink, link.Attrs().MTU); err != nil {
+		return fmt.Errorf("Could not set TAP MTU %d: %s", link.Attrs().MTU, err)
+	}
+
+	if err := netHandle.LinkSetUp(tapLink); err != nil {
+		return fmt.Errorf("Could not enable TAP %s: %s", tapLink.Attrs().Name, err)
+	}
+
+	if err := removeRedirectTCFilter(link); err != nil {
+		return err
+	}
+
+	if err := addQdiscIngress(tapLink.Attrs().Index); err != nil {
+		return err
+	}
+
+	if err := addRedirectTCFilter(link.Attrs().Index, tapLink.Attrs().Index); err != nil {
+		return err
+	}
+
+	if err := addRedirectTCFilter(tapLink.Attrs().Index, link.Attrs().Index); err != nil {
+		return err
+	}
+
+	// The guest sees the interface with the MAC address of the spare
+	// device, this is the one the agent has to look for.
+	netPair.TAPIface.HardAddr = tapLink.Attrs().HardwareAddr.String()
+
+	if netPair.Config.rateLimited() {
+		ifaceID, err := fcSpareTapNetID(tapLink.Attrs().Name)
+		if err != nil {
+			return err
+		}
+
+		return fc.fcUpdateNetRateLimiters(ifaceID, netPair.Config)
+	}
+
+	return nil
+}
+
+// fcDetachSpareTap releases the spare network device attached to the
+// endpoint, so that it can be used again.
+func (fc *firecracker) fcDetachSpareTap(endpoint Endpoint) error {
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Delete()
+
+	tapLink, err := fc.fcGetSpareTap(netHandle, endpoint.Name())
+	if err != nil {
+		return err
+	}
+
+	if err := removeRedirectTCFilter(tapLink); err != nil {
+		return err
+	}
+
+	if err := removeQdiscIngress(tapLink); err != nil {
+		return err
+	}
+
+	if err := netHandle.LinkSetDown(tapLink); err != nil {
+		return fmt.Errorf("Could not disable TAP %s: %s", tapLink.Attrs().Name, err)
+	}
+
+	// The next endpoint attached to the spare device sets its own rate
+	// limiters.
+	if endpoint.NetworkPair().Config.rateLimited() {
+		ifaceID, err := fcSpareTapNetID(tapLink.Attrs().Name)
+		if err != nil {
+			return err
+		}
+
+		if err := fc.fcUpdateNetRateLimiters(ifaceID, NetInterfaceConfig{}); err != nil {
+			return err
+		}
+	}
+
+	return netHandle.LinkSetAlias(tapLink, "")
+}
+
 func (fc *firecracker) umountResource(jailedPath string) {
 	hostPath := filepath.Join(fc.jailerRoot, jailedPath)
 	fc.Logger().WithField("resource", hostPath).Debug("Unmounting resource")
@@ -872,6 +1232,10 @@ func (fc *firecracker) cleanupJail() {
 	fc.umountResource(fcLogFifo)
 	fc.umountResource(fcMetricsFifo)
 	fc.umountResource(defaultFcConfig)
+	if fc.config.BootFromTemplate {
+		fc.umountResource(fcSnapshot)
+		fc.umountResource(fcSnapshotMem)
+	}
 	// if running with jailer, we also need to umount fc.jailerRoot
 	if fc.config.JailerPath != "" {
 		if err := syscall.Unmount(fc.jailerRoot, syscall.MNT_DETACH); err != nil {
@@ -890,19 +1254,150 @@ func (fc *firecracker) stopSandbox() (err error) {
 	span, _ := fc.trace("stopSandbox")
 	defer span.Finish()
 
+	defer fc.removeNetPool()
+
 	return fc.fcEnd()
 }
 
+func (fc *firecracker) fcSetVMState(state string) error {
+	span, _ := fc.trace("fcSetVMState")
+	defer span.Finish()
+
+	if err := fc.checkSnapshotVersion(); err != nil {
+		return err
+	}
+
+	param := ops.NewPatchVMParams()
+	param.SetBody(&models.VM{
+		State: &state,
+	})
+
+	if _, err := fc.client().Operations.PatchVM(param); err != nil {
+		return errors.Wrapf(err, "failed to set firecracker VM state to %s", state)
+	}
+
+	return nil
+}
+
 func (fc *firecracker) pauseSandbox() error {
+	span, _ := fc.trace("pauseSandbox")
+	defer span.Finish()
+
+	return fc.fcSetVMState(models.VMStatePaused)
+}
+
+// fcSnapshotMemPath returns the path of the guest memory file saved
+// along with the VM state at statePath.
+func fcSnapshotMemPath(statePath string) string {
+	return statePath + fcSnapshotMemSuffix
+}
+
+// saveSandbox creates a full snapshot of the paused VM. The VM state is
+// saved to statePath and the guest memory next to it.
+func (fc *firecracker) saveSandbox(statePath string) error {
+	span, _ := fc.trace("saveSandbox")
+	defer span.Finish()
+
+	if err := fc.checkSnapshotVersion(); err != nil {
+		return err
+	}
+
+	if statePath == "" {
+		return fmt.Errorf("Missing firecracker snapshot path")
+	}
+
+	snapshotPath := statePath
+	memPath := fcSnapshotMemPath(statePath)
+	if fc.jailed {
+		// The jailed firecracker cannot reach statePath, let it write
+		// the snapshot into its root and move it out afterwards.
+		snapshotPath = filepath.Join("/", fcSnapshot)
+		memPath = filepath.Join("/", fcSnapshotMem)
+	}
+
+	param := ops.NewCreateSnapshotParams()
+	param.SetBody(&models.SnapshotCreateParams{
+		SnapshotPath: &snapshotPath,
+		MemFilePath:  &memPath,
+		SnapshotType: models.SnapshotCreateParamsSnapshotTypeFull,
+	})
+
+	fc.Logger().WithField("snapshot", statePath).Info("Saving VM snapshot")
+	if _, err := fc.client().Operations.CreateSnapshot(param); err != nil {
+		return errors.Wrap(err, "failed to create firecracker VM snapshot")
+	}
+
+	if fc.jailed {
+		if err := fcMoveFile(filepath.Join(fc.jailerRoot, fcSnapshot), statePath); err != nil {
+			return err
+		}
+		if err := fcMoveFile(filepath.Join(fc.jailerRoot, fcSnapshotMem), fcSnapshotMemPath(statePath)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (fc *firecracker) saveSandbox() error {
+// fcLoadSnapshot loads the VM from the snapshot saved at statePath. The
+// loaded VM is paused and needs to be resumed.
+func (fc *firecracker) fcLoadSnapshot(statePath string) error {
+	span, _ := fc.trace("fcLoadSnapshot")
+	defer span.Finish()
+
+	if err := fc.checkSnapshotVersion(); err != nil {
+		return err
+	}
+
+	// The VMs booted from the same template share its snapshot, each
+	// jailed firecracker privately maps the guest memory file.
+	snapshotPath, err := fc.fcJailResource(statePath, fcSnapshot)
+	if err != nil {
+		return err
+	}
+
+	memPath, err := fc.fcJailResource(fcSnapshotMemPath(statePath), fcSnapshotMem)
+	if err != nil {
+		return err
+	}
+
+	param := ops.NewLoadSnapshotParams()
+	param.SetBody(&models.SnapshotLoadParams{
+		SnapshotPath: &snapshotPath,
+		MemFilePath:  &memPath,
+	})
+
+	fc.Logger().WithField("snapshot", statePath).Info("Loading VM snapshot")
+	if _, err := fc.client().Operations.LoadSnapshot(param); err != nil {
+		return errors.Wrap(err, "failed to load firecracker VM snapshot")
+	}
+
 	return nil
 }
 
+// fcMoveFile moves src to dst, the jailer root and dst can live on
+// different filesystems.
+func fcMoveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	if err := utils.FileCopy(src, dst); err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
+
 func (fc *firecracker) resumeSandbox() error {
-	return nil
+	span, _ := fc.trace("resumeSandbox")
+	defer span.Finish()
+
+	return fc.fcSetVMState(models.VMStateResumed)
+}
+
+func (fc *firecracker) migrateSandbox(uri string) error {
+	return errors.New("firecracker does not support live migration")
 }
 
 func (fc *firecracker) fcAddVsock(hvs types.HybridVSock) {
@@ -937,9 +1432,56 @@ func (fc *firecracker) fcAddNetDevice(endpoint Endpoint) {
 		HostDevName:       &endpoint.NetworkPair().TapInterface.TAPIface.Name,
 	}
 
+	c := endpoint.NetworkPair().Config
+	if c.RxRateLimiterMaxRate > 0 {
+		ifaceCfg.RxRateLimiter = fcRateLimiter(c.RxRateLimiterMaxRate)
+	}
+	if c.TxRateLimiterMaxRate > 0 {
+		ifaceCfg.TxRateLimiter = fcRateLimiter(c.TxRateLimiterMaxRate)
+	}
+
 	fc.fcConfig.NetworkInterfaces = append(fc.fcConfig.NetworkInterfaces, ifaceCfg)
 }
 
+// fcRateLimiter returns the rate limiter capping the bandwidth to maxRate
+// bits per second, its bucket being refilled every second. A zero maxRate
+// disables the rate limiter.
+func fcRateLimiter(maxRate uint64) *models.RateLimiter {
+	size := int64(maxRate / 8)
+	refillTime := int64(1000)
+	if maxRate == 0 {
+		refillTime = 0
+	}
+
+	return &models.RateLimiter{
+		Bandwidth: &models.TokenBucket{
+			Size:       &size,
+			RefillTime: &refillTime,
+		},
+	}
+}
+
+// fcUpdateNetRateLimiters replaces the rate limiters of the network device
+// ifaceID of the running VM.
+func (fc *firecracker) fcUpdateNetRateLimiters(ifaceID string, c NetInterfaceConfig) error {
+	span, _ := fc.trace("fcUpdateNetRateLimiters")
+	defer span.Finish()
+
+	ifaceParams := ops.NewPatchGuestNetworkInterfaceByIDParams()
+	ifaceParams.SetIfaceID(ifaceID)
+	ifaceParams.SetBody(&models.PartialNetworkInterface{
+		IfaceID:       &ifaceID,
+		RxRateLimiter: fcRateLimiter(c.RxRateLimiterMaxRate),
+		TxRateLimiter: fcRateLimiter(c.TxRateLimiterMaxRate),
+	})
+
+	if _, err := fc.client().Operations.PatchGuestNetworkInterfaceByID(ifaceParams); err != nil {
+		return fmt.Errorf("Could not update the rate limiters of network device %s: %v", ifaceID, err)
+	}
+
+	return nil
+}
+
 func (fc *firecracker) fcAddBlockDrive(drive config.BlockDrive) error {
 	span, _ := fc.trace("fcAddBlockDrive")
 	defer span.Finish()
@@ -1050,6 +1592,39 @@ func (fc *firecracker) hotplugBlockDevice(drive config.BlockDrive, op operation)
 	return nil, fc.fcUpdateBlockDrive(path, driveID)
 }
 
+// hotplugNetDevice connects the endpoint to one of the spare network
+// devices attached at boot time, or disconnects it. This is only possible
+// with the tcfilter interworking model. The guest sees the interface with
+// the MAC address of the spare device, not the one of the endpoint, since
+// firecracker cannot change it once the VM is booted: the traffic leaves
+// the sandbox with that MAC address, which the networks filtering on the
+// MAC address of the endpoint drop.
+func (fc *firecracker) hotplugNetDevice(endpoint Endpoint, op operation) (interface{}, error) {
+	netPair := endpoint.NetworkPair()
+	if netPair == nil {
+		return nil, fmt.Errorf("Could not hotplug endpoint %s without network pair", endpoint.Name())
+	}
+
+	model := netPair.NetInterworkingModel
+	if model == NetXConnectDefaultModel {
+		model = DefaultNetInterworkingModel
+	}
+
+	// Only the TC filter model allows to redirect the endpoint traffic
+	// to an existing TAP interface.
+	if model != NetXConnectTCFilterModel {
+		return nil, fmt.Errorf("Could not hotplug endpoint %s: firecracker only supports the %s interworking model",
+			endpoint.Name(), tcFilterNetModelStr)
+	}
+
+	return nil, doNetNS(fc.netNSPath, func(_ ns.NetNS) error {
+		if op == addDevice {
+			return fc.fcAttachSpareTap(endpoint)
+		}
+		return fc.fcDetachSpareTap(endpoint)
+	})
+}
+
 // hotplugAddDevice supported in Firecracker VMM
 func (fc *firecracker) hotplugAddDevice(devInfo interface{}, devType deviceType) (interface{}, error) {
 	span, _ := fc.trace("hotplugAddDevice")
@@ -1058,6 +1633,8 @@ func (fc *firecracker) hotplugAddDevice(devInfo interface{}, devType deviceType)
 	switch devType {
 	case blockDev:
 		return fc.hotplugBlockDevice(*devInfo.(*config.BlockDrive), addDevice)
+	case netDev:
+		return fc.hotplugNetDevice(devInfo.(Endpoint), addDevice)
 	default:
 		fc.Logger().WithFields(logrus.Fields{"devInfo": devInfo,
 			"deviceType": devType}).Warn("hotplugAddDevice: unsupported device")
@@ -1074,6 +1651,8 @@ func (fc *firecracker) hotplugRemoveDevice(devInfo interface{}, devType deviceTy
 	switch devType {
 	case blockDev:
 		return fc.hotplugBlockDevice(*devInfo.(*config.BlockDrive), removeDevice)
+	case netDev:
+		return fc.hotplugNetDevice(devInfo.(Endpoint), removeDevice)
 	default:
 		fc.Logger().WithFields(logrus.Fields{"devInfo": devInfo,
 			"deviceType": devType}).Error("hotplugRemoveDevice: unsupported device")
@@ -1098,20 +1677,122 @@ func (fc *firecracker) capabilities() types.Capabilities {
 	defer span.Finish()
 	var caps types.Capabilities
 	caps.SetBlockDeviceHotplugSupport()
+	caps.SetTemplateSupport()
+	caps.SetNetRateLimiterSupport()
 
 	return caps
 }
 
+// checkTemplateConfig checks the firecracker VM template requirements. The
+// template is a snapshot saved at DevicesStatePath, referring to the
+// devices of the template VM by their path. Only a jailed firecracker
+// uses the same paths for all the VMs, and only a firecracker able to
+// snapshot VMs can create and load the template.
+func (fc *firecracker) checkTemplateConfig(conf *HypervisorConfig) error {
+	if conf.DevicesStatePath == "" {
+		return fmt.Errorf("Missing DevicesStatePath for vm template")
+	}
+
+	if conf.JailerPath == "" {
+		return fmt.Errorf("Missing JailerPath for vm template")
+	}
+
+	if fc.config.HypervisorPath == "" {
+		fc.config.HypervisorPath = conf.HypervisorPath
+	}
+
+	return fc.checkSnapshotVersion()
+}
+
+// templateFiles returns the VM state and the guest memory files of the
+// template VM snapshot.
+func (fc *firecracker) templateFiles(conf *HypervisorConfig) []string {
+	return []string{conf.DevicesStatePath, fcSnapshotMemPath(conf.DevicesStatePath)}
+}
+
 func (fc *firecracker) hypervisorConfig() HypervisorConfig {
 	return fc.config
 }
 
+// fcGetMemory returns the memory size the VM booted with and the current
+// size of its balloon, in MiB.
+func (fc *firecracker) fcGetMemory() (uint32, uint32, error) {
+	machineConfig, err := fc.client().Operations.GetMachineConfiguration(nil)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to get firecracker machine configuration")
+	}
+
+	maxMem := uint32(*machineConfig.Payload.MemSizeMib)
+	if maxMem == fc.config.MemorySize {
+		// No balloon, there is no memory left to grow to.
+		return maxMem, 0, nil
+	}
+
+	balloon, err := fc.client().Operations.DescribeBalloonConfig(nil)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to get firecracker balloon configuration")
+	}
+
+	return maxMem, uint32(*balloon.Payload.AmountMib), nil
+}
+
+// resizeMemory grows the VM memory by deflating the balloon configured at
+// boot time. Removing memory is not supported.
 func (fc *firecracker) resizeMemory(reqMemMB uint32, memoryBlockSizeMB uint32, probe bool) (uint32, memoryDevice, error) {
-	return 0, memoryDevice{}, nil
+	span, _ := fc.trace("resizeMemory")
+	defer span.Finish()
+
+	if err := fc.checkBalloonVersion(); err != nil {
+		if reqMemMB > fc.config.MemorySize {
+			return fc.config.MemorySize, memoryDevice{}, errors.Wrapf(err, "Unable to resize memory from %d MiB to %d MiB", fc.config.MemorySize, reqMemMB)
+		}
+		return fc.config.MemorySize, memoryDevice{}, nil
+	}
+
+	maxMem, balloonMem, err := fc.fcGetMemory()
+	if err != nil {
+		return 0, memoryDevice{}, err
+	}
+
+	currentMem := maxMem - balloonMem
+	if reqMemMB <= currentMem {
+		if reqMemMB < currentMem {
+			fc.Logger().Warn("Remove memory is not supported, nothing to do")
+		}
+		return currentMem, memoryDevice{}, nil
+	}
+
+	if reqMemMB > maxMem {
+		return currentMem, memoryDevice{}, fmt.Errorf("Unable to resize memory to %d MiB, the maximum amount is %d MiB", reqMemMB, maxMem)
+	}
+
+	amount := int64(maxMem - reqMemMB)
+	param := ops.NewPatchBalloonParams()
+	param.SetBody(&models.BalloonUpdate{
+		AmountMib: &amount,
+	})
+
+	fc.Logger().WithFields(logrus.Fields{
+		"current-memory": currentMem,
+		"new-memory":     reqMemMB,
+	}).Debug("deflating balloon")
+
+	if _, err := fc.client().Operations.PatchBalloon(param); err != nil {
+		return currentMem, memoryDevice{}, errors.Wrapf(err, "failed to resize memory from %d MiB to %d MiB", currentMem, reqMemMB)
+	}
+
+	return reqMemMB, memoryDevice{sizeMB: int(reqMemMB - currentMem)}, nil
 }
 
+// resizeVCPUs fails when other vCPUs than the VM booted with are
+// requested, firecracker does not support vCPU hotplug.
 func (fc *firecracker) resizeVCPUs(reqVCPUs uint32) (currentVCPUs uint32, newVCPUs uint32, err error) {
-	return 0, 0, nil
+	currentVCPUs = fc.config.NumVCPUs
+	if reqVCPUs != currentVCPUs {
+		return currentVCPUs, currentVCPUs, fmt.Errorf("Unable to resize vCPUs from %d to %d, firecracker does not support vCPU hotplug", currentVCPUs, reqVCPUs)
+	}
+
+	return currentVCPUs, currentVCPUs, nil
 }
 
 // This is used to apply cgroup information on the host.
@@ -8,8 +8,12 @@ package virtcontainers
 import (
 	"testing"
 
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/containernetworking/plugins/pkg/testutils"
+	ktu "github.com/kata-containers/runtime/pkg/katatestutils"
 	"github.com/kata-containers/runtime/virtcontainers/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
 )
 
 func TestFCGenerateSocket(t *testing.T) {
@@ -45,3 +49,277 @@ func TestFCTruncateID(t *testing.T) {
 	id = fc.truncateID(testShortID)
 	assert.Equal(expectedID, id)
 }
+
+func TestFCCheckSnapshotVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	fc := firecracker{}
+
+	fc.info.Version = "0.21.1"
+	assert.Error(fc.checkSnapshotVersion())
+	assert.Error(fc.pauseSandbox())
+	assert.Error(fc.resumeSandbox())
+	assert.Error(fc.saveSandbox("/tmp/vm.state"))
+
+	fc.info.Version = "foo"
+	assert.Error(fc.checkSnapshotVersion())
+
+	fc.info.Version = fcSnapshotMinSupportedVersion.String()
+	assert.NoError(fc.checkSnapshotVersion())
+
+	fc.info.Version = "0.23.1"
+	assert.NoError(fc.checkSnapshotVersion())
+	assert.Error(fc.saveSandbox(""))
+
+	fc.info.Version = "0.21.1"
+	assert.Error(fc.fcLoadSnapshot("/tmp/vm.state"))
+}
+
+func TestFCCheckTemplateConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	fc := firecracker{}
+	caps := fc.capabilities()
+	assert.True(caps.IsTemplateSupported())
+
+	config := HypervisorConfig{BootFromTemplate: true}
+	assert.Error(fc.checkTemplateConfig(&config))
+
+	config.DevicesStatePath = "/tmp/vm.state"
+	assert.Error(fc.checkTemplateConfig(&config))
+
+	config.JailerPath = "/usr/bin/jailer"
+	fc.info.Version = "0.21.1"
+	assert.Error(fc.checkTemplateConfig(&config))
+
+	fc.info.Version = fcSnapshotMinSupportedVersion.String()
+	assert.NoError(fc.checkTemplateConfig(&config))
+
+	config.DevicesStatePath = "/run/template/state"
+	assert.Equal([]string{"/run/template/state", "/run/template/state.mem"}, fc.templateFiles(&config))
+}
+
+func TestFCSnapshotMemPath(t *testing.T) {
+	assert.Equal(t, "/foo/vm.state"+fcSnapshotMemSuffix, fcSnapshotMemPath("/foo/vm.state"))
+}
+
+func TestFCSetBalloon(t *testing.T) {
+	assert := assert.New(t)
+
+	fc := firecracker{
+		fcConfig: &types.FcConfig{},
+	}
+	fc.config.MemorySize = 256
+
+	fc.info.Version = "0.23.0"
+	memSize, err := fc.fcSetBalloon()
+	assert.NoError(err)
+	assert.Equal(fc.config.MemorySize, memSize)
+	assert.Nil(fc.fcConfig.Balloon)
+
+	fc.info.Version = fcBalloonMinSupportedVersion.String()
+	memSize, err = fc.fcSetBalloon()
+	assert.NoError(err)
+	assert.True(memSize >= fc.config.MemorySize)
+	if memSize > fc.config.MemorySize {
+		assert.NotNil(fc.fcConfig.Balloon)
+		assert.Equal(int64(memSize-fc.config.MemorySize), *fc.fcConfig.Balloon.AmountMib)
+	}
+
+	// The VM memory is capped to the maximum memory size.
+	fc.fcConfig.Balloon = nil
+	fc.config.DefaultMaxMemorySize = 512
+	memSize, err = fc.fcSetBalloon()
+	assert.NoError(err)
+	assert.True(memSize <= 512)
+	if memSize > fc.config.MemorySize {
+		assert.Equal(uint32(512), memSize)
+		assert.Equal(int64(256), *fc.fcConfig.Balloon.AmountMib)
+	}
+}
+
+func TestFCResizeMemoryWithoutBalloon(t *testing.T) {
+	assert := assert.New(t)
+
+	fc := firecracker{}
+	fc.config.MemorySize = 256
+	fc.info.Version = "0.23.0"
+
+	mem, _, err := fc.resizeMemory(256, 128, false)
+	assert.NoError(err)
+	assert.Equal(uint32(256), mem)
+
+	// Without balloon, adding memory fails.
+	mem, _, err = fc.resizeMemory(512, 128, false)
+	assert.Error(err)
+	assert.Equal(uint32(256), mem)
+}
+
+func TestFCResizeVCPUs(t *testing.T) {
+	assert := assert.New(t)
+
+	fc := firecracker{}
+	fc.config.NumVCPUs = 2
+
+	current, updated, err := fc.resizeVCPUs(2)
+	assert.NoError(err)
+	assert.Equal(uint32(2), current)
+	assert.Equal(uint32(2), updated)
+
+	current, updated, err = fc.resizeVCPUs(1)
+	assert.Error(err)
+	assert.Equal(uint32(2), current)
+	assert.Equal(uint32(2), updated)
+
+	current, updated, err = fc.resizeVCPUs(4)
+	assert.Error(err)
+	assert.Equal(uint32(2), current)
+	assert.Equal(uint32(2), updated)
+}
+
+func TestFCAddNetDeviceRateLimiters(t *testing.T) {
+	assert := assert.New(t)
+
+	fc := firecracker{
+		fcConfig: &types.FcConfig{},
+	}
+
+	endpoint, err := createVethNetworkEndpoint(1, "eth1", NetXConnectTCFilterModel)
+	assert.NoError(err)
+
+	fc.fcAddNetDevice(endpoint)
+	assert.Nil(fc.fcConfig.NetworkInterfaces[0].RxRateLimiter)
+	assert.Nil(fc.fcConfig.NetworkInterfaces[0].TxRateLimiter)
+
+	endpoint.NetPair.Config.TxRateLimiterMaxRate = 8000000
+	fc.fcAddNetDevice(endpoint)
+	assert.Nil(fc.fcConfig.NetworkInterfaces[1].RxRateLimiter)
+	bucket := fc.fcConfig.NetworkInterfaces[1].TxRateLimiter.Bandwidth
+	assert.Equal(int64(1000000), *bucket.Size)
+	assert.Equal(int64(1000), *bucket.RefillTime)
+
+	// A disabled rate limiter has an empty bucket.
+	bucket = fcRateLimiter(0).Bandwidth
+	assert.Zero(*bucket.Size)
+	assert.Zero(*bucket.RefillTime)
+}
+
+func TestFCSpareTapNetID(t *testing.T) {
+	assert := assert.New(t)
+
+	id, err := fcSpareTapNetID(fcSpareTapName(3))
+	assert.NoError(err)
+	assert.Equal(fcSpareNetIndexToID(3), id)
+
+	_, err = fcSpareTapNetID("tap0_kata")
+	assert.Error(err)
+}
+
+func TestFCHotplugNetDeviceInvalidModel(t *testing.T) {
+	assert := assert.New(t)
+
+	fc := firecracker{}
+
+	endpoint, err := createVethNetworkEndpoint(1, "eth1", NetXConnectMacVtapModel)
+	assert.NoError(err)
+
+	_, err = fc.hotplugAddDevice(endpoint, netDev)
+	assert.Error(err)
+
+	_, err = fc.hotplugRemoveDevice(endpoint, netDev)
+	assert.Error(err)
+
+	// The default model is the one configured by default.
+	savedModel := DefaultNetInterworkingModel
+	DefaultNetInterworkingModel = NetXConnectMacVtapModel
+	defer func() {
+		DefaultNetInterworkingModel = savedModel
+	}()
+
+	endpoint, err = createVethNetworkEndpoint(1, "eth1", NetXConnectDefaultModel)
+	assert.NoError(err)
+
+	_, err = fc.hotplugAddDevice(endpoint, netDev)
+	assert.Error(err)
+}
+
+func TestFCHotplugNetDevice(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	assert := assert.New(t)
+
+	netNS, err := testutils.NewNS()
+	assert.NoError(err)
+	defer testutils.UnmountNS(netNS)
+
+	fc := firecracker{
+		netNSPath: netNS.Path(),
+		fcConfig:  &types.FcConfig{},
+	}
+
+	// No spare network device by default
+	err = netNS.Do(func(_ ns.NetNS) error {
+		return fc.createNetPool()
+	})
+	assert.NoError(err)
+	assert.Empty(fc.fcConfig.NetworkInterfaces)
+
+	fc.config.SpareNetworkDevices = 4
+
+	vethName := "foo"
+	endpoint, err := createVethNetworkEndpoint(1, vethName, NetXConnectTCFilterModel)
+	assert.NoError(err)
+
+	err = netNS.Do(func(_ ns.NetNS) error {
+		veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: vethName, MTU: 1400}, PeerName: "bar"}
+		if err := netlink.LinkAdd(veth); err != nil {
+			return err
+		}
+
+		if err := fc.createNetPool(); err != nil {
+			return err
+		}
+
+		return setupTCFiltering(endpoint, 0, true)
+	})
+	assert.NoError(err)
+	assert.Len(fc.fcConfig.NetworkInterfaces, 4)
+
+	_, err = fc.hotplugAddDevice(endpoint, netDev)
+	assert.NoError(err)
+	assert.Equal(fc.fcConfig.NetworkInterfaces[0].GuestMac, endpoint.HardwareAddr())
+
+	// The spare device is in use
+	other, err := createVethNetworkEndpoint(2, "eth2", NetXConnectTCFilterModel)
+	assert.NoError(err)
+	err = netNS.Do(func(_ ns.NetNS) error {
+		netHandle, err := netlink.NewHandle()
+		if err != nil {
+			return err
+		}
+		defer netHandle.Delete()
+
+		tapLink, err := fc.fcGetSpareTap(netHandle, endpoint.Name())
+		if err != nil {
+			return err
+		}
+		assert.Equal(fcSpareTapName(0), tapLink.Attrs().Name)
+
+		_, err = fc.fcGetSpareTap(netHandle, other.Name())
+		assert.Error(err)
+
+		return removeTCFiltering(endpoint)
+	})
+	assert.NoError(err)
+
+	_, err = fc.hotplugRemoveDevice(endpoint, netDev)
+	assert.NoError(err)
+
+	// Detaching again fails, the spare device is released
+	_, err = fc.hotplugRemoveDevice(endpoint, netDev)
+	assert.Error(err)
+
+	fc.removeNetPool()
+}
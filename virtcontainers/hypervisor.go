@@ -234,6 +234,15 @@ type HypervisorConfig struct {
 	// DefaultMem specifies default memory size in MiB for the VM.
 	MemorySize uint32
 
+	// DefaultMaxMemorySize specifies the maximum memory size in MiB the
+	// VM can be resized to. The host memory size is used when it is 0.
+	DefaultMaxMemorySize uint64
+
+	// SpareNetworkDevices specifies the number of network devices
+	// attached to the VM at boot time, for the hypervisors which cannot
+	// hotplug them, so that interfaces can be added to the running VM.
+	SpareNetworkDevices uint32
+
 	// DefaultBridges specifies default number of bridges for the VM.
 	// Bridges can be used to hot plug devices
 	DefaultBridges uint32
@@ -305,8 +314,8 @@ type HypervisorConfig struct {
 	// BootFromTemplate is true.
 	MemoryPath string
 
-	// DevicesStatePath is the VM device state file path. Used when either BootToBeTemplate or
-	// BootFromTemplate is true.
+	// DevicesStatePath is the VM device state file path. Used when either BootToBeTemplate,
+	// BootFromTemplate or BootFromCheckpoint is true.
 	DevicesStatePath string
 
 	// EntropySource is the path to a host source of
@@ -414,6 +423,10 @@ type HypervisorConfig struct {
 	// BootFromTemplate used to indicate if the VM should be created from a template VM
 	BootFromTemplate bool
 
+	// BootFromCheckpoint used to indicate if the VM should be restored from
+	// the VM state saved at DevicesStatePath by a sandbox checkpoint.
+	BootFromCheckpoint bool
+
 	// DisableVhostNet is used to indicate if host supports vhost_net
 	DisableVhostNet bool
 
@@ -451,13 +464,19 @@ func (conf *HypervisorConfig) checkTemplateConfig() error {
 		return fmt.Errorf("Cannot set both 'to be' and 'from' vm tempate")
 	}
 
-	if conf.BootToBeTemplate || conf.BootFromTemplate {
-		if conf.MemoryPath == "" {
-			return fmt.Errorf("Missing MemoryPath for vm template")
+	// The hypervisor specific requirements are checked by the
+	// checkTemplateConfig method of the hypervisor interface.
+	if conf.BootFromTemplate && conf.DevicesStatePath == "" {
+		return fmt.Errorf("Missing DevicesStatePath to load from vm template")
+	}
+
+	if conf.BootFromCheckpoint {
+		if conf.BootToBeTemplate || conf.BootFromTemplate {
+			return fmt.Errorf("Cannot restore a vm template from a checkpoint")
 		}
 
-		if conf.BootFromTemplate && conf.DevicesStatePath == "" {
-			return fmt.Errorf("Missing DevicesStatePath to load from vm template")
+		if conf.DevicesStatePath == "" {
+			return fmt.Errorf("Missing DevicesStatePath to restore from checkpoint")
 		}
 	}
 
@@ -788,7 +807,11 @@ type hypervisor interface {
 	startSandbox(timeout int) error
 	stopSandbox() error
 	pauseSandbox() error
-	saveSandbox() error
+	// saveSandbox saves the state of a paused VM to statePath.
+	saveSandbox(statePath string) error
+	// migrateSandbox live migrates the VM state to uri, leaving the VM
+	// paused once the migration completed.
+	migrateSandbox(uri string) error
 	resumeSandbox() error
 	addDevice(devInfo interface{}, devType deviceType) error
 	hotplugAddDevice(devInfo interface{}, devType deviceType) (interface{}, error)
@@ -798,6 +821,12 @@ type hypervisor interface {
 	getSandboxConsole(sandboxID string) (string, error)
 	disconnect()
 	capabilities() types.Capabilities
+	// checkTemplateConfig checks the hypervisor specific requirements of
+	// a configuration booting a VM template or a VM from a template.
+	checkTemplateConfig(conf *HypervisorConfig) error
+	// templateFiles returns the files a VM template booted with conf is
+	// saved to.
+	templateFiles(conf *HypervisorConfig) []string
 	hypervisorConfig() HypervisorConfig
 	getThreadIDs() (vcpuThreadIDs, error)
 	cleanup() error
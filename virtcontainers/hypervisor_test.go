@@ -154,7 +154,26 @@ func TestHypervisorConfigValidTemplateConfig(t *testing.T) {
 	hypervisorConfig.BootFromTemplate = false
 	hypervisorConfig.BootToBeTemplate = true
 	testHypervisorConfigValid(t, hypervisorConfig, true)
+
+	// MemoryPath is a qemu requirement, checked by the hypervisor
 	hypervisorConfig.MemoryPath = ""
+	testHypervisorConfigValid(t, hypervisorConfig, true)
+}
+
+func TestHypervisorConfigValidCheckpointConfig(t *testing.T) {
+	hypervisorConfig := &HypervisorConfig{
+		KernelPath:         fmt.Sprintf("%s/%s", testDir, testKernel),
+		ImagePath:          fmt.Sprintf("%s/%s", testDir, testImage),
+		HypervisorPath:     fmt.Sprintf("%s/%s", testDir, testHypervisor),
+		BootFromCheckpoint: true,
+	}
+	testHypervisorConfigValid(t, hypervisorConfig, false)
+
+	hypervisorConfig.DevicesStatePath = "foobar"
+	testHypervisorConfigValid(t, hypervisorConfig, true)
+
+	hypervisorConfig.MemoryPath = "foobar"
+	hypervisorConfig.BootFromTemplate = true
 	testHypervisorConfigValid(t, hypervisorConfig, false)
 }
 
@@ -11,6 +11,7 @@ package virtcontainers
 
 import (
 	"context"
+	"crypto/tls"
 	"syscall"
 
 	"github.com/kata-containers/runtime/virtcontainers/device/api"
@@ -56,6 +57,11 @@ func (impl *VCImpl) StopSandbox(ctx context.Context, sandboxID string, force boo
 	return StopSandbox(ctx, sandboxID, force)
 }
 
+// MigrateSandbox implements the VC function of the same name.
+func (impl *VCImpl) MigrateSandbox(ctx context.Context, sandboxID, target string, tlsConfig *tls.Config) error {
+	return MigrateSandbox(ctx, sandboxID, target, tlsConfig)
+}
+
 // RunSandbox implements the VC function of the same name.
 func (impl *VCImpl) RunSandbox(ctx context.Context, sandboxConfig SandboxConfig) (VCSandbox, error) {
 	return RunSandbox(ctx, sandboxConfig, impl.factory)
@@ -157,6 +163,11 @@ func (impl *VCImpl) RemoveInterface(ctx context.Context, sandboxID string, inf *
 	return RemoveInterface(ctx, sandboxID, inf)
 }
 
+// UpdateInterface implements the VC function of the same name.
+func (impl *VCImpl) UpdateInterface(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	return UpdateInterface(ctx, sandboxID, inf)
+}
+
 // ListInterfaces implements the VC function of the same name.
 func (impl *VCImpl) ListInterfaces(ctx context.Context, sandboxID string) ([]*vcTypes.Interface, error) {
 	return ListInterfaces(ctx, sandboxID)
@@ -172,6 +183,11 @@ func (impl *VCImpl) ListRoutes(ctx context.Context, sandboxID string) ([]*vcType
 	return ListRoutes(ctx, sandboxID)
 }
 
+// AddNeighbors implements the VC function of the same name.
+func (impl *VCImpl) AddNeighbors(ctx context.Context, sandboxID string, neighs []*vcTypes.ARPNeighbor) error {
+	return AddNeighbors(ctx, sandboxID, neighs)
+}
+
 // CleanupContaienr is used by shimv2 to stop and delete a container exclusively, once there is no container
 // in the sandbox left, do stop the sandbox and delete it. Those serial operations will be done exclusively by
 // locking the sandbox.
@@ -7,6 +7,7 @@ package virtcontainers
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
 	"syscall"
 
@@ -31,6 +32,7 @@ type VC interface {
 	StartSandbox(ctx context.Context, sandboxID string) (VCSandbox, error)
 	StatusSandbox(ctx context.Context, sandboxID string) (SandboxStatus, error)
 	StopSandbox(ctx context.Context, sandboxID string, force bool) (VCSandbox, error)
+	MigrateSandbox(ctx context.Context, sandboxID, target string, tlsConfig *tls.Config) error
 
 	CreateContainer(ctx context.Context, sandboxID string, containerConfig ContainerConfig) (VCSandbox, VCContainer, error)
 	DeleteContainer(ctx context.Context, sandboxID, containerID string) (VCContainer, error)
@@ -50,9 +52,11 @@ type VC interface {
 
 	AddInterface(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error)
 	RemoveInterface(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error)
+	UpdateInterface(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error)
 	ListInterfaces(ctx context.Context, sandboxID string) ([]*vcTypes.Interface, error)
 	UpdateRoutes(ctx context.Context, sandboxID string, routes []*vcTypes.Route) ([]*vcTypes.Route, error)
 	ListRoutes(ctx context.Context, sandboxID string) ([]*vcTypes.Route, error)
+	AddNeighbors(ctx context.Context, sandboxID string, neighs []*vcTypes.ARPNeighbor) error
 
 	CleanupContainer(ctx context.Context, sandboxID, containerID string, force bool) error
 }
@@ -72,6 +76,7 @@ type VCSandbox interface {
 	Stop(force bool) error
 	Release() error
 	Monitor() (chan error, error)
+	Events() <-chan Event
 	Delete() error
 	Status() SandboxStatus
 	CreateContainer(contConfig ContainerConfig) (VCContainer, error)
@@ -87,6 +92,7 @@ type VCSandbox interface {
 	UpdateContainer(containerID string, resources specs.LinuxResources) error
 	ProcessListContainer(containerID string, options ProcessListOptions) (ProcessList, error)
 	WaitProcess(containerID, processID string) (int32, error)
+	ProcessStats(containerID string) ([]ProcessStats, error)
 	SignalProcess(containerID, processID string, signal syscall.Signal, all bool) error
 	WinsizeProcess(containerID, processID string, height, width uint32) error
 	IOStream(containerID, processID string) (io.WriteCloser, io.Reader, io.Reader, error)
@@ -95,11 +101,17 @@ type VCSandbox interface {
 
 	AddInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error)
 	RemoveInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error)
+	UpdateInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error)
 	ListInterfaces() ([]*vcTypes.Interface, error)
 	UpdateRoutes(routes []*vcTypes.Route) ([]*vcTypes.Route, error)
 	ListRoutes() ([]*vcTypes.Route, error)
+	AddNeighbors(neighs []*vcTypes.ARPNeighbor) error
 
 	GetOOMEvent() (string, error)
+
+	Checkpoint(dir string) error
+	Migrate(target string, tlsConfig *tls.Config) error
+	RestartVM() error
 }
 
 // VCContainer is the Container interface
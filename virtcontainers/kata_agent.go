@@ -600,8 +600,15 @@ func (k *kataAgent) exec(sandbox *Sandbox, c Container, cmd types.Cmd) (*Process
 		},
 	}
 
-	return prepareAndStartShim(sandbox, k.shim, c.id, req.ExecId,
+	p, err := prepareAndStartShim(sandbox, k.shim, c.id, req.ExecId,
 		k.state.URL, "", cmd, []ns.NSType{}, enterNSList)
+	if err != nil {
+		return nil, err
+	}
+
+	p.GuestPid = k.getExecGuestPid(sandbox, &c)
+
+	return p, nil
 }
 
 func (k *kataAgent) updateInterface(ifc *vcTypes.Interface) (*vcTypes.Interface, error) {
@@ -893,6 +900,14 @@ func (k *kataAgent) startSandbox(sandbox *Sandbox) error {
 	if _, err = k.updateRoutes(routes); err != nil {
 		return err
 	}
+
+	// The guest of a VM restored from a checkpoint runs the sandbox already,
+	// only its network may have to follow the one of the restoring host, the
+	// neighbours it knew being kept.
+	if sandbox.config.HypervisorConfig.BootFromCheckpoint {
+		return nil
+	}
+
 	if err = k.addARPNeighbors(neighs); err != nil {
 		return err
 	}
@@ -1521,7 +1536,11 @@ func (k *kataAgent) createContainer(sandbox *Sandbox, c *Container) (p *Process,
 		AgentPidns:   agentPidNs,
 	}
 
-	if _, err = k.sendReq(req); err != nil {
+	// The container of a VM restored from a checkpoint exists in the guest
+	// already, only its host side needs to be set up again.
+	if sandbox.isCheckpointed(c.id) {
+		k.Logger().WithField("container", c.id).Info("container restored from checkpoint")
+	} else if _, err = k.sendReq(req); err != nil {
 		return nil, err
 	}
 
@@ -1547,8 +1566,83 @@ func (k *kataAgent) createContainer(sandbox *Sandbox, c *Container) (p *Process,
 		}
 	}
 
-	return prepareAndStartShim(sandbox, k.shim, c.id, req.ExecId,
+	p, err = prepareAndStartShim(sandbox, k.shim, c.id, req.ExecId,
 		k.state.URL, consoleURL, c.config.Cmd, createNSList, enterNSList)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sandbox.isCheckpointed(c.id) {
+		p.GuestPid = k.getGuestPid(sandbox, c)
+	}
+
+	return p, nil
+}
+
+// getGuestPid returns the PID of the process of a freshly created container
+// in the VM, 0 if it cannot be found. The agent lists the PIDs of the
+// container processes when asked for the json format, the container init
+// process being the only one until the container is started.
+//
+// This costs a ListProcesses round-trip per container creation: the agent
+// protocol is vendored from the agent repository and CreateContainer
+// returns an Empty message, so the PID cannot be returned along without a
+// protocol change there, which the agents already in the guest images
+// would not implement anyway.
+func (k *kataAgent) getGuestPid(sandbox *Sandbox, c *Container) int {
+	processList, err := k.processListContainer(sandbox, *c, ProcessListOptions{Format: "json"})
+	if err != nil {
+		k.Logger().WithError(err).WithField("container", c.id).Warn("Could not list container processes")
+		return 0
+	}
+
+	var pids []int
+	if err := json.Unmarshal(processList, &pids); err != nil || len(pids) != 1 {
+		k.Logger().WithError(err).WithFields(logrus.Fields{
+			"container": c.id,
+			"pids":      pids,
+		}).Warn("Could not find container process in the VM")
+		return 0
+	}
+
+	return pids[0]
+}
+
+// getExecGuestPid returns the PID in the VM of the process just executed in
+// a container, 0 if it cannot be found. The container init process and the
+// executed processes are started by the agent, unlike their descendants
+// their parent is not a container process. The executed process is the one
+// of them that is neither the container init process nor a process
+// executed before.
+func (k *kataAgent) getExecGuestPid(sandbox *Sandbox, c *Container) int {
+	stats, err := listProcessStats(sandbox, c)
+	if err != nil {
+		k.Logger().WithError(err).WithField("container", c.id).Debug("Could not list container processes")
+		return 0
+	}
+
+	pids := make(map[int]bool, len(stats))
+	for _, st := range stats {
+		pids[st.GuestPid] = true
+	}
+
+	var newPids []int
+	for _, st := range stats {
+		if pids[st.GuestPPid] || st.GuestPid == c.process.GuestPid || sandbox.isExecGuestPid(st.GuestPid) {
+			continue
+		}
+		newPids = append(newPids, st.GuestPid)
+	}
+
+	if len(newPids) != 1 {
+		k.Logger().WithFields(logrus.Fields{
+			"container": c.id,
+			"pids":      newPids,
+		}).Debug("Could not find executed process in the VM")
+		return 0
+	}
+
+	return newPids[0]
 }
 
 // handleEphemeralStorage handles ephemeral storages by
@@ -1120,6 +1120,60 @@ func TestAgentCreateContainer(t *testing.T) {
 	assert.Error(err)
 }
 
+type gRPCProxyGuestPid struct {
+	gRPCProxy
+	pids []byte
+}
+
+func (p *gRPCProxyGuestPid) ListProcesses(ctx context.Context, req *pb.ListProcessesRequest) (*pb.ListProcessesResponse, error) {
+	if req.Format != "json" {
+		return &pb.ListProcessesResponse{}, nil
+	}
+	return &pb.ListProcessesResponse{ProcessList: p.pids}, nil
+}
+
+func TestAgentGetGuestPid(t *testing.T) {
+	assert := assert.New(t)
+
+	impl := &gRPCProxyGuestPid{}
+
+	proxy := mock.ProxyGRPCMock{
+		GRPCImplementer: impl,
+		GRPCRegister: func(s *grpc.Server, srv interface{}) {
+			pb.RegisterAgentServiceServer(s, impl)
+			pb.RegisterHealthServer(s, impl)
+		},
+	}
+
+	sockDir, err := testGenerateKataProxySockDir()
+	assert.NoError(err)
+	defer os.RemoveAll(sockDir)
+
+	testKataProxyURL := fmt.Sprintf(testKataProxyURLTempl, sockDir)
+	assert.NoError(proxy.Start(testKataProxyURL))
+	defer proxy.Stop()
+
+	k := &kataAgent{
+		ctx: context.Background(),
+		state: KataAgentState{
+			URL: testKataProxyURL,
+		},
+	}
+
+	sandbox := &Sandbox{ctx: context.Background()}
+	c := &Container{id: "barfoo"}
+
+	impl.pids = []byte("[42]")
+	assert.Equal(42, k.getGuestPid(sandbox, c))
+
+	// the container process cannot be told apart from the others
+	impl.pids = []byte("[42,43]")
+	assert.Equal(0, k.getGuestPid(sandbox, c))
+
+	impl.pids = []byte("foo")
+	assert.Equal(0, k.getGuestPid(sandbox, c))
+}
+
 func TestAgentNetworkOperation(t *testing.T) {
 	assert := assert.New(t)
 
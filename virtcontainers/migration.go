@@ -0,0 +1,362 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A sandbox migrates over a single connection between the source and the
+// destination runtimes, carrying messages made of a one byte type and a
+// four bytes big endian payload length, followed by the payload:
+//
+//	source                            destination
+//	  | -- migrationMsgVMState (xN) --> |  VM state, as streamed by the hypervisor
+//	  | -- migrationMsgState ---------> |  sandbox and containers persisted states
+//	  | <-- migrationMsgResult -------- |  empty on success, the error otherwise
+//
+// The destination stores what it receives as a checkpoint, the sandbox
+// being restored from it.
+const (
+	migrationMsgVMState byte = iota + 1
+	migrationMsgState
+	migrationMsgResult
+)
+
+const (
+	// migrationMsgHeaderSize is the size of the type and length of a
+	// migration message.
+	migrationMsgHeaderSize = 5
+
+	// migrationMsgMaxSize bounds the payload of a migration message.
+	migrationMsgMaxSize = 64 << 20
+
+	// migrationChunkSize is the size of the VM state chunks sent by the
+	// source.
+	migrationChunkSize = 1 << 20
+
+	// migrationSocket is the unix socket the hypervisor streams the VM
+	// state to on the source.
+	migrationSocket = "migration.sock"
+)
+
+// ParseMigrationURI splits a migration URI, unix:<path> or
+// tcp:<host>:<port>, into the network and address to dial or listen on.
+func ParseMigrationURI(uri string) (string, string, error) {
+	parts := strings.SplitN(uri, ":", 2)
+	if len(parts) == 2 && parts[1] != "" {
+		switch parts[0] {
+		case "unix", "tcp":
+			return parts[0], parts[1], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("Invalid migration URI %q, expecting unix:<path> or tcp:<host>:<port>", uri)
+}
+
+// dialMigration connects to the destination runtime listening on uri. The
+// sandbox state is not sent over plain TCP, tlsConfig is required to dial
+// a tcp URI.
+func dialMigration(uri string, tlsConfig *tls.Config) (net.Conn, error) {
+	network, address, err := ParseMigrationURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == "unix" {
+		return net.Dial(network, address)
+	}
+
+	if tlsConfig == nil {
+		return nil, fmt.Errorf("Migration over %s requires a TLS configuration", uri)
+	}
+
+	return tls.Dial(network, address, tlsConfig)
+}
+
+// ListenMigration listens for a sandbox migrated by Sandbox.Migrate on uri.
+// A unix socket is only accessible to the runtime user, a tcp URI requires
+// tlsConfig to authenticate the source with a client certificate.
+func ListenMigration(uri string, tlsConfig *tls.Config) (net.Listener, error) {
+	network, address, err := ParseMigrationURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == "unix" {
+		l, err := net.Listen(network, address)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.Chmod(address, 0600); err != nil {
+			l.Close()
+			return nil, err
+		}
+
+		return l, nil
+	}
+
+	if tlsConfig == nil || tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		return nil, fmt.Errorf("Migration over %s requires a TLS configuration verifying client certificates", uri)
+	}
+
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return tls.NewListener(l, tlsConfig), nil
+}
+
+func writeMigrationMsg(w io.Writer, msgType byte, payload []byte) error {
+	header := make([]byte, migrationMsgHeaderSize)
+	header[0] = msgType
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	_, err := w.Write(payload)
+	return err
+}
+
+func readMigrationMsg(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, migrationMsgHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > migrationMsgMaxSize {
+		return 0, nil, fmt.Errorf("Migration message too large: %d bytes", size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return header[0], payload, nil
+}
+
+// streamMigrationVMState forwards the VM state the hypervisor writes on
+// the first connection to l, to w. l is closed once that connection is
+// accepted.
+func streamMigrationVMState(l net.Listener, w io.Writer) error {
+	conn, err := l.Accept()
+	l.Close()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, migrationChunkSize)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if err := writeMigrationMsg(w, migrationMsgVMState, buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Migrate live migrates the sandbox to the destination runtime listening
+// on target, see ParseMigrationURI. The VM state is streamed while the VM
+// runs, then the sandbox and containers persisted states are sent and the
+// destination restores the sandbox from them, the way it would from a
+// checkpoint. On success the sandbox VM is stopped on this host, it is
+// resumed if the destination failed to restore the sandbox. tlsConfig is
+// required to migrate over tcp, see ListenMigration.
+func (s *Sandbox) Migrate(target string, tlsConfig *tls.Config) (err error) {
+	span, _ := s.trace("migrate")
+	defer span.Finish()
+
+	if err := s.checkVMStateSave("migrate"); err != nil {
+		return err
+	}
+
+	caps := s.hypervisor.capabilities()
+	if !caps.IsMigrationSupported() {
+		return fmt.Errorf("Hypervisor %s does not support live migration", s.config.HypervisorType)
+	}
+
+	conn, err := dialMigration(target, tlsConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The VM is paused while its state is sent, and the agent
+	// disconnected, which the monitor must not take for the sandbox
+	// being unhealthy.
+	if s.monitor != nil {
+		s.monitor.suspend()
+		defer s.monitor.resume()
+	}
+
+	dir, err := ioutil.TempDir("", "kata-migration")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	sock := filepath.Join(dir, migrationSocket)
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		return err
+	}
+
+	streamed := make(chan error, 1)
+	go func() {
+		streamed <- streamMigrationVMState(l, conn)
+	}()
+
+	// The agent connection is tied to this VM instance, the destination
+	// establishes its own.
+	if err := s.agent.disconnect(); err != nil {
+		s.Logger().WithError(err).Warn("failed to disconnect agent before migration")
+	}
+
+	if err := s.hypervisor.migrateSandbox("unix:" + sock); err != nil {
+		l.Close()
+		<-streamed
+		return err
+	}
+
+	// The VM is stopped from now on, and must be resumed unless the
+	// destination might be running it.
+	resume := true
+	defer func() {
+		if !resume {
+			return
+		}
+		if resumeErr := s.hypervisor.resumeSandbox(); resumeErr != nil {
+			s.Logger().WithError(resumeErr).Error("failed to resume sandbox after migration")
+		}
+	}()
+
+	if err := <-streamed; err != nil {
+		return err
+	}
+
+	ss, cs := s.dump()
+	data, err := json.Marshal(checkpointState{
+		Sandbox:    ss,
+		Containers: cs,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeMigrationMsg(conn, migrationMsgState, data); err != nil {
+		return err
+	}
+
+	msgType, result, err := readMigrationMsg(conn)
+	if err == nil && msgType != migrationMsgResult {
+		err = fmt.Errorf("unexpected message type %d", msgType)
+	}
+	if err != nil {
+		// The destination might be running the sandbox already.
+		resume = false
+		return fmt.Errorf("Could not get the migration outcome from %s, sandbox left paused: %v", target, err)
+	}
+
+	if len(result) > 0 {
+		return fmt.Errorf("Destination %s failed to restore sandbox: %s", target, result)
+	}
+
+	resume = false
+	s.Logger().WithField("target", target).Info("Sandbox migrated")
+
+	return s.hypervisor.stopSandbox()
+}
+
+// releaseMigrated removes what is left on this host of a sandbox migrated
+// by Sandbox.Migrate. The VM was stopped once migrated and the agent went
+// along with it, the agent is marked dead so that the containers and the
+// sandbox are only torn down on the host, without waiting for it.
+func (s *Sandbox) releaseMigrated() error {
+	s.agent.markDead()
+
+	if err := s.Stop(true); err != nil {
+		return err
+	}
+
+	return s.Delete()
+}
+
+// ReceiveMigration receives a sandbox migrated by Sandbox.Migrate from
+// conn, saving it as a checkpoint in dir, and calls restore to restore it
+// from there. The restore outcome is reported to the source.
+func ReceiveMigration(conn io.ReadWriter, dir string, restore func(dir string) error) error {
+	if err := os.MkdirAll(dir, DirMode); err != nil {
+		return err
+	}
+
+	if err := receiveMigrationState(conn, dir); err != nil {
+		return err
+	}
+
+	var result []byte
+	restoreErr := restore(dir)
+	if restoreErr != nil {
+		result = []byte(restoreErr.Error())
+	}
+
+	if err := writeMigrationMsg(conn, migrationMsgResult, result); err != nil {
+		return err
+	}
+
+	return restoreErr
+}
+
+func receiveMigrationState(r io.Reader, dir string) error {
+	f, err := os.OpenFile(CheckpointVMStatePath(dir), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for {
+		msgType, payload, err := readMigrationMsg(r)
+		if err != nil {
+			return err
+		}
+
+		switch msgType {
+		case migrationMsgVMState:
+			if _, err := f.Write(payload); err != nil {
+				return err
+			}
+		case migrationMsgState:
+			if err := f.Sync(); err != nil {
+				return err
+			}
+			return ioutil.WriteFile(filepath.Join(dir, checkpointStateFile), payload, 0600)
+		default:
+			return fmt.Errorf("Unexpected migration message type %d", msgType)
+		}
+	}
+}
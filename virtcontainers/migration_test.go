@@ -0,0 +1,244 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/kata-containers/runtime/virtcontainers/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMigrationURI(t *testing.T) {
+	assert := assert.New(t)
+
+	network, address, err := ParseMigrationURI("unix:/run/kata/migration.sock")
+	assert.NoError(err)
+	assert.Equal("unix", network)
+	assert.Equal("/run/kata/migration.sock", address)
+
+	network, address, err = ParseMigrationURI("tcp:10.0.0.2:4444")
+	assert.NoError(err)
+	assert.Equal("tcp", network)
+	assert.Equal("10.0.0.2:4444", address)
+
+	for _, uri := range []string{"", "unix", "unix:", "exec:cat", "/run/kata/migration.sock"} {
+		_, _, err = ParseMigrationURI(uri)
+		assert.Error(err, uri)
+	}
+}
+
+func TestMigrationMsg(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	err := writeMigrationMsg(&buf, migrationMsgVMState, []byte("foo"))
+	assert.NoError(err)
+	err = writeMigrationMsg(&buf, migrationMsgResult, nil)
+	assert.NoError(err)
+
+	msgType, payload, err := readMigrationMsg(&buf)
+	assert.NoError(err)
+	assert.Equal(migrationMsgVMState, msgType)
+	assert.Equal([]byte("foo"), payload)
+
+	msgType, payload, err = readMigrationMsg(&buf)
+	assert.NoError(err)
+	assert.Equal(migrationMsgResult, msgType)
+	assert.Empty(payload)
+
+	_, _, err = readMigrationMsg(&buf)
+	assert.Error(err)
+
+	// truncated payload
+	err = writeMigrationMsg(&buf, migrationMsgState, []byte("foo"))
+	assert.NoError(err)
+	buf.Truncate(buf.Len() - 1)
+	_, _, err = readMigrationMsg(&buf)
+	assert.Error(err)
+}
+
+func TestSandboxMigrate(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, nil, nil)
+	assert.NoError(err)
+	defer cleanUp()
+
+	dir, err := ioutil.TempDir("", "migration")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	target := "unix:" + filepath.Join(dir, "target.sock")
+	l, err := ListenMigration(target, nil)
+	assert.NoError(err)
+	defer l.Close()
+
+	// sandbox is not running yet
+	err = s.Migrate(target, nil)
+	assert.Error(err)
+
+	err = s.Start()
+	assert.NoError(err)
+
+	contID := "999"
+	_, err = s.CreateContainer(newTestContainerConfigNoop(contID))
+	assert.NoError(err)
+
+	received := make(chan error, 1)
+	receive := func(restoreErr error) {
+		conn, err := l.Accept()
+		if err != nil {
+			received <- err
+			return
+		}
+		defer conn.Close()
+
+		received <- ReceiveMigration(conn, filepath.Join(dir, "checkpoint"), func(cpDir string) error {
+			vmState, err := ioutil.ReadFile(CheckpointVMStatePath(cpDir))
+			if err != nil {
+				return err
+			}
+			if string(vmState) != mockMigrationState {
+				return errors.New("unexpected VM state")
+			}
+
+			config, err := LoadCheckpointConfig(cpDir)
+			if err != nil {
+				return err
+			}
+			if config.ID != testSandboxID {
+				return errors.New("unexpected sandbox ID")
+			}
+
+			restored := &Sandbox{id: testSandboxID}
+			if err := restored.loadCheckpoint(cpDir); err != nil {
+				return err
+			}
+			if !restored.isCheckpointed(contID) {
+				return errors.New("container not checkpointed")
+			}
+
+			return restoreErr
+		})
+	}
+
+	// the destination fails to restore the sandbox
+	go receive(errors.New("restore failure"))
+	err = s.Migrate(target, nil)
+	assert.Error(err)
+	assert.Contains(err.Error(), "restore failure")
+	assert.Error(<-received)
+
+	go receive(nil)
+	err = s.Migrate(target, nil)
+	assert.NoError(err)
+	assert.NoError(<-received)
+	assert.Equal(types.StateRunning, s.state.State)
+
+	// the migrated sandbox is removed without the agent
+	agent := &migratedTestAgent{}
+	s.agent = agent
+	err = s.releaseMigrated()
+	assert.NoError(err)
+	assert.True(agent.dead)
+	assert.Empty(agent.calls)
+	assert.Equal(types.StateStopped, s.state.State)
+	assert.Empty(s.containers)
+}
+
+// migratedTestAgent records the calls of the container and sandbox
+// operations made before it is marked dead.
+type migratedTestAgent struct {
+	noopAgent
+	dead  bool
+	calls []string
+}
+
+func (a *migratedTestAgent) record(call string) error {
+	if a.dead {
+		return errors.New("Dead agent")
+	}
+	a.calls = append(a.calls, call)
+	return nil
+}
+
+func (a *migratedTestAgent) markDead() {
+	a.dead = true
+}
+
+func (a *migratedTestAgent) stopContainer(sandbox *Sandbox, c Container) error {
+	return a.record("stopContainer")
+}
+
+func (a *migratedTestAgent) signalProcess(c *Container, processID string, signal syscall.Signal, all bool) error {
+	return a.record("signalProcess")
+}
+
+func (a *migratedTestAgent) stopSandbox(sandbox *Sandbox) error {
+	return a.record("stopSandbox")
+}
+
+func TestSandboxMigrateNoDestination(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, nil, nil)
+	assert.NoError(err)
+	defer cleanUp()
+
+	err = s.Start()
+	assert.NoError(err)
+
+	err = s.Migrate("foo", nil)
+	assert.Error(err)
+
+	// plain TCP is not allowed
+	err = s.Migrate("tcp:localhost:4444", nil)
+	assert.Error(err)
+	assert.Contains(err.Error(), "TLS")
+
+	dir, err := ioutil.TempDir("", "migration")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	err = s.Migrate("unix:"+filepath.Join(dir, "target.sock"), nil)
+	assert.Error(err)
+}
+
+func TestListenMigration(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "migration")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	_, err = ListenMigration("foo", nil)
+	assert.Error(err)
+
+	sock := filepath.Join(dir, "target.sock")
+	l, err := ListenMigration("unix:"+sock, nil)
+	assert.NoError(err)
+	defer l.Close()
+
+	fi, err := os.Stat(sock)
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0600), fi.Mode().Perm())
+
+	// plain TCP is not allowed
+	_, err = ListenMigration("tcp:localhost:0", nil)
+	assert.Error(err)
+
+	// nor TLS without client authentication
+	_, err = ListenMigration("tcp:localhost:0", &tls.Config{})
+	assert.Error(err)
+}
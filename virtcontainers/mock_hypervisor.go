@@ -8,18 +8,35 @@ package virtcontainers
 import (
 	"context"
 	"errors"
+	"net"
 	"os"
+	"strings"
 
 	persistapi "github.com/kata-containers/runtime/virtcontainers/persist/api"
 	"github.com/kata-containers/runtime/virtcontainers/types"
 )
 
+// mockMigrationState is the VM state sent by the mock hypervisor live migration.
+const mockMigrationState = "mock vm state"
+
 type mockHypervisor struct {
 	mockPid int
 }
 
 func (m *mockHypervisor) capabilities() types.Capabilities {
-	return types.Capabilities{}
+	var caps types.Capabilities
+	caps.SetSnapshotSupport()
+	caps.SetMigrationSupport()
+	caps.SetTemplateSupport()
+	return caps
+}
+
+func (m *mockHypervisor) checkTemplateConfig(conf *HypervisorConfig) error {
+	return nil
+}
+
+func (m *mockHypervisor) templateFiles(conf *HypervisorConfig) []string {
+	return []string{conf.MemoryPath, conf.DevicesStatePath}
 }
 
 func (m *mockHypervisor) hypervisorConfig() HypervisorConfig {
@@ -51,10 +68,22 @@ func (m *mockHypervisor) resumeSandbox() error {
 	return nil
 }
 
-func (m *mockHypervisor) saveSandbox() error {
+func (m *mockHypervisor) saveSandbox(statePath string) error {
 	return nil
 }
 
+// migrateSandbox streams a fake VM state to the unix socket uri.
+func (m *mockHypervisor) migrateSandbox(uri string) error {
+	conn, err := net.Dial("unix", strings.TrimPrefix(uri, "unix:"))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(mockMigrationState))
+	return err
+}
+
 func (m *mockHypervisor) addDevice(devInfo interface{}, devType deviceType) error {
 	return nil
 }
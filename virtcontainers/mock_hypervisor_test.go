@@ -74,7 +74,7 @@ func TestMockHypervisorGetSandboxConsole(t *testing.T) {
 func TestMockHypervisorSaveSandbox(t *testing.T) {
 	var m *mockHypervisor
 
-	assert.NoError(t, m.saveSandbox())
+	assert.NoError(t, m.saveSandbox(""))
 }
 
 func TestMockHypervisorDisconnect(t *testing.T) {
@@ -6,10 +6,12 @@
 package virtcontainers
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -17,21 +19,140 @@ const (
 	watcherChannelSize          = 128
 )
 
+// MonitorAction is what the monitor watchers are asked to do when the
+// sandbox is found unhealthy.
+type MonitorAction string
+
+const (
+	// MonitorActionStop stops and deletes the sandbox.
+	MonitorActionStop MonitorAction = "stop"
+
+	// MonitorActionRestartVM restarts the sandbox VM.
+	MonitorActionRestartVM MonitorAction = "restart-vm"
+
+	// MonitorActionReport only reports the failure.
+	MonitorActionReport MonitorAction = "report"
+)
+
+// SetAction sets the monitor action from its name.
+func (a *MonitorAction) SetAction(name string) error {
+	switch action := MonitorAction(name); action {
+	case MonitorActionStop, MonitorActionRestartVM, MonitorActionReport:
+		*a = action
+		return nil
+	}
+	return fmt.Errorf("Unknown monitor action %s", name)
+}
+
+// MonitorConfig is the sandbox health-check policy of the monitor.
+type MonitorConfig struct {
+	// Interval is the time between two health checks,
+	// DefaultMonitorCheckInterval if not set.
+	Interval time.Duration
+
+	// FailureThreshold is the number of consecutive failed health
+	// checks after which the watchers are notified, 1 if not set.
+	FailureThreshold uint32
+
+	// GracePeriod is the time after the monitor starts, or after the
+	// sandbox VM got restarted, during which no health check is done.
+	GracePeriod time.Duration
+
+	// Action is the action the watchers are asked to take,
+	// MonitorActionStop if not set.
+	Action MonitorAction
+
+	// Restartable makes the watchers restart the sandbox VM as soon as
+	// the hypervisor process is found dead, whatever Action is.
+	Restartable bool
+}
+
+func (c *MonitorConfig) valid() error {
+	if c.Interval < 0 {
+		return fmt.Errorf("Invalid monitor interval %v", c.Interval)
+	}
+
+	if c.GracePeriod < 0 {
+		return fmt.Errorf("Invalid monitor grace period %v", c.GracePeriod)
+	}
+
+	if c.Action != "" {
+		var action MonitorAction
+		return action.SetAction(string(c.Action))
+	}
+
+	return nil
+}
+
+func (c *MonitorConfig) interval() time.Duration {
+	if c.Interval == 0 {
+		return DefaultMonitorCheckInterval
+	}
+	return c.Interval
+}
+
+func (c *MonitorConfig) failureThreshold() uint32 {
+	if c.FailureThreshold == 0 {
+		return 1
+	}
+	return c.FailureThreshold
+}
+
+func (c *MonitorConfig) action() MonitorAction {
+	if c.Action == "" {
+		return MonitorActionStop
+	}
+	return c.Action
+}
+
+// MonitorError is the error the monitor watchers get notified with. It
+// holds the health-check failure and the action the watcher is asked to
+// take.
+type MonitorError struct {
+	Action MonitorAction
+	Err    error
+}
+
+func (e *MonitorError) Error() string {
+	return e.Err.Error()
+}
+
 type monitor struct {
 	sync.Mutex
 
 	sandbox       *Sandbox
 	checkInterval time.Duration
+	threshold     uint32
+	gracePeriod   time.Duration
+	action        MonitorAction
+	restartable   bool
 	watchers      []chan error
 	wg            sync.WaitGroup
 	running       bool
 	stopCh        chan bool
+
+	// checkLock serializes the health checks with suspend/resume.
+	checkLock sync.Mutex
+	suspended bool
+	// failures counts the consecutive failed health checks.
+	failures uint32
+	// notified is set once the watchers were asked to stop or restart
+	// the sandbox, no more health check is done until resume.
+	notified bool
+	// since is when the grace period started.
+	since time.Time
 }
 
 func newMonitor(s *Sandbox) *monitor {
+	config := s.config.MonitorConfig
+
 	return &monitor{
 		sandbox:       s,
-		checkInterval: DefaultMonitorCheckInterval,
+		checkInterval: config.interval(),
+		threshold:     config.failureThreshold(),
+		gracePeriod:   config.GracePeriod,
+		action:        config.action(),
+		restartable:   config.Restartable,
 		stopCh:        make(chan bool, 1),
 	}
 }
@@ -45,6 +166,7 @@ func (m *monitor) newWatcher() (chan error, error) {
 
 	if !m.running {
 		m.running = true
+		m.since = time.Now()
 		m.wg.Add(1)
 
 		// create and start agent watcher
@@ -57,8 +179,7 @@ func (m *monitor) newWatcher() (chan error, error) {
 					m.wg.Done()
 					return
 				case <-tick.C:
-					m.watchHypervisor()
-					m.watchAgent()
+					m.check()
 				}
 			}
 		}()
@@ -68,8 +189,6 @@ func (m *monitor) newWatcher() (chan error, error) {
 }
 
 func (m *monitor) notify(err error) {
-	m.sandbox.agent.markDead()
-
 	m.Lock()
 	defer m.Unlock()
 
@@ -127,18 +246,96 @@ func (m *monitor) stop() {
 	}
 }
 
-func (m *monitor) watchAgent() {
-	err := m.sandbox.agent.check()
-	if err != nil {
-		// TODO: define and export error types
-		m.notify(errors.Wrapf(err, "failed to ping agent"))
+// check runs a health check of the sandbox and notifies the watchers
+// once the failure threshold is reached.
+func (m *monitor) check() {
+	m.checkLock.Lock()
+	defer m.checkLock.Unlock()
+
+	if m.suspended || m.notified {
+		return
+	}
+
+	// A dead hypervisor process of a restartable sandbox is not subject
+	// to the grace period nor to the failure threshold, there is nothing
+	// to wait for.
+	if m.restartable {
+		if err := m.watchHypervisor(); err != nil {
+			m.sandbox.agent.markDead()
+			m.notified = true
+			m.notify(&MonitorError{
+				Action: MonitorActionRestartVM,
+				Err:    err,
+			})
+			return
+		}
+	}
+
+	if time.Since(m.since) < m.gracePeriod {
+		return
+	}
+
+	err := m.watchHypervisor()
+	if err == nil {
+		err = m.watchAgent()
+	}
+	if err == nil {
+		m.failures = 0
+		return
+	}
+
+	m.failures++
+	virtLog.WithError(err).WithFields(logrus.Fields{
+		"failures":  m.failures,
+		"threshold": m.threshold,
+	}).Warn("sandbox health check failed")
+
+	if m.failures < m.threshold {
+		return
+	}
+	m.failures = 0
+
+	if m.action != MonitorActionReport {
+		m.sandbox.agent.markDead()
+		m.notified = true
+	}
+
+	m.notify(&MonitorError{
+		Action: m.action,
+		Err:    err,
+	})
+}
+
+// suspend stops the health checks until resume is called, waiting for
+// the one in progress if any.
+func (m *monitor) suspend() {
+	m.checkLock.Lock()
+	defer m.checkLock.Unlock()
+
+	m.suspended = true
+}
+
+// resume starts the health checks again, after a new grace period.
+func (m *monitor) resume() {
+	m.checkLock.Lock()
+	defer m.checkLock.Unlock()
+
+	m.suspended = false
+	m.notified = false
+	m.failures = 0
+	m.since = time.Now()
+}
+
+func (m *monitor) watchAgent() error {
+	if err := m.sandbox.agent.check(); err != nil {
+		return errors.Wrapf(err, "failed to ping agent")
 	}
+	return nil
 }
 
 func (m *monitor) watchHypervisor() error {
 	if err := m.sandbox.hypervisor.check(); err != nil {
-		m.notify(errors.Wrapf(err, "failed to ping hypervisor process"))
-		return err
+		return errors.Wrapf(err, "failed to ping hypervisor process")
 	}
 	return nil
 }
@@ -6,9 +6,10 @@
 package virtcontainers
 
 import (
-	"errors"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,3 +59,187 @@ func TestMonitorClosedChannel(t *testing.T) {
 
 	m.stop()
 }
+
+type monitorTestAgent struct {
+	noopAgent
+	checkErr error
+	dead     bool
+}
+
+func (a *monitorTestAgent) check() error {
+	return a.checkErr
+}
+
+func (a *monitorTestAgent) markDead() {
+	a.dead = true
+}
+
+type monitorTestHypervisor struct {
+	mockHypervisor
+	checkErr error
+}
+
+func (h *monitorTestHypervisor) check() error {
+	return h.checkErr
+}
+
+func TestMonitorConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	config := MonitorConfig{}
+	assert.NoError(config.valid())
+	assert.Equal(DefaultMonitorCheckInterval, config.interval())
+	assert.Equal(uint32(1), config.failureThreshold())
+	assert.Equal(MonitorActionStop, config.action())
+
+	config = MonitorConfig{
+		Interval:         5 * time.Second,
+		FailureThreshold: 3,
+		Action:           MonitorActionReport,
+	}
+	assert.NoError(config.valid())
+	assert.Equal(5*time.Second, config.interval())
+	assert.Equal(uint32(3), config.failureThreshold())
+	assert.Equal(MonitorActionReport, config.action())
+
+	for _, config := range []MonitorConfig{
+		{Interval: -time.Second},
+		{GracePeriod: -time.Second},
+		{Action: "foo"},
+	} {
+		assert.Error(config.valid())
+	}
+
+	var action MonitorAction
+	assert.NoError(action.SetAction("restart-vm"))
+	assert.Equal(MonitorActionRestartVM, action)
+	assert.Error(action.SetAction("foo"))
+	assert.Equal(MonitorActionRestartVM, action)
+}
+
+func TestMonitorCheckPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, nil, nil)
+	assert.NoError(err)
+	defer cleanUp()
+
+	agent := &monitorTestAgent{}
+	s.agent = agent
+
+	s.config.MonitorConfig = MonitorConfig{
+		Interval:         time.Hour,
+		FailureThreshold: 2,
+		GracePeriod:      time.Hour,
+		Action:           MonitorActionReport,
+	}
+
+	m := newMonitor(s)
+	ch, err := m.newWatcher()
+	assert.NoError(err)
+	defer m.stop()
+
+	agent.checkErr = errors.New("agent check failure")
+
+	// within the grace period
+	m.check()
+	assert.Equal(uint32(0), m.failures)
+
+	m.since = time.Now().Add(-time.Hour)
+
+	// a failure followed by a success does not reach the threshold
+	m.check()
+	assert.Equal(uint32(1), m.failures)
+	agent.checkErr = nil
+	m.check()
+	assert.Equal(uint32(0), m.failures)
+	assert.Len(ch, 0)
+
+	agent.checkErr = errors.New("agent check failure")
+	m.check()
+	m.check()
+	assert.Len(ch, 1)
+	merr, ok := (<-ch).(*MonitorError)
+	assert.True(ok)
+	assert.Equal(MonitorActionReport, merr.Action)
+	assert.Equal(agent.checkErr, errors.Cause(merr.Err))
+	assert.False(agent.dead)
+
+	// reporting does not stop the health checks
+	m.check()
+	m.check()
+	assert.Len(ch, 1)
+	<-ch
+
+	// stopping or restarting the sandbox does, until resumed
+	m.action = MonitorActionStop
+	m.check()
+	m.check()
+	assert.Len(ch, 1)
+	merr, ok = (<-ch).(*MonitorError)
+	assert.True(ok)
+	assert.Equal(MonitorActionStop, merr.Action)
+	assert.True(agent.dead)
+
+	m.check()
+	m.check()
+	assert.Len(ch, 0)
+
+	m.resume()
+	assert.False(m.notified)
+	m.since = time.Now().Add(-time.Hour)
+
+	m.suspend()
+	m.check()
+	assert.Equal(uint32(0), m.failures)
+	m.resume()
+	m.since = time.Now().Add(-time.Hour)
+
+	m.check()
+	assert.Equal(uint32(1), m.failures)
+}
+
+func TestMonitorCheckRestartable(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, nil, nil)
+	assert.NoError(err)
+	defer cleanUp()
+
+	agent := &monitorTestAgent{}
+	s.agent = agent
+	hypervisor := &monitorTestHypervisor{}
+	s.hypervisor = hypervisor
+
+	s.config.MonitorConfig = MonitorConfig{
+		Interval:         time.Hour,
+		FailureThreshold: 3,
+		GracePeriod:      time.Hour,
+		Action:           MonitorActionReport,
+		Restartable:      true,
+	}
+
+	m := newMonitor(s)
+	ch, err := m.newWatcher()
+	assert.NoError(err)
+	defer m.stop()
+
+	// agent failures follow the policy
+	agent.checkErr = errors.New("agent check failure")
+	m.check()
+	assert.Len(ch, 0)
+	assert.Equal(uint32(0), m.failures)
+
+	// a dead hypervisor does not wait for the grace period nor the threshold
+	hypervisor.checkErr = errors.New("hypervisor check failure")
+	m.check()
+	assert.Len(ch, 1)
+	merr, ok := (<-ch).(*MonitorError)
+	assert.True(ok)
+	assert.Equal(MonitorActionRestartVM, merr.Action)
+	assert.Equal(hypervisor.checkErr, errors.Cause(merr.Err))
+	assert.True(agent.dead)
+
+	m.check()
+	assert.Len(ch, 0)
+}
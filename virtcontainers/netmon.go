@@ -19,6 +19,16 @@ type NetmonConfig struct {
 	Path   string
 	Debug  bool
 	Enable bool
+
+	// InProcess is set when the caller of virtcontainers watches the
+	// network namespace itself, in which case the sandbox does not start
+	// the network monitor binary.
+	InProcess bool
+}
+
+// external returns whether the sandbox runs the network monitor binary.
+func (c NetmonConfig) external() bool {
+	return c.Enable && !c.InProcess
 }
 
 // netmonParams is the structure providing specific parameters needed
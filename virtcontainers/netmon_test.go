@@ -59,3 +59,11 @@ func TestStopNetmon(t *testing.T) {
 	err := stopNetmon(pid)
 	assert.Nil(t, err)
 }
+
+func TestNetmonConfigExternal(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(NetmonConfig{}.external())
+	assert.True(NetmonConfig{Enable: true}.external())
+	assert.False(NetmonConfig{Enable: true, InProcess: true}.external())
+}
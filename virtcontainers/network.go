@@ -51,6 +51,12 @@ const (
 	// NetXConnectNoneModel can be used when the VM is in the host network namespace
 	NetXConnectNoneModel
 
+	// NetXConnectBridgedModel connects the network interface provided by
+	// the network plugin to a tap interface through a Linux bridge. This
+	// works when the interface traffic can't be redirected with tc, e.g.
+	// with XDP programs attached to it.
+	NetXConnectBridgedModel
+
 	// NetXConnectInvalidModel is the last item to check valid values by IsValid()
 	NetXConnectInvalidModel
 )
@@ -68,6 +74,8 @@ const (
 	tcFilterNetModelStr = "tcfilter"
 
 	noneNetModelStr = "none"
+
+	bridgedNetModelStr = "bridged"
 )
 
 //SetModel change the model string value
@@ -85,6 +93,9 @@ func (n *NetInterworkingModel) SetModel(modelName string) error {
 	case noneNetModelStr:
 		*n = NetXConnectNoneModel
 		return nil
+	case bridgedNetModelStr:
+		*n = NetXConnectBridgedModel
+		return nil
 	}
 	return fmt.Errorf("Unknown type %s", modelName)
 }
@@ -151,6 +162,68 @@ type NetworkInterfacePair struct {
 	TapInterface
 	VirtIface NetworkInterface
 	NetInterworkingModel
+
+	// Bridge is the name of the Linux bridge connecting VirtIface and
+	// TAPIface with the bridged interworking model.
+	Bridge string
+
+	// Config is the configuration of the VM network device.
+	Config NetInterfaceConfig
+
+	// Policy is the egress allow-list enforced on the TAP, if any.
+	Policy *NetworkPolicy
+}
+
+// NetInterfaceConfig is the configuration of the VM network device of a
+// network interface, the defaults being used for its zero fields.
+type NetInterfaceConfig struct {
+	// Queues is the number of queue pairs of the device, the number of
+	// vCPUs by default. It is only used when the hypervisor supports
+	// multiqueue.
+	Queues uint32
+
+	// MTU overrides the MTU of the network interface for the TAP and the
+	// guest interface.
+	MTU uint32
+
+	// RxQueueSize and TxQueueSize are the sizes of the device virtqueues.
+	RxQueueSize uint32
+	TxQueueSize uint32
+
+	// DisableVhostNet disables vhost-net for the device.
+	DisableVhostNet bool
+
+	// DisableChecksum, DisableTSO and DisableGSO disable the checksum,
+	// TCP segmentation and generic segmentation offloads of the device.
+	DisableChecksum bool
+	DisableTSO      bool
+	DisableGSO      bool
+
+	// RxRateLimiterMaxRate and TxRateLimiterMaxRate cap the bandwidth, in
+	// bits per second, of the traffic received and sent by the VM.
+	RxRateLimiterMaxRate uint64
+	TxRateLimiterMaxRate uint64
+}
+
+// rateLimited tells if the bandwidth of the VM network device is capped.
+func (c NetInterfaceConfig) rateLimited() bool {
+	return c.RxRateLimiterMaxRate > 0 || c.TxRateLimiterMaxRate > 0
+}
+
+// virtqueueOptions tells if the virtqueue sizes or the offloads of the VM
+// network device are set.
+func (c NetInterfaceConfig) virtqueueOptions() bool {
+	return c.RxQueueSize > 0 || c.TxQueueSize > 0 || c.DisableChecksum || c.DisableTSO || c.DisableGSO
+}
+
+// tapMTU returns the MTU of the TAP connected to a network interface of
+// MTU linkMTU.
+func (c NetInterfaceConfig) tapMTU(linkMTU int) int {
+	if c.MTU > 0 {
+		return int(c.MTU)
+	}
+
+	return linkMTU
 }
 
 // NetworkConfig is the network configuration related to a network.
@@ -160,6 +233,38 @@ type NetworkConfig struct {
 	DisableNewNetNs   bool
 	NetmonConfig      NetmonConfig
 	InterworkingModel NetInterworkingModel
+
+	// Interfaces are the configurations of the VM network devices by
+	// network interface name.
+	Interfaces map[string]NetInterfaceConfig
+
+	// Policy is the egress allow-list enforced on the VM traffic, if any.
+	Policy *NetworkPolicy
+
+	// RxRateLimiterMaxRate and TxRateLimiterMaxRate cap the bandwidth, in
+	// bits per second, of the network interfaces which don't set their
+	// own.
+	RxRateLimiterMaxRate uint64
+	TxRateLimiterMaxRate uint64
+}
+
+// setInterfaceConfig sets the VM network device configuration and the
+// network policy of endpoint.
+func (n NetworkConfig) setInterfaceConfig(endpoint Endpoint) {
+	netPair := endpoint.NetworkPair()
+	if netPair == nil {
+		return
+	}
+
+	netPair.Config = n.Interfaces[endpoint.Name()]
+	netPair.Policy = n.Policy
+
+	if netPair.Config.RxRateLimiterMaxRate == 0 {
+		netPair.Config.RxRateLimiterMaxRate = n.RxRateLimiterMaxRate
+	}
+	if netPair.Config.TxRateLimiterMaxRate == 0 {
+		netPair.Config.TxRateLimiterMaxRate = n.TxRateLimiterMaxRate
+	}
 }
 
 func networkLogger() *logrus.Entry {
@@ -410,27 +515,53 @@ func xConnectVMNetwork(endpoint Endpoint, h hypervisor) error {
 	caps := h.capabilities()
 	if caps.IsMultiQueueSupported() {
 		queues = int(h.hypervisorConfig().NumVCPUs)
+		if netPair.Config.Queues > 0 {
+			queues = int(netPair.Config.Queues)
+		}
 	}
 
 	var disableVhostNet bool
 	if rootless.IsRootless() {
 		disableVhostNet = true
 	} else {
-		disableVhostNet = h.hypervisorConfig().DisableVhostNet
+		disableVhostNet = h.hypervisorConfig().DisableVhostNet || netPair.Config.DisableVhostNet
 	}
 
 	if netPair.NetInterworkingModel == NetXConnectDefaultModel {
 		netPair.NetInterworkingModel = DefaultNetInterworkingModel
 	}
 
+	var err error
 	switch netPair.NetInterworkingModel {
 	case NetXConnectMacVtapModel:
-		return tapNetworkPair(endpoint, queues, disableVhostNet)
+		err = tapNetworkPair(endpoint, queues, disableVhostNet)
 	case NetXConnectTCFilterModel:
-		return setupTCFiltering(endpoint, queues, disableVhostNet)
+		err = setupTCFiltering(endpoint, queues, disableVhostNet)
+	case NetXConnectBridgedModel:
+		err = bridgeNetworkPair(endpoint, queues, disableVhostNet)
 	default:
-		return fmt.Errorf("Invalid internetworking model")
+		err = fmt.Errorf("Invalid internetworking model")
 	}
+	if err != nil {
+		return err
+	}
+
+	if netPair.Config.rateLimited() && !netRateLimitedByHypervisor(h, netPair.Config) {
+		return addTCRateLimiters(endpoint)
+	}
+
+	return nil
+}
+
+// netRateLimitedByHypervisor tells if h caps the bandwidth of a VM network
+// device configured with c.
+func netRateLimitedByHypervisor(h hypervisor, c NetInterfaceConfig) bool {
+	caps := h.capabilities()
+	if caps.IsNetRateLimiterSupported() {
+		return true
+	}
+
+	return caps.IsNetSharedRateLimiterSupported() && c.RxRateLimiterMaxRate == c.TxRateLimiterMaxRate
 }
 
 // The endpoint type should dictate how the disconnection needs to happen.
@@ -441,11 +572,19 @@ func xDisconnectVMNetwork(endpoint Endpoint) error {
 		netPair.NetInterworkingModel = DefaultNetInterworkingModel
 	}
 
+	if netPair.Config.rateLimited() {
+		if err := removeTCRateLimiters(endpoint); err != nil {
+			return err
+		}
+	}
+
 	switch netPair.NetInterworkingModel {
 	case NetXConnectMacVtapModel:
 		return untapNetworkPair(endpoint)
 	case NetXConnectTCFilterModel:
 		return removeTCFiltering(endpoint)
+	case NetXConnectBridgedModel:
+		return unbridgeNetworkPair(endpoint)
 	default:
 		return fmt.Errorf("Invalid internetworking model")
 	}
@@ -570,8 +709,9 @@ func tapNetworkPair(endpoint Endpoint, queues int, disableVhostNet bool) error {
 	tapHardAddr := attrs.HardwareAddr
 	netPair.TAPIface.HardAddr = attrs.HardwareAddr.String()
 
-	if err := netHandle.LinkSetMTU(tapLink, attrs.MTU); err != nil {
-		return fmt.Errorf("Could not set TAP MTU %d: %s", attrs.MTU, err)
+	mtu := netPair.Config.tapMTU(attrs.MTU)
+	if err := netHandle.LinkSetMTU(tapLink, mtu); err != nil {
+		return fmt.Errorf("Could not set TAP MTU %d: %s", mtu, err)
 	}
 
 	hardAddr, err := net.ParseMAC(netPair.VirtIface.HardAddr)
@@ -592,6 +732,18 @@ func tapNetworkPair(endpoint Endpoint, queues int, disableVhostNet bool) error {
 		return fmt.Errorf("Could not enable TAP %s: %s", netPair.TAPIface.Name, err)
 	}
 
+	// The VM traffic is sent through the macvtap, the policy filters its
+	// egress.
+	if netPair.Policy != nil {
+		if err := addQdiscClsact(tapLink); err != nil {
+			return err
+		}
+
+		if err := addNetworkPolicyFilter(tapLink, netlink.HANDLE_MIN_EGRESS, netPair.Policy); err != nil {
+			return err
+		}
+	}
+
 	// Clear the IP addresses from the veth interface to prevent ARP conflict
 	netPair.VirtIface.Addrs, err = netlink.AddrList(link, netlink.FAMILY_ALL)
 	if err != nil {
@@ -664,8 +816,9 @@ func setupTCFiltering(endpoint Endpoint, queues int, disableVhostNet bool) error
 	// to see traffic from this MAC address and not another one.
 	netPair.TAPIface.HardAddr = attrs.HardwareAddr.String()
 
-	if err := netHandle.LinkSetMTU(tapLink, attrs.MTU); err != nil {
-		return fmt.Errorf("Could not set TAP MTU %d: %s", attrs.MTU, err)
+	mtu := netPair.Config.tapMTU(attrs.MTU)
+	if err := netHandle.LinkSetMTU(tapLink, mtu); err != nil {
+		return fmt.Errorf("Could not set TAP MTU %d: %s", mtu, err)
 	}
 
 	if err := netHandle.LinkSetUp(tapLink); err != nil {
@@ -690,6 +843,122 @@ func setupTCFiltering(endpoint Endpoint, queues int, disableVhostNet bool) error
 		return err
 	}
 
+	// The policy filter comes ahead of the redirection of the VM traffic.
+	if netPair.Policy != nil {
+		if err := addNetworkPolicyFilter(tapLink, netlink.MakeHandle(0xffff, 0), netPair.Policy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func bridgeNetworkPair(endpoint Endpoint, queues int, disableVhostNet bool) error {
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Delete()
+
+	netPair := endpoint.NetworkPair()
+
+	tapLink, fds, err := createLink(netHandle, netPair.TAPIface.Name, &netlink.Tuntap{}, queues)
+	if err != nil {
+		return fmt.Errorf("Could not create TAP interface: %s", err)
+	}
+	netPair.VMFds = fds
+
+	if !disableVhostNet {
+		vhostFds, err := createVhostFds(queues)
+		if err != nil {
+			return fmt.Errorf("Could not setup vhost fds %s : %s", netPair.VirtIface.Name, err)
+		}
+		netPair.VhostFds = vhostFds
+	}
+
+	link, err := getLinkForEndpoint(endpoint, netHandle)
+	if err != nil {
+		return err
+	}
+
+	attrs := link.Attrs()
+
+	// Save the veth MAC address to the TAP so that it can later be used
+	// to build the hypervisor command line. This MAC address has to be
+	// the one inside the VM in order to avoid any firewall issues. The
+	// bridge created by the network plugin on the host actually expects
+	// to see traffic from this MAC address and not another one.
+	netPair.TAPIface.HardAddr = attrs.HardwareAddr.String()
+
+	mtu := netPair.Config.tapMTU(attrs.MTU)
+	if err := netHandle.LinkSetMTU(tapLink, mtu); err != nil {
+		return fmt.Errorf("Could not set TAP MTU %d: %s", mtu, err)
+	}
+
+	bridge := &netlink.Bridge{
+		LinkAttrs: netlink.LinkAttrs{
+			Name: netPair.Name,
+			MTU:  mtu,
+		},
+	}
+	if err := netHandle.LinkAdd(bridge); err != nil {
+		return fmt.Errorf("Could not create bridge %s: %s", netPair.Name, err)
+	}
+	netPair.Bridge = netPair.Name
+
+	// The veth MAC address being the one inside the VM, the veth gets
+	// another one for the bridge to forward the VM traffic to the TAP
+	// instead of handling it locally.
+	hardAddr, err := net.ParseMAC(netPair.VirtIface.HardAddr)
+	if err != nil {
+		return err
+	}
+	if err := netHandle.LinkSetHardwareAddr(link, hardAddr); err != nil {
+		return fmt.Errorf("Could not set MAC address %s for veth interface %s: %s",
+			netPair.VirtIface.HardAddr, netPair.VirtIface.Name, err)
+	}
+
+	if err := netHandle.LinkSetMaster(link, bridge); err != nil {
+		return fmt.Errorf("Could not attach veth %s to bridge %s: %s", netPair.VirtIface.Name, netPair.Bridge, err)
+	}
+
+	if err := netHandle.LinkSetMaster(tapLink, bridge); err != nil {
+		return fmt.Errorf("Could not attach TAP %s to bridge %s: %s", netPair.TAPIface.Name, netPair.Bridge, err)
+	}
+
+	// The policy filter runs before the bridge handles the VM traffic.
+	if netPair.Policy != nil {
+		if err := addQdiscIngress(tapLink.Attrs().Index); err != nil {
+			return err
+		}
+
+		if err := addNetworkPolicyFilter(tapLink, netlink.MakeHandle(0xffff, 0), netPair.Policy); err != nil {
+			return err
+		}
+	}
+
+	// Clear the IP addresses from the veth interface to prevent ARP conflict
+	netPair.VirtIface.Addrs, err = netlink.AddrList(link, netlink.FAMILY_ALL)
+	if err != nil {
+		return fmt.Errorf("Unable to obtain veth IP addresses: %s", err)
+	}
+
+	if err := clearIPs(link, netPair.VirtIface.Addrs); err != nil {
+		return fmt.Errorf("Unable to clear veth IP addresses: %s", err)
+	}
+
+	if err := netHandle.LinkSetUp(bridge); err != nil {
+		return fmt.Errorf("Could not enable bridge %s: %s", netPair.Bridge, err)
+	}
+
+	if err := netHandle.LinkSetUp(tapLink); err != nil {
+		return fmt.Errorf("Could not enable TAP %s: %s", netPair.TAPIface.Name, err)
+	}
+
+	if err := netHandle.LinkSetUp(link); err != nil {
+		return fmt.Errorf("Could not enable veth %s: %s", netPair.VirtIface.Name, err)
+	}
+
 	return nil
 }
 
@@ -796,6 +1065,139 @@ func removeQdiscIngress(link netlink.Link) error {
 	return nil
 }
 
+// tbfLatency is the maximum time a packet waits for tokens in a token bucket
+// filter, and tbfMinBurst the minimum bucket size, large enough for the
+// packets of any MTU.
+const (
+	tbfLatency  = 25 * time.Millisecond
+	tbfMinBurst = 64 * 1024
+)
+
+// addTbfQdisc caps the bandwidth of the traffic sent through link to maxRate
+// bits per second, with a bucket of 100ms of traffic.
+//
+// This is equivalent to calling:
+// `tc qdisc replace dev link root tbf rate maxRate burst burst latency 25ms`
+func addTbfQdisc(link netlink.Link, maxRate uint64) error {
+	rate := maxRate / 8
+
+	burst := rate / 10
+	if burst < tbfMinBurst {
+		burst = tbfMinBurst
+	}
+
+	qdisc := &netlink.Tbf{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		},
+		Rate:   rate,
+		Limit:  uint32(rate*uint64(tbfLatency)/uint64(time.Second) + burst),
+		Buffer: uint32(netlink.Xmittime(rate, uint32(burst))),
+	}
+
+	if err := netlink.QdiscReplace(qdisc); err != nil {
+		return fmt.Errorf("Failed to add tbf qdisc to %s: %s", link.Attrs().Name, err)
+	}
+
+	return nil
+}
+
+// removeTbfQdisc removes the token bucket filter previously created on link.
+func removeTbfQdisc(link netlink.Link) error {
+	qdiscs, err := netlink.QdiscList(link)
+	if err != nil {
+		return err
+	}
+
+	for _, qdisc := range qdiscs {
+		tbf, ok := qdisc.(*netlink.Tbf)
+		if !ok || tbf.Parent != netlink.HANDLE_ROOT {
+			continue
+		}
+
+		if err := netlink.QdiscDel(tbf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rateLimitedLinks returns the links through which the traffic received and
+// sent by the VM leaves, nil when it can't be shaped. With the macvtap model,
+// the traffic received by the VM doesn't go through the transmit path of any
+// link of the network namespace.
+func rateLimitedLinks(netHandle *netlink.Handle, endpoint Endpoint) (rxLink, txLink netlink.Link, err error) {
+	netPair := endpoint.NetworkPair()
+
+	link, err := getLinkForEndpoint(endpoint, netHandle)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tapLink, err := netHandle.LinkByName(netPair.TAPIface.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Could not get TAP interface %s: %s", netPair.TAPIface.Name, err)
+	}
+
+	if netPair.NetInterworkingModel == NetXConnectMacVtapModel {
+		return nil, tapLink, nil
+	}
+
+	return tapLink, link, nil
+}
+
+// addTCRateLimiters caps the bandwidth of the VM network device with token
+// bucket filters, for the hypervisors which can't do it.
+func addTCRateLimiters(endpoint Endpoint) error {
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Delete()
+
+	c := endpoint.NetworkPair().Config
+
+	rxLink, txLink, err := rateLimitedLinks(netHandle, endpoint)
+	if err != nil {
+		return err
+	}
+
+	if c.RxRateLimiterMaxRate > 0 {
+		if rxLink == nil {
+			networkLogger().WithField("endpoint", endpoint.Name()).Warn("the bandwidth of the traffic received by the VM can't be capped with the macvtap interworking model")
+		} else if err := addTbfQdisc(rxLink, c.RxRateLimiterMaxRate); err != nil {
+			return err
+		}
+	}
+
+	if c.TxRateLimiterMaxRate > 0 {
+		if err := addTbfQdisc(txLink, c.TxRateLimiterMaxRate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeTCRateLimiters removes the token bucket filters of the VM network
+// device, the ones on the TAP going away with it.
+func removeTCRateLimiters(endpoint Endpoint) error {
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Delete()
+
+	link, err := getLinkForEndpoint(endpoint, netHandle)
+	if err != nil {
+		return err
+	}
+
+	return removeTbfQdisc(link)
+}
+
 func untapNetworkPair(endpoint Endpoint) error {
 	netHandle, err := netlink.NewHandle()
 	if err != nil {
@@ -879,6 +1281,60 @@ func removeTCFiltering(endpoint Endpoint) error {
 	return nil
 }
 
+func unbridgeNetworkPair(endpoint Endpoint) error {
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Delete()
+
+	netPair := endpoint.NetworkPair()
+
+	tapLink, err := getLinkByName(netHandle, netPair.TAPIface.Name, &netlink.Tuntap{})
+	if err != nil {
+		return fmt.Errorf("Could not get TAP interface: %s", err)
+	}
+
+	if err := netHandle.LinkSetDown(tapLink); err != nil {
+		return fmt.Errorf("Could not disable TAP %s: %s", netPair.TAPIface.Name, err)
+	}
+
+	if err := netHandle.LinkDel(tapLink); err != nil {
+		return fmt.Errorf("Could not remove TAP %s: %s", netPair.TAPIface.Name, err)
+	}
+
+	// Removing the bridge releases the veth.
+	bridge, err := netHandle.LinkByName(netPair.Bridge)
+	if err != nil {
+		return fmt.Errorf("Could not get bridge %s: %s", netPair.Bridge, err)
+	}
+
+	if err := netHandle.LinkDel(bridge); err != nil {
+		return fmt.Errorf("Could not remove bridge %s: %s", netPair.Bridge, err)
+	}
+
+	link, err := getLinkForEndpoint(endpoint, netHandle)
+	if err != nil {
+		return err
+	}
+
+	hardAddr, err := net.ParseMAC(netPair.TAPIface.HardAddr)
+	if err != nil {
+		return err
+	}
+	if err := netHandle.LinkSetHardwareAddr(link, hardAddr); err != nil {
+		return fmt.Errorf("Could not set MAC address %s for veth interface %s: %s",
+			netPair.TAPIface.HardAddr, netPair.VirtIface.Name, err)
+	}
+
+	if err := netHandle.LinkSetDown(link); err != nil {
+		return fmt.Errorf("Could not disable veth %s: %s", netPair.VirtIface.Name, err)
+	}
+
+	// Restore the IPs that were cleared
+	return setIPs(link, netPair.VirtIface.Addrs)
+}
+
 func createNetNS() (string, error) {
 	n, err := testutils.NewNS()
 	if err != nil {
@@ -961,6 +1417,18 @@ func generateVCNetworkStructures(networkNS NetworkNamespace) ([]*vcTypes.Interfa
 				continue
 			}
 
+			// The guest generates the IPv6 link-local address from the
+			// interface MAC address, as well as its temporary addresses.
+			// The addresses which failed the duplicate address detection
+			// are not transferred, the guest would find them duplicated
+			// too.
+			if addr.IP.To4() == nil && addr.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			if addr.Flags&(unix.IFA_F_TEMPORARY|unix.IFA_F_DADFAILED) != 0 {
+				continue
+			}
+
 			netMask, _ := addr.Mask.Size()
 			ipAddress := vcTypes.IPAddress{
 				Family:  netlink.FAMILY_V4,
@@ -973,12 +1441,17 @@ func generateVCNetworkStructures(networkNS NetworkNamespace) ([]*vcTypes.Interfa
 			}
 			ipAddresses = append(ipAddresses, &ipAddress)
 		}
+		mtu := endpoint.Properties().Iface.MTU
+		if netPair := endpoint.NetworkPair(); netPair != nil {
+			mtu = netPair.Config.tapMTU(mtu)
+		}
+
 		noarp := endpoint.Properties().Iface.RawFlags & unix.IFF_NOARP
 		ifc := vcTypes.Interface{
 			IPAddresses: ipAddresses,
 			Device:      endpoint.Name(),
 			Name:        endpoint.Name(),
-			Mtu:         uint64(endpoint.Properties().Iface.MTU),
+			Mtu:         uint64(mtu),
 			RawFlags:    noarp,
 			HwAddr:      endpoint.HardwareAddr(),
 			PciPath:     endpoint.PciPath(),
@@ -993,6 +1466,18 @@ func generateVCNetworkStructures(networkNS NetworkNamespace) ([]*vcTypes.Interfa
 				continue
 			}
 
+			// The routes learnt from IPv6 router advertisements are
+			// learnt by the guest from the same advertisements, and
+			// kept up to date with them.
+			if route.Protocol == unix.RTPROT_RA {
+				continue
+			}
+
+			// Only unicast routes can be described to the agent.
+			if route.Type != unix.RTN_UNSPEC && route.Type != unix.RTN_UNICAST {
+				continue
+			}
+
 			if route.Dst != nil {
 				r.Dest = route.Dst.String()
 			}
@@ -1014,8 +1499,8 @@ func generateVCNetworkStructures(networkNS NetworkNamespace) ([]*vcTypes.Interfa
 		for _, neigh := range endpoint.Properties().Neighbors {
 			var n vcTypes.ARPNeighbor
 
-			// We add only static ARP entries
-			if neigh.State != netlink.NUD_PERMANENT {
+			// We add only static ARP and NDP entries
+			if neigh.State != netlink.NUD_PERMANENT || neigh.IP == nil {
 				continue
 			}
 
@@ -1087,17 +1572,72 @@ func generateRandomPrivateMacAddr() (string, error) {
 	return hardAddr.String(), nil
 }
 
+// dadTimeout is how long the IPv6 addresses of a network interface are
+// waited for to complete the duplicate address detection.
+const dadTimeout = 3 * time.Second
+
+// tentativeAddrs tells if some of addrs did not complete the duplicate
+// address detection yet.
+func tentativeAddrs(addrs []netlink.Addr) bool {
+	for _, addr := range addrs {
+		if addr.Flags&unix.IFA_F_TENTATIVE != 0 && addr.Flags&unix.IFA_F_DADFAILED == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addrListDAD returns the addresses of link once they completed the
+// duplicate address detection: the guest can't be told to skip it, the
+// addresses being transferred as they are found to be unique.
+func addrListDAD(handle *netlink.Handle, link netlink.Link) ([]netlink.Addr, error) {
+	deadline := time.Now().Add(dadTimeout)
+	for {
+		addrs, err := handle.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return nil, err
+		}
+
+		if !tentativeAddrs(addrs) {
+			return addrs, nil
+		}
+
+		// The detection does not run while the link is down.
+		if time.Now().After(deadline) {
+			networkLogger().WithField("link", link.Attrs().Name).Warn("Duplicate address detection not completed")
+			return addrs, nil
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
 func networkInfoFromLink(handle *netlink.Handle, link netlink.Link) (NetworkInfo, error) {
-	addrs, err := handle.AddrList(link, netlink.FAMILY_ALL)
+	addrs, err := addrListDAD(handle, link)
 	if err != nil {
 		return NetworkInfo{}, err
 	}
 
-	routes, err := handle.RouteList(link, netlink.FAMILY_ALL)
+	routes, err := handle.RouteList(link, netlink.FAMILY_V4)
 	if err != nil {
 		return NetworkInfo{}, err
 	}
 
+	routesV6, err := handle.RouteList(link, netlink.FAMILY_V6)
+	if err != nil {
+		return NetworkInfo{}, err
+	}
+
+	// The default routes have no destination, the IPv6 ones get one not
+	// to be taken for IPv4 routes when they have no gateway either.
+	for i := range routesV6 {
+		if routesV6[i].Dst == nil {
+			routesV6[i].Dst = &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 8*net.IPv6len)}
+		}
+	}
+	routes = append(routes, routesV6...)
+
 	neighbors, err := handle.NeighList(link.Attrs().Index, netlink.FAMILY_ALL)
 	if err != nil {
 		return NetworkInfo{}, err
@@ -1167,6 +1707,7 @@ func createEndpointsFromScan(networkNSPath string, config *NetworkConfig) ([]End
 		}
 
 		endpoint.SetProperties(netInfo)
+		config.setInterfaceConfig(endpoint)
 		endpoints = append(endpoints, endpoint)
 
 		idx++
@@ -1281,6 +1822,10 @@ func (n *Network) Add(ctx context.Context, config *NetworkConfig, s *Sandbox, ho
 
 	err = doNetNS(config.NetNSPath, func(_ ns.NetNS) error {
 		for _, endpoint := range endpoints {
+			if err := s.restoreGuestHardwareAddr(endpoint); err != nil {
+				return err
+			}
+
 			networkLogger().WithField("endpoint-type", endpoint.Type()).WithField("hotplug", hotplug).Info("Attaching endpoint")
 			if hotplug {
 				if err := endpoint.HotAttach(s.hypervisor); err != nil {
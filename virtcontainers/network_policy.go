@@ -0,0 +1,398 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// NetworkPolicy is an egress allow-list enforced on the host side of the VM
+// network interfaces. The traffic sent by the VM which matches none of its
+// rules is dropped before it leaves the TAP, whatever the interworking model.
+//
+// The rules only apply to the new connections and to the traffic without
+// connections: the tcp segments other than the connection requests, i.e.
+// the ones with ACK or without SYN, are allowed so that the services
+// running in the VM can reply, as are the ICMP replies and errors. The udp
+// replies of the services need to be allowed by rules. ARP and the IPv6
+// neighbour discovery are always allowed.
+type NetworkPolicy struct {
+	Egress []NetworkPolicyRule `json:"egress"`
+}
+
+// NetworkPolicyRule allows the VM traffic to a network.
+type NetworkPolicyRule struct {
+	// CIDR is the destination network, e.g. "10.0.0.0/8" or "2001:db8::/32".
+	CIDR string `json:"cidr"`
+
+	// Protocol restricts the rule to "tcp", "udp", "icmp" or "icmpv6".
+	Protocol string `json:"protocol,omitempty"`
+
+	// Ports restricts a tcp or udp rule to destination ports.
+	Ports []uint16 `json:"ports,omitempty"`
+}
+
+var networkPolicyProtocols = map[string]uint8{
+	"tcp":    unix.IPPROTO_TCP,
+	"udp":    unix.IPPROTO_UDP,
+	"icmp":   unix.IPPROTO_ICMP,
+	"icmpv6": unix.IPPROTO_ICMPV6,
+}
+
+// The verifier of older kernels rejects programs of more instructions.
+const networkPolicyMaxInstructions = 4096
+
+const networkPolicyFilterName = "kata_net_policy"
+
+// ParseNetworkPolicy parses a JSON network policy.
+func ParseNetworkPolicy(data []byte) (*NetworkPolicy, error) {
+	var policy NetworkPolicy
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("Invalid network policy: %v", err)
+	}
+
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (p *NetworkPolicy) validate() error {
+	_, err := p.instructions()
+	return err
+}
+
+// instructions returns the tc classifier enforcing the policy. It runs in
+// direct action mode, dropping the denied packets and handing the allowed
+// ones over to the next filter.
+func (p *NetworkPolicy) instructions() (asm.Instructions, error) {
+	const (
+		// Stack offsets of the network header and of the first bytes
+		// of the transport header.
+		ipHdr = -64
+		l4Hdr = -80
+
+		ethHdrLen  = 14
+		ipv4HdrLen = 20
+		ipv6HdrLen = 40
+
+		tcpFlagsOff = 13
+		tcpFlagSYN  = 0x02
+		tcpFlagACK  = 0x10
+	)
+
+	// loadBytes loads len bytes of the packet into the stack at stackOff,
+	// from offset when offReg is R0, from offReg+offset otherwise.
+	loadBytes := func(offReg asm.Register, offset int32, len int32, stackOff int32) asm.Instructions {
+		insns := asm.Instructions{asm.Mov.Reg(asm.R1, asm.R6)}
+		if offReg == asm.R0 {
+			insns = append(insns, asm.Mov.Imm(asm.R2, offset))
+		} else {
+			insns = append(insns, asm.Mov.Reg(asm.R2, offReg), asm.Add.Imm(asm.R2, offset))
+		}
+		return append(insns,
+			asm.Mov.Reg(asm.R3, asm.RFP),
+			asm.Add.Imm(asm.R3, stackOff),
+			asm.Mov.Imm(asm.R4, len),
+			asm.FnSkbLoadBytes.Call(),
+			asm.JNE.Imm(asm.R0, 0, "drop"),
+		)
+	}
+
+	// loadL4 loads the destination port of the tcp and udp packets into
+	// R9, zero for the other packets, and allows the tcp segments other
+	// than the connection requests and the icmpTypes messages of
+	// icmpProto. R7 is the transport header offset, zero when the packet
+	// has no transport header.
+	loadL4 := func(family string, icmpProto int32, icmpTypes []int32) asm.Instructions {
+		insns := asm.Instructions{
+			asm.Mov.Imm(asm.R9, 0).Sym(family + "_l4"),
+			asm.JEq.Imm(asm.R7, 0, family+"_dst"),
+			asm.JEq.Imm(asm.R8, unix.IPPROTO_TCP, family+"_tcp"),
+			asm.JEq.Imm(asm.R8, icmpProto, family+"_icmp"),
+			asm.JNE.Imm(asm.R8, unix.IPPROTO_UDP, family+"_dst"),
+		}
+		insns = append(insns, loadBytes(asm.R7, 0, 4, l4Hdr)...)
+		insns = append(insns, asm.Ja.Label(family+"_port"))
+
+		load := loadBytes(asm.R7, 0, tcpFlagsOff+1, l4Hdr)
+		load[0] = load[0].Sym(family + "_tcp")
+		insns = append(insns, load...)
+		insns = append(insns,
+			asm.LoadMem(asm.R1, asm.RFP, l4Hdr+tcpFlagsOff, asm.Byte),
+			asm.And.Imm(asm.R1, tcpFlagSYN|tcpFlagACK),
+			asm.JNE.Imm(asm.R1, tcpFlagSYN, "allow"),
+			asm.LoadMem(asm.R9, asm.RFP, l4Hdr+2, asm.Half).Sym(family+"_port"),
+			asm.HostTo(asm.BE, asm.R9, asm.Half),
+			asm.Ja.Label(family+"_dst"),
+		)
+
+		load = loadBytes(asm.R7, 0, 1, l4Hdr)
+		load[0] = load[0].Sym(family + "_icmp")
+		insns = append(insns, load...)
+		insns = append(insns, asm.LoadMem(asm.R1, asm.RFP, l4Hdr, asm.Byte))
+		for _, icmpType := range icmpTypes {
+			insns = append(insns, asm.JEq.Imm(asm.R1, icmpType, "allow"))
+		}
+		return insns
+	}
+
+	insns := asm.Instructions{
+		asm.Mov.Reg(asm.R6, asm.R1),
+	}
+
+	insns = append(insns, loadBytes(asm.R0, 12, 2, l4Hdr)...)
+	insns = append(insns,
+		asm.LoadMem(asm.R0, asm.RFP, l4Hdr, asm.Half),
+		asm.HostTo(asm.BE, asm.R0, asm.Half),
+		asm.JEq.Imm(asm.R0, unix.ETH_P_ARP, "allow"),
+		asm.JEq.Imm(asm.R0, unix.ETH_P_IP, "ipv4"),
+		asm.JEq.Imm(asm.R0, unix.ETH_P_IPV6, "ipv6"),
+		asm.Ja.Label("drop"),
+	)
+
+	// IPv4: R8 is the protocol, R7 the transport header offset unless the
+	// packet is a fragment other than the first one, and R0 the
+	// destination address.
+	load := loadBytes(asm.R0, ethHdrLen, ipv4HdrLen, ipHdr)
+	load[0] = load[0].Sym("ipv4")
+	insns = append(insns, load...)
+	insns = append(insns,
+		asm.LoadMem(asm.R8, asm.RFP, ipHdr+9, asm.Byte),
+		asm.LoadMem(asm.R7, asm.RFP, ipHdr, asm.Byte),
+		asm.And.Imm(asm.R7, 0x0f),
+		asm.LSh.Imm(asm.R7, 2),
+		asm.Add.Imm(asm.R7, ethHdrLen),
+		asm.LoadMem(asm.R0, asm.RFP, ipHdr+6, asm.Half),
+		asm.HostTo(asm.BE, asm.R0, asm.Half),
+		asm.And.Imm(asm.R0, 0x1fff),
+		asm.JEq.Imm(asm.R0, 0, "ipv4_l4"),
+		asm.Mov.Imm(asm.R7, 0),
+	)
+	insns = append(insns, loadL4("ipv4", unix.IPPROTO_ICMP, []int32{
+		0,  // echo reply
+		3,  // destination unreachable
+		11, // time exceeded
+	})...)
+	insns = append(insns,
+		asm.LoadMem(asm.R0, asm.RFP, ipHdr+16, asm.Word).Sym("ipv4_dst"),
+		asm.HostTo(asm.BE, asm.R0, asm.Word),
+	)
+
+	// Each rule jumps to the next rule of its family when it doesn't
+	// match, the last one to the drop.
+	var ipv4Rules, ipv6Rules asm.Instructions
+	var ipv4Count, ipv6Count int
+
+	for i, rule := range p.Egress {
+		_, ipNet, err := net.ParseCIDR(rule.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid network policy rule %d: %v", i, err)
+		}
+
+		var protocol uint8
+		if rule.Protocol != "" {
+			var ok bool
+			if protocol, ok = networkPolicyProtocols[rule.Protocol]; !ok {
+				return nil, fmt.Errorf("Invalid network policy rule %d: unknown protocol %s", i, rule.Protocol)
+			}
+		}
+
+		if len(rule.Ports) > 0 && protocol != unix.IPPROTO_TCP && protocol != unix.IPPROTO_UDP {
+			return nil, fmt.Errorf("Invalid network policy rule %d: ports need the tcp or udp protocol", i)
+		}
+
+		var ruleInsns asm.Instructions
+		var label, next string
+
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			if protocol == unix.IPPROTO_ICMPV6 {
+				return nil, fmt.Errorf("Invalid network policy rule %d: icmpv6 needs an IPv6 network", i)
+			}
+
+			label = fmt.Sprintf("ipv4_rule_%d", ipv4Count)
+			ipv4Count++
+			next = fmt.Sprintf("ipv4_rule_%d", ipv4Count)
+
+			ruleInsns = asm.Instructions{
+				asm.Mov.Reg(asm.R1, asm.R0),
+				asm.LoadImm(asm.R2, int64(beUint(ipNet.Mask)), asm.DWord),
+				asm.And.Reg(asm.R1, asm.R2),
+				asm.LoadImm(asm.R2, int64(beUint(ip4)), asm.DWord),
+				asm.JNE.Reg(asm.R1, asm.R2, next),
+			}
+		} else {
+			if protocol == unix.IPPROTO_ICMP {
+				return nil, fmt.Errorf("Invalid network policy rule %d: icmp needs an IPv4 network", i)
+			}
+
+			label = fmt.Sprintf("ipv6_rule_%d", ipv6Count)
+			ipv6Count++
+			next = fmt.Sprintf("ipv6_rule_%d", ipv6Count)
+
+			for j, reg := range []asm.Register{asm.R0, asm.R5} {
+				ruleInsns = append(ruleInsns,
+					asm.Mov.Reg(asm.R1, reg),
+					asm.LoadImm(asm.R2, int64(beUint(ipNet.Mask[8*j:8*j+8])), asm.DWord),
+					asm.And.Reg(asm.R1, asm.R2),
+					asm.LoadImm(asm.R2, int64(beUint(ipNet.IP[8*j:8*j+8])), asm.DWord),
+					asm.JNE.Reg(asm.R1, asm.R2, next),
+				)
+			}
+		}
+		ruleInsns[0] = ruleInsns[0].Sym(label)
+
+		if protocol != 0 {
+			ruleInsns = append(ruleInsns, asm.JNE.Imm(asm.R8, int32(protocol), next))
+		}
+
+		for _, port := range rule.Ports {
+			if port == 0 {
+				return nil, fmt.Errorf("Invalid network policy rule %d: invalid port 0", i)
+			}
+			ruleInsns = append(ruleInsns, asm.JEq.Imm(asm.R9, int32(port), "allow"))
+		}
+
+		if len(rule.Ports) > 0 {
+			ruleInsns = append(ruleInsns, asm.Ja.Label(next))
+		} else {
+			ruleInsns = append(ruleInsns, asm.Ja.Label("allow"))
+		}
+
+		if ipNet.IP.To4() != nil {
+			ipv4Rules = append(ipv4Rules, ruleInsns...)
+		} else {
+			ipv6Rules = append(ipv6Rules, ruleInsns...)
+		}
+	}
+
+	insns = append(insns, ipv4Rules...)
+	insns = append(insns, asm.Ja.Label("drop").Sym(fmt.Sprintf("ipv4_rule_%d", ipv4Count)))
+
+	// IPv6: R8 is the next header, R7 the transport header offset, and R0
+	// and R5 the destination address. The transport header isn't looked
+	// for after extension headers.
+	load = loadBytes(asm.R0, ethHdrLen, ipv6HdrLen, ipHdr)
+	load[0] = load[0].Sym("ipv6")
+	insns = append(insns, load...)
+	insns = append(insns,
+		asm.LoadMem(asm.R8, asm.RFP, ipHdr+6, asm.Byte),
+		asm.Mov.Imm(asm.R7, ethHdrLen+ipv6HdrLen),
+	)
+	insns = append(insns, loadL4("ipv6", unix.IPPROTO_ICMPV6, []int32{
+		1,   // destination unreachable
+		2,   // packet too big
+		3,   // time exceeded
+		129, // echo reply
+		133, // router solicitation
+		135, // neighbour solicitation
+		136, // neighbour advertisement
+	})...)
+	insns = append(insns,
+		asm.LoadMem(asm.R0, asm.RFP, ipHdr+24, asm.DWord).Sym("ipv6_dst"),
+		asm.HostTo(asm.BE, asm.R0, asm.DWord),
+		asm.LoadMem(asm.R5, asm.RFP, ipHdr+32, asm.DWord),
+		asm.HostTo(asm.BE, asm.R5, asm.DWord),
+	)
+	insns = append(insns, ipv6Rules...)
+	insns = append(insns,
+		asm.Ja.Label("drop").Sym(fmt.Sprintf("ipv6_rule_%d", ipv6Count)),
+
+		asm.Mov.Imm(asm.R0, int32(netlink.TC_ACT_SHOT)).Sym("drop"),
+		asm.Return(),
+
+		asm.Mov.Imm(asm.R0, int32(netlink.TC_ACT_UNSPEC)).Sym("allow"),
+		asm.Return(),
+	)
+
+	if len(insns) > networkPolicyMaxInstructions {
+		return nil, fmt.Errorf("Invalid network policy: too many rules")
+	}
+
+	return insns, nil
+}
+
+// beUint returns the big endian integer of up to 8 bytes.
+func beUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// addNetworkPolicyFilter enforces policy on the VM traffic with a tc
+// classifier attached to the qdisc parent of link, ahead of its other
+// filters.
+func addNetworkPolicyFilter(link netlink.Link, parent uint32, policy *NetworkPolicy) error {
+	insns, err := policy.instructions()
+	if err != nil {
+		return err
+	}
+
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Name:         networkPolicyFilterName,
+		Type:         ebpf.SchedCLS,
+		Instructions: insns,
+		License:      "Apache-2.0",
+	})
+	if err != nil {
+		return fmt.Errorf("Could not load network policy: %v", err)
+	}
+	// The filter holds a reference on the program.
+	defer prog.Close()
+
+	filter := &netlink.BpfFilter{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    parent,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Fd:           prog.FD(),
+		Name:         networkPolicyFilterName,
+		DirectAction: true,
+	}
+
+	if err := netlink.FilterAdd(filter); err != nil {
+		return fmt.Errorf("Could not add network policy filter to %s: %v", link.Attrs().Name, err)
+	}
+
+	return nil
+}
+
+// addQdiscClsact creates a clsact qdisc on link, its egress filters seeing
+// the traffic sent through link.
+//
+// This is equivalent to calling `tc qdisc add dev link clsact`
+func addQdiscClsact(link netlink.Link) error {
+	qdisc := &netlink.GenericQdisc{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(0xffff, 0),
+			Parent:    netlink.HANDLE_CLSACT,
+		},
+		QdiscType: "clsact",
+	}
+
+	if err := netlink.QdiscAdd(qdisc); err != nil {
+		return fmt.Errorf("Failed to add clsact qdisc to %s: %s", link.Attrs().Name, err)
+	}
+
+	return nil
+}
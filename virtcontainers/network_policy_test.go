@@ -0,0 +1,287 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	ktu "github.com/kata-containers/runtime/pkg/katatestutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseNetworkPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	policy, err := ParseNetworkPolicy([]byte(`{
+		"egress": [
+			{"cidr": "10.0.0.0/8"},
+			{"cidr": "192.168.1.1/32", "protocol": "tcp", "ports": [80, 443]},
+			{"cidr": "2001:db8::/32", "protocol": "icmpv6"}
+		]
+	}`))
+	assert.NoError(err)
+	assert.Equal(&NetworkPolicy{
+		Egress: []NetworkPolicyRule{
+			{CIDR: "10.0.0.0/8"},
+			{CIDR: "192.168.1.1/32", Protocol: "tcp", Ports: []uint16{80, 443}},
+			{CIDR: "2001:db8::/32", Protocol: "icmpv6"},
+		},
+	}, policy)
+
+	for _, data := range []string{
+		`{"egress": [{"cidr": "10.0.0.0"}]}`,
+		`{"egress": [{"cidr": "10.0.0.0/8", "protocol": "sctp"}]}`,
+		`{"egress": [{"cidr": "10.0.0.0/8", "ports": [80]}]}`,
+		`{"egress": [{"cidr": "10.0.0.0/8", "protocol": "icmp", "ports": [80]}]}`,
+		`{"egress": [{"cidr": "10.0.0.0/8", "protocol": "tcp", "ports": [0]}]}`,
+		`{"egress": [{"cidr": "10.0.0.0/8", "protocol": "icmpv6"}]}`,
+		`{"egress": [{"cidr": "2001:db8::/32", "protocol": "icmp"}]}`,
+		`{"ingress": [{"cidr": "10.0.0.0/8"}]}`,
+		`{"egress": {"cidr": "10.0.0.0/8"}}`,
+	} {
+		_, err := ParseNetworkPolicy([]byte(data))
+		assert.Error(err, data)
+	}
+}
+
+// testPacket returns an Ethernet frame of a tcp connection request or of a
+// udp packet to dst:port, or of an ICMP packet of type port.
+func testPacket(dst string, protocol uint8, port uint16) []byte {
+	ip := net.ParseIP(dst)
+
+	frame := make([]byte, 14)
+	var l4 []byte
+
+	if protocol == unix.IPPROTO_ICMP || protocol == unix.IPPROTO_ICMPV6 {
+		l4 = []byte{byte(port), 0, 0, 0, 0, 0, 0, 0}
+	} else {
+		l4 = make([]byte, 20)
+		binary.BigEndian.PutUint16(l4[0:], 40000)
+		binary.BigEndian.PutUint16(l4[2:], port)
+		if protocol == unix.IPPROTO_TCP {
+			l4[13] = tcpSYN
+		}
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		binary.BigEndian.PutUint16(frame[12:], unix.ETH_P_IP)
+		hdr := make([]byte, 20)
+		hdr[0] = 0x45
+		binary.BigEndian.PutUint16(hdr[2:], uint16(len(hdr)+len(l4)))
+		hdr[8] = 64
+		hdr[9] = protocol
+		copy(hdr[12:], net.ParseIP("10.1.1.2").To4())
+		copy(hdr[16:], ip4)
+		frame = append(frame, hdr...)
+	} else {
+		binary.BigEndian.PutUint16(frame[12:], unix.ETH_P_IPV6)
+		hdr := make([]byte, 40)
+		hdr[0] = 0x60
+		binary.BigEndian.PutUint16(hdr[4:], uint16(len(l4)))
+		hdr[6] = protocol
+		hdr[7] = 64
+		copy(hdr[8:], net.ParseIP("2001:db8:1::2"))
+		copy(hdr[24:], ip)
+		frame = append(frame, hdr...)
+	}
+
+	return append(frame, l4...)
+}
+
+const (
+	tcpFIN = 0x01
+	tcpSYN = 0x02
+	tcpRST = 0x04
+	tcpACK = 0x10
+)
+
+// testTCPSegment returns an Ethernet frame of a tcp segment with flags to
+// dst:port.
+func testTCPSegment(dst string, port uint16, flags byte) []byte {
+	frame := testPacket(dst, unix.IPPROTO_TCP, port)
+	frame[len(frame)-20+13] = flags
+	return frame
+}
+
+// testNetworkPolicyProgram loads the classifier of the policy data.
+func testNetworkPolicyProgram(t *testing.T, data string) *ebpf.Program {
+	policy, err := ParseNetworkPolicy([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insns, err := policy.instructions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:         ebpf.SchedCLS,
+		Instructions: insns,
+		License:      "Apache-2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return prog
+}
+
+func TestNetworkPolicyFilter(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	assert := assert.New(t)
+
+	prog := testNetworkPolicyProgram(t, `{
+		"egress": [
+			{"cidr": "10.0.0.0/8", "protocol": "udp", "ports": [53]},
+			{"cidr": "192.168.1.1/32", "protocol": "tcp", "ports": [80, 443]},
+			{"cidr": "172.16.0.0/12", "protocol": "icmp"},
+			{"cidr": "2001:db8:2::/48"}
+		]
+	}`)
+	defer prog.Close()
+
+	arp := make([]byte, 42)
+	binary.BigEndian.PutUint16(arp[12:], unix.ETH_P_ARP)
+
+	other := make([]byte, 42)
+	binary.BigEndian.PutUint16(other[12:], unix.ETH_P_8021Q)
+
+	allow := uint32(0xffffffff) // TC_ACT_UNSPEC
+	drop := uint32(netlink.TC_ACT_SHOT)
+
+	for _, d := range []struct {
+		name   string
+		packet []byte
+		action uint32
+	}{
+		{"arp", arp, allow},
+		{"vlan", other, drop},
+		{"truncated", testPacket("10.1.2.3", unix.IPPROTO_UDP, 53)[:36], drop},
+		{"dns", testPacket("10.1.2.3", unix.IPPROTO_UDP, 53), allow},
+		{"dns over tcp", testPacket("10.1.2.3", unix.IPPROTO_TCP, 53), drop},
+		{"other udp port", testPacket("10.1.2.3", unix.IPPROTO_UDP, 54), drop},
+		{"https", testPacket("192.168.1.1", unix.IPPROTO_TCP, 443), allow},
+		{"https other host", testPacket("192.168.1.2", unix.IPPROTO_TCP, 443), drop},
+		{"ping", testPacket("172.20.1.1", unix.IPPROTO_ICMP, 8), allow},
+		{"ping other network", testPacket("172.32.1.1", unix.IPPROTO_ICMP, 8), drop},
+		{"ipv6", testPacket("2001:db8:2:ffff::1", unix.IPPROTO_TCP, 22), allow},
+		{"ipv6 other network", testPacket("2001:db8:3::1", unix.IPPROTO_TCP, 22), drop},
+		{"neighbour solicitation", testPacket("ff02::1:ff00:1", unix.IPPROTO_ICMPV6, 135), allow},
+		{"echo request", testPacket("2001:db8:3::1", unix.IPPROTO_ICMPV6, 128), drop},
+	} {
+		action, _, err := prog.Test(d.packet)
+		assert.NoError(err, d.name)
+		assert.Equal(d.action, action, d.name)
+	}
+}
+
+func TestNetworkPolicyFilterServer(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	assert := assert.New(t)
+
+	// A pod serving clients it isn't allowed to connect to.
+	prog := testNetworkPolicyProgram(t, `{
+		"egress": [
+			{"cidr": "10.0.0.0/8", "protocol": "udp", "ports": [53]}
+		]
+	}`)
+	defer prog.Close()
+
+	allow := uint32(0xffffffff) // TC_ACT_UNSPEC
+	drop := uint32(netlink.TC_ACT_SHOT)
+
+	for _, d := range []struct {
+		name   string
+		packet []byte
+		action uint32
+	}{
+		{"accept", testTCPSegment("192.0.2.10", 51000, tcpSYN|tcpACK), allow},
+		{"reply", testTCPSegment("192.0.2.10", 51000, tcpACK), allow},
+		{"close", testTCPSegment("192.0.2.10", 51000, tcpFIN|tcpACK), allow},
+		{"reset", testTCPSegment("192.0.2.10", 51000, tcpRST), allow},
+		{"ipv6 reply", testTCPSegment("2001:db8:5::10", 51000, tcpACK), allow},
+		{"truncated reply", testTCPSegment("192.0.2.10", 51000, tcpACK)[:40], drop},
+		{"connect", testTCPSegment("192.0.2.10", 51000, tcpSYN), drop},
+		{"ipv6 connect", testTCPSegment("2001:db8:5::10", 51000, tcpSYN), drop},
+		{"udp reply", testPacket("192.0.2.10", unix.IPPROTO_UDP, 51000), drop},
+		{"echo reply", testPacket("192.0.2.10", unix.IPPROTO_ICMP, 0), allow},
+		{"unreachable", testPacket("192.0.2.10", unix.IPPROTO_ICMP, 3), allow},
+		{"echo request", testPacket("192.0.2.10", unix.IPPROTO_ICMP, 8), drop},
+		{"ipv6 echo reply", testPacket("2001:db8:5::10", unix.IPPROTO_ICMPV6, 129), allow},
+		{"packet too big", testPacket("2001:db8:5::10", unix.IPPROTO_ICMPV6, 2), allow},
+	} {
+		action, _, err := prog.Test(d.packet)
+		assert.NoError(err, d.name)
+		assert.Equal(d.action, action, d.name)
+	}
+}
+
+func TestTcRedirectNetworkPolicy(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	assert := assert.New(t)
+
+	netHandle, err := netlink.NewHandle()
+	assert.NoError(err)
+	defer netHandle.Delete()
+
+	// Create a test veth interface.
+	vethName := "foo"
+	veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: vethName, TxQLen: 200, MTU: 1400}, PeerName: "bar"}
+
+	err = netlink.LinkAdd(veth)
+	assert.NoError(err)
+
+	link, err := netlink.LinkByName(vethName)
+	assert.NoError(err)
+	defer netHandle.LinkDel(link)
+
+	err = netHandle.LinkSetUp(link)
+	assert.NoError(err)
+
+	endpoint, err := createVethNetworkEndpoint(1, vethName, NetXConnectTCFilterModel)
+	assert.NoError(err)
+
+	netPair := endpoint.NetworkPair()
+	netPair.Policy = &NetworkPolicy{Egress: []NetworkPolicyRule{{CIDR: "10.0.0.0/8"}}}
+
+	err = setupTCFiltering(endpoint, 1, true)
+	assert.NoError(err)
+
+	tapLink, err := netlink.LinkByName(netPair.TAPIface.Name)
+	assert.NoError(err)
+
+	filters, err := netlink.FilterList(tapLink, netlink.MakeHandle(0xffff, 0))
+	assert.NoError(err)
+	assert.Len(filters, 2)
+
+	// The policy filter comes first.
+	for _, f := range filters {
+		if bpf, ok := f.(*netlink.BpfFilter); ok {
+			assert.True(bpf.DirectAction)
+			assert.Equal(uint16(1), bpf.Attrs().Priority)
+		} else {
+			assert.True(f.Attrs().Priority > 1)
+		}
+	}
+
+	err = removeTCFiltering(endpoint)
+	assert.NoError(err)
+}
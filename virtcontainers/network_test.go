@@ -12,10 +12,12 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/containernetworking/plugins/pkg/ns"
 	ktu "github.com/kata-containers/runtime/pkg/katatestutils"
 	vcTypes "github.com/kata-containers/runtime/virtcontainers/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 )
 
 func TestCreateDeleteNetNS(t *testing.T) {
@@ -134,6 +136,198 @@ func TestGenerateInterfacesAndRoutes(t *testing.T) {
 		"ARP Neighbors returned didn't match: got %+v, expecting %+v", resNeighs, expectedNeighs)
 }
 
+func TestGenerateInterfacesAndRoutesIPv6(t *testing.T) {
+	assert := assert.New(t)
+
+	global := &net.IPNet{IP: net.ParseIP("2001:db8:1::2"), Mask: net.CIDRMask(64, 128)}
+	linkLocal := &net.IPNet{IP: net.ParseIP("fe80::ff:fe00:2"), Mask: net.CIDRMask(64, 128)}
+	temporary := &net.IPNet{IP: net.ParseIP("2001:db8:1::1234:5678"), Mask: net.CIDRMask(64, 128)}
+	duplicate := &net.IPNet{IP: net.ParseIP("2001:db8:1::3"), Mask: net.CIDRMask(64, 128)}
+
+	addrs := []netlink.Addr{
+		{IPNet: global, Flags: unix.IFA_F_PERMANENT},
+		{IPNet: linkLocal, Flags: unix.IFA_F_PERMANENT},
+		{IPNet: temporary, Flags: unix.IFA_F_TEMPORARY},
+		{IPNet: duplicate, Flags: unix.IFA_F_PERMANENT | unix.IFA_F_DADFAILED},
+	}
+
+	defaultV6 := &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+	dst := &net.IPNet{IP: net.ParseIP("2001:db8:2::"), Mask: net.CIDRMask(64, 128)}
+	blackhole := &net.IPNet{IP: net.ParseIP("2001:db8:3::"), Mask: net.CIDRMask(64, 128)}
+	routerAddr := net.ParseIP("fe80::1")
+
+	routes := []netlink.Route{
+		{Dst: &net.IPNet{IP: net.ParseIP("fe80::"), Mask: net.CIDRMask(64, 128)}, Protocol: unix.RTPROT_KERNEL, Type: unix.RTN_UNICAST},
+		{Dst: defaultV6, Gw: routerAddr, Protocol: unix.RTPROT_RA, Type: unix.RTN_UNICAST},
+		{Dst: defaultV6, Gw: routerAddr, Protocol: unix.RTPROT_BOOT, Type: unix.RTN_UNICAST},
+		{Dst: dst, Protocol: unix.RTPROT_BOOT, Type: unix.RTN_UNICAST},
+		{Dst: blackhole, Protocol: unix.RTPROT_BOOT, Type: unix.RTN_BLACKHOLE},
+	}
+
+	routerMAC, _ := net.ParseMAC("6a:92:3a:59:70:aa")
+
+	neighs := []netlink.Neigh{
+		{IP: routerAddr, State: netlink.NUD_PERMANENT, Flags: netlink.NTF_ROUTER, HardwareAddr: routerMAC},
+		{IP: net.ParseIP("2001:db8:1::4"), State: netlink.NUD_REACHABLE, HardwareAddr: routerMAC},
+	}
+
+	ep0 := &PhysicalEndpoint{
+		IfaceName: "eth0",
+		HardAddr:  net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x02}.String(),
+		EndpointProperties: NetworkInfo{
+			Iface:     NetlinkIface{LinkAttrs: netlink.LinkAttrs{MTU: 1500}},
+			Addrs:     addrs,
+			Routes:    routes,
+			Neighbors: neighs,
+		},
+	}
+
+	ifaces, resRoutes, resNeighs, err := generateVCNetworkStructures(NetworkNamespace{NetNsPath: "foobar", Endpoints: []Endpoint{ep0}})
+	assert.NoError(err)
+
+	assert.Equal([]*vcTypes.Interface{
+		{
+			Device:      "eth0",
+			Name:        "eth0",
+			IPAddresses: []*vcTypes.IPAddress{{Family: netlink.FAMILY_V6, Address: "2001:db8:1::2", Mask: "64"}},
+			Mtu:         1500,
+			HwAddr:      "02:00:00:00:00:02",
+		},
+	}, ifaces)
+
+	assert.Equal([]*vcTypes.Route{
+		{Dest: "::/0", Gateway: "fe80::1", Device: "eth0"},
+		{Dest: "2001:db8:2::/64", Device: "eth0"},
+	}, resRoutes)
+
+	assert.Equal([]*vcTypes.ARPNeighbor{
+		{
+			Device:      "eth0",
+			State:       netlink.NUD_PERMANENT,
+			Flags:       netlink.NTF_ROUTER,
+			LLAddr:      "6a:92:3a:59:70:aa",
+			ToIPAddress: &vcTypes.IPAddress{Address: "fe80::1", Family: netlink.FAMILY_V6},
+		},
+	}, resNeighs)
+}
+
+// TestGenerateInterfacesAndRoutesDualStack scans a dual stack network
+// namespace, set up the way a network plugin would.
+func TestGenerateInterfacesAndRoutesDualStack(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	assert := assert.New(t)
+
+	netNSPath, err := createNetNS()
+	assert.NoError(err)
+	defer deleteNetNS(netNSPath)
+
+	hardAddr := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x02}
+	routerMAC := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+
+	err = doNetNS(netNSPath, func(_ ns.NetNS) error {
+		veth := &netlink.Veth{
+			LinkAttrs: netlink.LinkAttrs{Name: "eth0", HardwareAddr: hardAddr, MTU: 1450},
+			PeerName:  "peer0",
+		}
+		if err := netlink.LinkAdd(veth); err != nil {
+			return err
+		}
+
+		link, err := netlink.LinkByName("eth0")
+		if err != nil {
+			return err
+		}
+
+		for _, a := range []string{"10.0.0.2/24", "2001:db8:1::2/64"} {
+			addr, err := netlink.ParseAddr(a)
+			if err != nil {
+				return err
+			}
+			addr.Flags = unix.IFA_F_NODAD
+			if err := netlink.AddrAdd(link, addr); err != nil {
+				return err
+			}
+		}
+
+		for _, name := range []string{"eth0", "peer0"} {
+			if err := netlink.LinkSetUp(&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: name}}); err != nil {
+				return err
+			}
+		}
+
+		_, dst, _ := net.ParseCIDR("2001:db8:2::/64")
+		for _, r := range []netlink.Route{
+			{LinkIndex: link.Attrs().Index, Gw: net.ParseIP("10.0.0.1")},
+			{LinkIndex: link.Attrs().Index, Gw: net.ParseIP("fe80::1")},
+			{LinkIndex: link.Attrs().Index, Dst: dst},
+		} {
+			if err := netlink.RouteAdd(&r); err != nil {
+				return err
+			}
+		}
+
+		for _, ip := range []string{"10.0.0.1", "fe80::1"} {
+			if err := netlink.NeighAdd(&netlink.Neigh{
+				LinkIndex:    link.Attrs().Index,
+				IP:           net.ParseIP(ip),
+				State:        netlink.NUD_PERMANENT,
+				HardwareAddr: routerMAC,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	assert.NoError(err)
+
+	endpoints, err := createEndpointsFromScan(netNSPath, &NetworkConfig{InterworkingModel: NetXConnectTCFilterModel})
+	assert.NoError(err)
+
+	var eth0 []Endpoint
+	for _, e := range endpoints {
+		if e.Name() == "eth0" {
+			eth0 = append(eth0, e)
+		}
+	}
+	assert.Len(eth0, 1)
+
+	// The kernel generates the link-local address of eth0, it isn't
+	// transferred to the guest.
+	linkLocal := false
+	for _, addr := range eth0[0].Properties().Addrs {
+		linkLocal = linkLocal || addr.IP.IsLinkLocalUnicast()
+	}
+	assert.True(linkLocal)
+
+	ifaces, routes, neighs, err := generateVCNetworkStructures(NetworkNamespace{NetNsPath: netNSPath, Endpoints: eth0})
+	assert.NoError(err)
+
+	assert.Len(ifaces, 1)
+	assert.Equal(uint64(1450), ifaces[0].Mtu)
+	assert.Equal([]*vcTypes.IPAddress{
+		{Family: netlink.FAMILY_V4, Address: "10.0.0.2", Mask: "24"},
+		{Family: netlink.FAMILY_V6, Address: "2001:db8:1::2", Mask: "64"},
+	}, ifaces[0].IPAddresses)
+
+	assert.Equal([]*vcTypes.Route{
+		{Gateway: "10.0.0.1", Device: "eth0"},
+		{Dest: "2001:db8:2::/64", Device: "eth0"},
+		{Dest: "::/0", Gateway: "fe80::1", Device: "eth0"},
+	}, routes)
+
+	assert.Len(neighs, 2)
+	for _, n := range neighs {
+		assert.Equal(routerMAC.String(), n.LLAddr)
+		assert.Equal("eth0", n.Device)
+	}
+	assert.Equal(netlink.FAMILY_V4, neighs[0].ToIPAddress.Family)
+	assert.Equal(netlink.FAMILY_V6, neighs[1].ToIPAddress.Family)
+}
+
 func TestNetInterworkingModelIsValid(t *testing.T) {
 	tests := []struct {
 		name string
@@ -144,6 +338,7 @@ func TestNetInterworkingModelIsValid(t *testing.T) {
 		{"Default Model", NetXConnectDefaultModel, true},
 		{"TC Filter Model", NetXConnectTCFilterModel, true},
 		{"Macvtap Model", NetXConnectMacVtapModel, true},
+		{"Bridged Model", NetXConnectBridgedModel, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -166,6 +361,7 @@ func TestNetInterworkingModelSetModel(t *testing.T) {
 		{"macvtap Model", macvtapNetModelStr, false},
 		{"tcfilter Model", tcFilterNetModelStr, false},
 		{"none Model", noneNetModelStr, false},
+		{"bridged Model", bridgedNetModelStr, false},
 	}
 
 	for _, tt := range tests {
@@ -301,3 +497,199 @@ func TestTcRedirectNetwork(t *testing.T) {
 	err = netHandle.LinkDel(link)
 	assert.NoError(err)
 }
+
+func TestBridgedNetwork(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	assert := assert.New(t)
+
+	netHandle, err := netlink.NewHandle()
+	assert.NoError(err)
+	defer netHandle.Delete()
+
+	// Create a test veth interface.
+	vethName := "foo"
+	veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: vethName, TxQLen: 200, MTU: 1400}, PeerName: "bar"}
+
+	err = netlink.LinkAdd(veth)
+	assert.NoError(err)
+
+	link, err := netlink.LinkByName(vethName)
+	assert.NoError(err)
+	defer netHandle.LinkDel(link)
+
+	addr, err := netlink.ParseAddr("172.17.0.2/16")
+	assert.NoError(err)
+	assert.NoError(netlink.AddrAdd(link, addr))
+
+	hardAddr := link.Attrs().HardwareAddr
+
+	endpoint, err := createVethNetworkEndpoint(1, vethName, NetXConnectBridgedModel)
+	assert.NoError(err)
+
+	err = bridgeNetworkPair(endpoint, 1, true)
+	assert.NoError(err)
+
+	netPair := endpoint.NetworkPair()
+	assert.Equal("br1_kata", netPair.Bridge)
+	assert.Equal(hardAddr.String(), netPair.TAPIface.HardAddr)
+
+	bridge, err := netlink.LinkByName(netPair.Bridge)
+	assert.NoError(err)
+	assert.Equal(1400, bridge.Attrs().MTU)
+
+	for _, name := range []string{vethName, netPair.TAPIface.Name} {
+		l, err := netlink.LinkByName(name)
+		assert.NoError(err)
+		assert.Equal(bridge.Attrs().Index, l.Attrs().MasterIndex, name)
+	}
+
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+	assert.NoError(err)
+	assert.Empty(addrs)
+
+	err = unbridgeNetworkPair(endpoint)
+	assert.NoError(err)
+
+	_, err = netlink.LinkByName(netPair.Bridge)
+	assert.Error(err)
+
+	link, err = netlink.LinkByName(vethName)
+	assert.NoError(err)
+	assert.Equal(hardAddr, link.Attrs().HardwareAddr)
+	assert.Zero(link.Attrs().MasterIndex)
+
+	addrs, err = netlink.AddrList(link, netlink.FAMILY_V4)
+	assert.NoError(err)
+	assert.Len(addrs, 1)
+}
+
+func TestTCRateLimiters(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	assert := assert.New(t)
+
+	netHandle, err := netlink.NewHandle()
+	assert.NoError(err)
+	defer netHandle.Delete()
+
+	// Create a test veth interface.
+	vethName := "foo"
+	veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: vethName, TxQLen: 200, MTU: 1400}, PeerName: "bar"}
+
+	err = netlink.LinkAdd(veth)
+	assert.NoError(err)
+
+	link, err := netlink.LinkByName(vethName)
+	assert.NoError(err)
+	defer netHandle.LinkDel(link)
+
+	endpoint, err := createVethNetworkEndpoint(1, vethName, NetXConnectTCFilterModel)
+	assert.NoError(err)
+	endpoint.NetPair.Config = NetInterfaceConfig{RxRateLimiterMaxRate: 8000000, TxRateLimiterMaxRate: 16000000}
+
+	err = setupTCFiltering(endpoint, 1, true)
+	assert.NoError(err)
+
+	err = addTCRateLimiters(endpoint)
+	assert.NoError(err)
+
+	// The traffic received by the VM leaves through the TAP, the traffic
+	// it sends through the veth.
+	rootTbf := func(name string) *netlink.Tbf {
+		l, err := netlink.LinkByName(name)
+		assert.NoError(err)
+
+		qdiscs, err := netlink.QdiscList(l)
+		assert.NoError(err)
+		for _, q := range qdiscs {
+			if tbf, ok := q.(*netlink.Tbf); ok && tbf.Parent == netlink.HANDLE_ROOT {
+				return tbf
+			}
+		}
+		return nil
+	}
+
+	tbf := rootTbf(endpoint.NetPair.TAPIface.Name)
+	if assert.NotNil(tbf) {
+		assert.Equal(uint64(1000000), tbf.Rate)
+	}
+
+	tbf = rootTbf(vethName)
+	if assert.NotNil(tbf) {
+		assert.Equal(uint64(2000000), tbf.Rate)
+	}
+
+	// Adding the rate limiters again replaces them.
+	err = addTCRateLimiters(endpoint)
+	assert.NoError(err)
+
+	err = xDisconnectVMNetwork(endpoint)
+	assert.NoError(err)
+	assert.Nil(rootTbf(vethName))
+}
+
+func TestNetInterfaceConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(1500, NetInterfaceConfig{}.tapMTU(1500))
+	assert.Equal(1400, NetInterfaceConfig{MTU: 1400}.tapMTU(1500))
+
+	config := NetworkConfig{
+		Interfaces: map[string]NetInterfaceConfig{
+			"eth0": {Queues: 2, MTU: 1400},
+		},
+	}
+
+	eth0, err := createVethNetworkEndpoint(0, "eth0", NetXConnectTCFilterModel)
+	assert.NoError(err)
+	eth1, err := createVethNetworkEndpoint(1, "eth1", NetXConnectTCFilterModel)
+	assert.NoError(err)
+
+	config.setInterfaceConfig(eth0)
+	config.setInterfaceConfig(eth1)
+	config.setInterfaceConfig(&PhysicalEndpoint{})
+
+	assert.Equal(config.Interfaces["eth0"], eth0.NetPair.Config)
+	assert.Equal(NetInterfaceConfig{}, eth1.NetPair.Config)
+
+	// The rate limiters of the network configuration apply to the
+	// interfaces which don't set their own.
+	config.RxRateLimiterMaxRate = 1000000
+	config.TxRateLimiterMaxRate = 2000000
+	config.Interfaces["eth1"] = NetInterfaceConfig{TxRateLimiterMaxRate: 3000000}
+
+	config.setInterfaceConfig(eth1)
+	assert.Equal(NetInterfaceConfig{RxRateLimiterMaxRate: 1000000, TxRateLimiterMaxRate: 3000000}, eth1.NetPair.Config)
+	assert.True(eth1.NetPair.Config.rateLimited())
+	assert.False(eth0.NetPair.Config.rateLimited())
+
+	eth0.SetProperties(NetworkInfo{Iface: NetlinkIface{LinkAttrs: netlink.LinkAttrs{MTU: 1500}}})
+	eth1.SetProperties(NetworkInfo{Iface: NetlinkIface{LinkAttrs: netlink.LinkAttrs{MTU: 1500}}})
+
+	ifaces, _, _, err := generateVCNetworkStructures(NetworkNamespace{
+		NetNsPath: "foobar",
+		Endpoints: []Endpoint{eth0, eth1},
+	})
+	assert.NoError(err)
+	assert.Len(ifaces, 2)
+	assert.Equal(uint64(1400), ifaces[0].Mtu)
+	assert.Equal(uint64(1500), ifaces[1].Mtu)
+}
+
+func TestTentativeAddrs(t *testing.T) {
+	assert := assert.New(t)
+
+	addrs := []netlink.Addr{
+		{IPNet: &net.IPNet{IP: net.ParseIP("2001:db8::1")}},
+		{IPNet: &net.IPNet{IP: net.ParseIP("2001:db8::2")}, Flags: unix.IFA_F_TENTATIVE | unix.IFA_F_DADFAILED},
+	}
+	assert.False(tentativeAddrs(addrs))
+
+	addrs = append(addrs, netlink.Addr{IPNet: &net.IPNet{IP: net.ParseIP("2001:db8::3")}, Flags: unix.IFA_F_TENTATIVE})
+	assert.True(tentativeAddrs(addrs))
+}
@@ -126,6 +126,11 @@ func (n *noopAgent) listRoutes() ([]*vcTypes.Route, error) {
 	return nil, nil
 }
 
+// addARPNeighbors is the Noop agent ARP neighbors addition implementation. It does nothing.
+func (n *noopAgent) addARPNeighbors(neighs []*vcTypes.ARPNeighbor) error {
+	return nil
+}
+
 // check is the Noop agent health checker. It does nothing.
 func (n *noopAgent) check() error {
 	return nil
@@ -212,6 +212,13 @@ func TestNoopAgentListRoutes(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestNoopAgentAddARPNeighbors(t *testing.T) {
+	n := &noopAgent{}
+	assert := assert.New(t)
+	err := n.addARPNeighbors(nil)
+	assert.NoError(err)
+}
+
 func TestNoopAgentRSetProxy(t *testing.T) {
 	n := &noopAgent{}
 	p := &noopProxy{}
@@ -115,6 +115,7 @@ func (s *Sandbox) dumpProcess(cs map[string]persistapi.ContainerState) {
 		state.Process = persistapi.Process{
 			Token:     cont.process.Token,
 			Pid:       cont.process.Pid,
+			GuestPid:  cont.process.GuestPid,
 			StartTime: cont.process.StartTime,
 		}
 
@@ -191,6 +192,9 @@ func (s *Sandbox) dumpConfig(ss *persistapi.SandboxState) {
 			NetNsCreated:      sconfig.NetworkConfig.NetNsCreated,
 			DisableNewNetNs:   sconfig.NetworkConfig.DisableNewNetNs,
 			InterworkingModel: int(sconfig.NetworkConfig.InterworkingModel),
+
+			RxRateLimiterMaxRate: sconfig.NetworkConfig.RxRateLimiterMaxRate,
+			TxRateLimiterMaxRate: sconfig.NetworkConfig.TxRateLimiterMaxRate,
 		},
 
 		ShmSize:             sconfig.ShmSize,
@@ -207,6 +211,20 @@ func (s *Sandbox) dumpConfig(ss *persistapi.SandboxState) {
 		ss.Config.Experimental = append(ss.Config.Experimental, e.Name)
 	}
 
+	for name, c := range sconfig.NetworkConfig.Interfaces {
+		if ss.Config.NetworkConfig.Interfaces == nil {
+			ss.Config.NetworkConfig.Interfaces = make(map[string]persistapi.NetInterfaceConfig)
+		}
+		ss.Config.NetworkConfig.Interfaces[name] = persistapi.NetInterfaceConfig(c)
+	}
+
+	if policy := sconfig.NetworkConfig.Policy; policy != nil {
+		ss.Config.NetworkConfig.Policy = &persistapi.NetworkPolicy{}
+		for _, r := range policy.Egress {
+			ss.Config.NetworkConfig.Policy.Egress = append(ss.Config.NetworkConfig.Policy.Egress, persistapi.NetworkPolicyRule(r))
+		}
+	}
+
 	ss.Config.HypervisorConfig = persistapi.HypervisorConfig{
 		NumVCPUs:                sconfig.HypervisorConfig.NumVCPUs,
 		DefaultMaxVCPUs:         sconfig.HypervisorConfig.DefaultMaxVCPUs,
@@ -217,6 +235,7 @@ func (s *Sandbox) dumpConfig(ss *persistapi.SandboxState) {
 		MemOffset:               sconfig.HypervisorConfig.MemOffset,
 		VirtioMem:               sconfig.HypervisorConfig.VirtioMem,
 		VirtioFSCacheSize:       sconfig.HypervisorConfig.VirtioFSCacheSize,
+		SpareNetworkDevices:     sconfig.HypervisorConfig.SpareNetworkDevices,
 		KernelPath:              sconfig.HypervisorConfig.KernelPath,
 		ImagePath:               sconfig.HypervisorConfig.ImagePath,
 		InitrdPath:              sconfig.HypervisorConfig.InitrdPath,
@@ -303,7 +322,8 @@ func (s *Sandbox) dumpConfig(ss *persistapi.SandboxState) {
 	}
 }
 
-func (s *Sandbox) Save() error {
+// dump returns the sandbox and container states to be persisted.
+func (s *Sandbox) dump() (persistapi.SandboxState, map[string]persistapi.ContainerState) {
 	var (
 		ss = persistapi.SandboxState{}
 		cs = make(map[string]persistapi.ContainerState)
@@ -319,6 +339,12 @@ func (s *Sandbox) Save() error {
 	s.dumpNetwork(&ss)
 	s.dumpConfig(&ss)
 
+	return ss, cs
+}
+
+func (s *Sandbox) Save() error {
+	ss, cs := s.dump()
+
 	if err := s.newStore.ToDisk(ss, cs); err != nil {
 		return err
 	}
@@ -390,6 +416,7 @@ func (c *Container) loadContProcess(cs persistapi.ContainerState) {
 	c.process = Process{
 		Token:     cs.Process.Token,
 		Pid:       cs.Process.Pid,
+		GuestPid:  cs.Process.GuestPid,
 		StartTime: cs.Process.StartTime,
 	}
 }
@@ -402,31 +429,37 @@ func (s *Sandbox) loadNetwork(netInfo persistapi.NetworkInfo) {
 	}
 
 	for _, e := range netInfo.Endpoints {
-		var ep Endpoint
-		switch EndpointType(e.Type) {
-		case PhysicalEndpointType:
-			ep = &PhysicalEndpoint{}
-		case VethEndpointType:
-			ep = &VethEndpoint{}
-		case VhostUserEndpointType:
-			ep = &VhostUserEndpoint{}
-		case BridgedMacvlanEndpointType:
-			ep = &BridgedMacvlanEndpoint{}
-		case MacvtapEndpointType:
-			ep = &MacvtapEndpoint{}
-		case TapEndpointType:
-			ep = &TapEndpoint{}
-		case IPVlanEndpointType:
-			ep = &IPVlanEndpoint{}
-		default:
-			s.Logger().WithField("endpoint-type", e.Type).Error("unknown endpoint type")
-			continue
+		if ep := loadEndpoint(e); ep != nil {
+			s.networkNS.Endpoints = append(s.networkNS.Endpoints, ep)
 		}
-		ep.load(e)
-		s.networkNS.Endpoints = append(s.networkNS.Endpoints, ep)
 	}
 }
 
+func loadEndpoint(e persistapi.NetworkEndpoint) Endpoint {
+	var ep Endpoint
+	switch EndpointType(e.Type) {
+	case PhysicalEndpointType:
+		ep = &PhysicalEndpoint{}
+	case VethEndpointType:
+		ep = &VethEndpoint{}
+	case VhostUserEndpointType:
+		ep = &VhostUserEndpoint{}
+	case BridgedMacvlanEndpointType:
+		ep = &BridgedMacvlanEndpoint{}
+	case MacvtapEndpointType:
+		ep = &MacvtapEndpoint{}
+	case TapEndpointType:
+		ep = &TapEndpoint{}
+	case IPVlanEndpointType:
+		ep = &IPVlanEndpoint{}
+	default:
+		networkLogger().WithField("endpoint-type", e.Type).Error("unknown endpoint type")
+		return nil
+	}
+	ep.load(e)
+	return ep
+}
+
 // Restore will restore sandbox data from persist file on disk
 func (s *Sandbox) Restore() error {
 	ss, _, err := s.newStore.FromDisk(s.id)
@@ -472,7 +505,10 @@ func loadSandboxConfig(id string) (*SandboxConfig, error) {
 		return nil, err
 	}
 
-	savedConf := ss.Config
+	return sandboxConfigFromState(id, ss.Config), nil
+}
+
+func sandboxConfigFromState(id string, savedConf persistapi.SandboxConfig) *SandboxConfig {
 	sconfig := &SandboxConfig{
 		ID:             id,
 		HypervisorType: HypervisorType(savedConf.HypervisorType),
@@ -488,6 +524,9 @@ func loadSandboxConfig(id string) (*SandboxConfig, error) {
 			NetNsCreated:      savedConf.NetworkConfig.NetNsCreated,
 			DisableNewNetNs:   savedConf.NetworkConfig.DisableNewNetNs,
 			InterworkingModel: NetInterworkingModel(savedConf.NetworkConfig.InterworkingModel),
+
+			RxRateLimiterMaxRate: savedConf.NetworkConfig.RxRateLimiterMaxRate,
+			TxRateLimiterMaxRate: savedConf.NetworkConfig.TxRateLimiterMaxRate,
 		},
 
 		ShmSize:             savedConf.ShmSize,
@@ -504,6 +543,20 @@ func loadSandboxConfig(id string) (*SandboxConfig, error) {
 		sconfig.Experimental = append(sconfig.Experimental, *exp.Get(name))
 	}
 
+	for name, c := range savedConf.NetworkConfig.Interfaces {
+		if sconfig.NetworkConfig.Interfaces == nil {
+			sconfig.NetworkConfig.Interfaces = make(map[string]NetInterfaceConfig)
+		}
+		sconfig.NetworkConfig.Interfaces[name] = NetInterfaceConfig(c)
+	}
+
+	if policy := savedConf.NetworkConfig.Policy; policy != nil {
+		sconfig.NetworkConfig.Policy = &NetworkPolicy{}
+		for _, r := range policy.Egress {
+			sconfig.NetworkConfig.Policy.Egress = append(sconfig.NetworkConfig.Policy.Egress, NetworkPolicyRule(r))
+		}
+	}
+
 	hconf := savedConf.HypervisorConfig
 	sconfig.HypervisorConfig = HypervisorConfig{
 		NumVCPUs:                hconf.NumVCPUs,
@@ -515,6 +568,7 @@ func loadSandboxConfig(id string) (*SandboxConfig, error) {
 		MemOffset:               hconf.MemOffset,
 		VirtioMem:               hconf.VirtioMem,
 		VirtioFSCacheSize:       hconf.VirtioFSCacheSize,
+		SpareNetworkDevices:     hconf.SpareNetworkDevices,
 		KernelPath:              hconf.KernelPath,
 		ImagePath:               hconf.ImagePath,
 		InitrdPath:              hconf.InitrdPath,
@@ -589,7 +643,7 @@ func loadSandboxConfig(id string) (*SandboxConfig, error) {
 			},
 		})
 	}
-	return sconfig, nil
+	return sconfig
 }
 
 var oldstoreKey = struct{}{}
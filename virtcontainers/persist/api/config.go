@@ -38,6 +38,10 @@ type HypervisorConfig struct {
 	// VirtioFSCacheSize is the DAX cache size in MiB
 	VirtioFSCacheSize uint32
 
+	// SpareNetworkDevices is the number of network devices attached to
+	// the VM at boot time for the interfaces added later on.
+	SpareNetworkDevices uint32
+
 	// KernelPath is the guest kernel host path.
 	KernelPath string
 
@@ -233,6 +237,11 @@ type NetworkConfig struct {
 	NetNsCreated      bool
 	DisableNewNetNs   bool
 	InterworkingModel int
+	Interfaces        map[string]NetInterfaceConfig
+	Policy            *NetworkPolicy
+
+	RxRateLimiterMaxRate uint64
+	TxRateLimiterMaxRate uint64
 }
 
 type ContainerConfig struct {
@@ -81,11 +81,17 @@ type Process struct {
 	// shim PID.
 	Pid int
 
+	// GuestPid is the process ID as seen in the VM.
+	GuestPid int
+
 	StartTime time.Time
 }
 
 // ContainerState represents container state
 type ContainerState struct {
+	// Generation of the sandbox state this container state was written with
+	Generation uint64
+
 	// State is container running status
 	State string
 
@@ -38,6 +38,40 @@ type NetworkInterfacePair struct {
 	TapInterface
 	VirtIface            NetworkInterface
 	NetInterworkingModel int
+
+	// Bridge is the Linux bridge created with the bridged interworking
+	// model.
+	Bridge string
+
+	Config NetInterfaceConfig
+}
+
+// NetInterfaceConfig is the configuration of the VM network device of a
+// network interface.
+type NetInterfaceConfig struct {
+	Queues          uint32
+	MTU             uint32
+	RxQueueSize     uint32
+	TxQueueSize     uint32
+	DisableVhostNet bool
+	DisableChecksum bool
+	DisableTSO      bool
+	DisableGSO      bool
+
+	RxRateLimiterMaxRate uint64
+	TxRateLimiterMaxRate uint64
+}
+
+// NetworkPolicy is the egress allow-list enforced on the VM traffic.
+type NetworkPolicy struct {
+	Egress []NetworkPolicyRule
+}
+
+// NetworkPolicyRule allows the VM traffic to a network.
+type NetworkPolicyRule struct {
+	CIDR     string
+	Protocol string
+	Ports    []uint16
 }
 
 type PhysicalEndpoint struct {
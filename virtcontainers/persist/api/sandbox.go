@@ -23,6 +23,11 @@ type SandboxState struct {
 	// PersistVersion of persist data format, can be used for keeping compatibility later
 	PersistVersion uint
 
+	// Generation is incremented by the persist driver each time the sandbox
+	// state is written. It tells which container states were written along
+	// with it, and which state is the last good one after a crash.
+	Generation uint64
+
 	// State is sandbox running status
 	State string
 
@@ -21,6 +21,14 @@ import (
 // persistFile is the file name for JSON sandbox/container configuration
 const persistFile = "persist.json"
 
+// prevPersistFile is the file name for the previous generation of the JSON
+// sandbox/container configuration
+const prevPersistFile = persistFile + ".prev"
+
+// tmpPersistFile is the file name the JSON sandbox/container configuration
+// is written to before replacing persistFile
+const tmpPersistFile = persistFile + ".tmp"
+
 // dirMode is the permission bits used for creating a directory
 const dirMode = os.FileMode(0700) | os.ModeDir
 
@@ -71,6 +79,129 @@ func (fs *FS) sandboxDir(sandboxID string) (string, error) {
 	return filepath.Join(fs.RunStoragePath(), sandboxID), nil
 }
 
+// syncDir makes the renames done in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// writeState replaces the persist file of dir with the JSON encoding of v.
+// The data is written to a temporary file which is synced before being
+// renamed, so that a crash leaves either the previous or the new file, never
+// a partially written one. The replaced file is kept as the previous
+// generation.
+func writeState(dir string, v interface{}) (retErr error) {
+	tmpFile := filepath.Join(dir, tmpPersistFile)
+	f, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileMode)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if retErr != nil {
+			os.Remove(tmpFile)
+		}
+	}()
+
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, persistFile)
+	if err := os.Rename(file, filepath.Join(dir, prevPersistFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Rename(tmpFile, file); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// readState decodes the persist file at path into v.
+func readState(path string, v interface{}) error {
+	f, err := os.OpenFile(path, os.O_RDONLY, fileMode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewDecoder(f).Decode(v)
+}
+
+// readSandboxState reads the last good generation of the sandbox state from
+// sandboxDir. The previous generation is used when the current one is
+// missing or cannot be decoded, as it happens when the host crashes while
+// the state is written.
+func (fs *FS) readSandboxState(sandboxDir string) (persistapi.SandboxState, error) {
+	var ss persistapi.SandboxState
+
+	err := readState(filepath.Join(sandboxDir, persistFile), &ss)
+	if err == nil {
+		return ss, nil
+	}
+
+	var prev persistapi.SandboxState
+	if prevErr := readState(filepath.Join(sandboxDir, prevPersistFile), &prev); prevErr != nil {
+		return ss, err
+	}
+
+	fs.Logger().WithError(err).WithField("generation", prev.Generation).
+		Warn("failed to read sandbox state, using previous generation")
+
+	return prev, nil
+}
+
+// readContainerState reads the state of the container in containerDir
+// which was written with the sandbox state generation. It returns false
+// if the container was not part of that generation.
+func (fs *FS) readContainerState(containerDir string, generation uint64) (persistapi.ContainerState, bool, error) {
+	var readErr error
+
+	for _, name := range []string{persistFile, prevPersistFile} {
+		var cstate persistapi.ContainerState
+
+		err := readState(filepath.Join(containerDir, name), &cstate)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			fs.Logger().WithError(err).WithField("file", filepath.Join(containerDir, name)).
+				Warn("failed to read container state")
+			if readErr == nil {
+				readErr = err
+			}
+			continue
+		}
+
+		// The container state is newer than the sandbox state when
+		// writing the sandbox state did not complete.
+		if cstate.Generation > generation {
+			continue
+		}
+
+		return cstate, true, nil
+	}
+
+	return persistapi.ContainerState{}, false, readErr
+}
+
 // ToDisk sandboxState and containerState to disk
 func (fs *FS) ToDisk(ss persistapi.SandboxState, cs map[string]persistapi.ContainerState) (retErr error) {
 	id := ss.SandboxContainer
@@ -78,39 +209,39 @@ func (fs *FS) ToDisk(ss persistapi.SandboxState, cs map[string]persistapi.Contai
 		return fmt.Errorf("sandbox container id required")
 	}
 
-	fs.sandboxState = &ss
-	fs.containerState = cs
-
 	sandboxDir, err := fs.sandboxDir(id)
 	if err != nil {
 		return err
 	}
 
+	// The generation follows the last one written, which has to be
+	// read from disk if the state of the sandbox is not known yet.
+	generation := fs.sandboxState.Generation
+	if fs.sandboxState.SandboxContainer != id {
+		generation = 0
+		if last, err := fs.readSandboxState(sandboxDir); err == nil {
+			generation = last.Generation
+		}
+	}
+	ss.Generation = generation + 1
+
+	fs.sandboxState = &ss
+	fs.containerState = cs
+
 	if err := os.MkdirAll(sandboxDir, dirMode); err != nil {
 		return err
 	}
 
-	// if error happened, destroy all dirs
+	// if error happened, destroy all dirs, unless a previous generation
+	// is there to be restored
 	defer func() {
-		if retErr != nil {
+		if retErr != nil && generation == 0 {
 			if err := fs.Destroy(id); err != nil {
 				fs.Logger().WithError(err).Errorf("failed to destroy dirs")
 			}
 		}
 	}()
 
-	// persist sandbox configuration data
-	sandboxFile := filepath.Join(sandboxDir, persistFile)
-	f, err := os.OpenFile(sandboxFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileMode)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	if err := json.NewEncoder(f).Encode(fs.sandboxState); err != nil {
-		return err
-	}
-
 	var dirCreationErr error
 	var createdDirs []string
 	defer func() {
@@ -120,7 +251,8 @@ func (fs *FS) ToDisk(ss persistapi.SandboxState, cs map[string]persistapi.Contai
 			}
 		}
 	}()
-	// persist container configuration data
+	// persist container configuration data, before the sandbox one
+	// which commits the generation
 	for cid, cstate := range fs.containerState {
 		cdir := filepath.Join(sandboxDir, cid)
 		if dirCreationErr = os.MkdirAll(cdir, dirMode); dirCreationErr != nil {
@@ -128,16 +260,15 @@ func (fs *FS) ToDisk(ss persistapi.SandboxState, cs map[string]persistapi.Contai
 		}
 		createdDirs = append(createdDirs, cdir)
 
-		cfile := filepath.Join(cdir, persistFile)
-		cf, err := os.OpenFile(cfile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, fileMode)
-		if err != nil {
+		cstate.Generation = ss.Generation
+		if err := writeState(cdir, cstate); err != nil {
 			return err
 		}
+	}
 
-		defer cf.Close()
-		if err := json.NewEncoder(cf).Encode(cstate); err != nil {
-			return err
-		}
+	// persist sandbox configuration data
+	if err := writeState(sandboxDir, fs.sandboxState); err != nil {
+		return err
 	}
 
 	// Walk sandbox dir and find container.
@@ -177,16 +308,10 @@ func (fs *FS) FromDisk(sid string) (persistapi.SandboxState, map[string]persista
 	}
 
 	// get sandbox configuration from persist data
-	sandboxFile := filepath.Join(sandboxDir, persistFile)
-	f, err := os.OpenFile(sandboxFile, os.O_RDONLY, fileMode)
+	ss, err = fs.readSandboxState(sandboxDir)
 	if err != nil {
 		return ss, nil, err
 	}
-	defer f.Close()
-
-	if err := json.NewDecoder(f).Decode(fs.sandboxState); err != nil {
-		return ss, nil, err
-	}
 
 	// walk sandbox dir and find container
 	files, err := ioutil.ReadDir(sandboxDir)
@@ -194,32 +319,31 @@ func (fs *FS) FromDisk(sid string) (persistapi.SandboxState, map[string]persista
 		return ss, nil, err
 	}
 
+	cs := make(map[string]persistapi.ContainerState)
 	for _, file := range files {
 		if !file.IsDir() {
 			continue
 		}
 
 		cid := file.Name()
-		cfile := filepath.Join(sandboxDir, cid, persistFile)
-		cf, err := os.OpenFile(cfile, os.O_RDONLY, fileMode)
+		cstate, found, err := fs.readContainerState(filepath.Join(sandboxDir, cid), ss.Generation)
 		if err != nil {
-			// if persist.json doesn't exist, ignore and go to next
-			if os.IsNotExist(err) {
-				continue
-			}
 			return ss, nil, err
 		}
 
-		defer cf.Close()
-		var cstate persistapi.ContainerState
-		if err := json.NewDecoder(cf).Decode(&cstate); err != nil {
-			return ss, nil, err
+		// if persist.json doesn't exist for the generation, ignore
+		// and go to next
+		if !found {
+			continue
 		}
 
-		fs.containerState[cid] = cstate
+		cs[cid] = cstate
 	}
 
-	return *fs.sandboxState, fs.containerState, nil
+	fs.sandboxState = &ss
+	fs.containerState = cs
+
+	return ss, cs, nil
 }
 
 // Destroy removes everything from disk
@@ -7,7 +7,9 @@ package fs
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	persistapi "github.com/kata-containers/runtime/virtcontainers/persist/api"
@@ -161,6 +163,97 @@ func TestFsDriver(t *testing.T) {
 	assert.True(t, os.IsNotExist(err))
 }
 
+func TestFsDriverGeneration(t *testing.T) {
+	defer initTestDir()()
+
+	fs, err := getFsDriver()
+	assert.Nil(t, err)
+	assert.NotNil(t, fs)
+
+	id := "test-fs-driver"
+	ss := persistapi.SandboxState{
+		SandboxContainer: id,
+	}
+	cs := map[string]persistapi.ContainerState{
+		"test-container": {},
+	}
+
+	assert.Nil(t, fs.ToDisk(ss, cs))
+	assert.Nil(t, fs.ToDisk(ss, cs))
+
+	sandboxDir, err := fs.sandboxDir(id)
+	assert.Nil(t, err)
+
+	// The previous generation is kept, and no temporary file is left.
+	_, err = os.Stat(filepath.Join(sandboxDir, prevPersistFile))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(sandboxDir, tmpPersistFile))
+	assert.True(t, os.IsNotExist(err))
+
+	ss, cs, err = fs.FromDisk(id)
+	assert.Nil(t, err)
+	assert.Equal(t, ss.Generation, uint64(2))
+	assert.Equal(t, cs["test-container"].Generation, uint64(2))
+
+	// A new driver goes on from the generation on disk.
+	fs2, err := getFsDriver()
+	assert.Nil(t, err)
+	assert.Nil(t, fs2.ToDisk(ss, cs))
+
+	ss, _, err = fs2.FromDisk(id)
+	assert.Nil(t, err)
+	assert.Equal(t, ss.Generation, uint64(3))
+}
+
+func TestFsDriverFromDiskFallback(t *testing.T) {
+	defer initTestDir()()
+
+	fs, err := getFsDriver()
+	assert.Nil(t, err)
+	assert.NotNil(t, fs)
+
+	id := "test-fs-driver"
+	ss := persistapi.SandboxState{
+		SandboxContainer: id,
+		State:            "ready",
+	}
+	cs := map[string]persistapi.ContainerState{
+		"test-container": {
+			State: "ready",
+		},
+	}
+	assert.Nil(t, fs.ToDisk(ss, cs))
+
+	ss.State = "running"
+	cs["test-container"] = persistapi.ContainerState{
+		State: "running",
+	}
+	cs["test-container2"] = persistapi.ContainerState{
+		State: "ready",
+	}
+	assert.Nil(t, fs.ToDisk(ss, cs))
+
+	// Simulate a crash while writing the sandbox state.
+	sandboxDir, err := fs.sandboxDir(id)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(sandboxDir, persistFile), []byte(`{"State": "run`), fileMode)
+	assert.Nil(t, err)
+
+	// The state is the one of the first generation, without the
+	// container added by the second one.
+	ss, cs, err = fs.FromDisk(id)
+	assert.Nil(t, err)
+	assert.Equal(t, ss.Generation, uint64(1))
+	assert.Equal(t, ss.State, "ready")
+	assert.Equal(t, len(cs), 1)
+	assert.Equal(t, cs["test-container"].State, "ready")
+
+	// Without a previous generation, the error is returned.
+	assert.Nil(t, os.Remove(filepath.Join(sandboxDir, prevPersistFile)))
+	_, _, err = fs.FromDisk(id)
+	assert.NotNil(t, err)
+}
+
 func TestGlobalReadWrite(t *testing.T) {
 	defer initTestDir()()
 
@@ -20,6 +20,10 @@ const (
 	// ContainerTypeKey is the annotation key to fetch container type.
 	ContainerTypeKey = kataAnnotationsPrefix + "pkg.oci.container_type"
 
+	// GuestPidKey is the annotation key the container process ID in the
+	// VM is reported with in the container state.
+	GuestPidKey = kataAnnotationsPrefix + "pkg.oci.guest_pid"
+
 	SandboxConfigPathKey = kataAnnotationsPrefix + "config_path"
 )
 
@@ -237,6 +241,57 @@ const (
 
 	// DisableNewNetNs is a sandbox annotation that determines if create a netns for hypervisor process.
 	DisableNewNetNs = kataAnnotRuntimePrefix + "disable_new_netns"
+
+	// NetInterfacePrefix is the prefix of the sandbox annotations configuring the VM network device of a
+	// network interface, NetInterfacePrefix + "<interface>.<option>". The options are queues, mtu,
+	// rx_queue_size, tx_queue_size, disable_vhost_net, disable_checksum, disable_tso, disable_gso,
+	// rx_rate_limiter_max_rate and tx_rate_limiter_max_rate.
+	NetInterfacePrefix = kataAnnotRuntimePrefix + "net_interface."
+
+	// NetworkPolicy is a sandbox annotation that specifies the JSON egress allow-list enforced on the VM network
+	// traffic, unless the runtime configuration sets one.
+	NetworkPolicy = kataAnnotRuntimePrefix + "network_policy"
+
+	// MonitorInterval is a sandbox annotation that specifies the time, in seconds, between two sandbox health checks.
+	MonitorInterval = kataAnnotRuntimePrefix + "monitor_interval"
+
+	// MonitorFailureThreshold is a sandbox annotation that specifies the number of consecutive failed sandbox
+	// health checks after which the monitor action is taken.
+	MonitorFailureThreshold = kataAnnotRuntimePrefix + "monitor_failure_threshold"
+
+	// MonitorGracePeriod is a sandbox annotation that specifies the time, in seconds, after the sandbox start
+	// during which no sandbox health check is done.
+	MonitorGracePeriod = kataAnnotRuntimePrefix + "monitor_grace_period"
+
+	// MonitorAction is a sandbox annotation that specifies the action taken when the sandbox is found unhealthy.
+	MonitorAction = kataAnnotRuntimePrefix + "monitor_action"
+
+	// Restartable is a sandbox annotation that determines if the sandbox VM is restarted when the hypervisor
+	// process dies.
+	Restartable = kataAnnotRuntimePrefix + "restartable"
+)
+
+// Network related annotations
+const (
+	kataAnnotNetworkPrefix = kataConfAnnotationsPrefix + "network."
+
+	// NetworkRxRateLimiterMaxRate is a sandbox annotation that specifies the maximum rate, in bits per second,
+	// of the traffic received by each VM network interface.
+	NetworkRxRateLimiterMaxRate = kataAnnotNetworkPrefix + "rx_rate_limiter_max_rate"
+
+	// NetworkTxRateLimiterMaxRate is a sandbox annotation that specifies the maximum rate, in bits per second,
+	// of the traffic sent by each VM network interface.
+	NetworkTxRateLimiterMaxRate = kataAnnotNetworkPrefix + "tx_rate_limiter_max_rate"
+
+	// K8sIngressBandwidth is the Kubernetes pod annotation that specifies the maximum rate of the traffic
+	// received by the pod, as a quantity of bits per second, e.g. "10M". NetworkRxRateLimiterMaxRate
+	// takes precedence over it.
+	K8sIngressBandwidth = "kubernetes.io/ingress-bandwidth"
+
+	// K8sEgressBandwidth is the Kubernetes pod annotation that specifies the maximum rate of the traffic
+	// sent by the pod, as a quantity of bits per second, e.g. "10M". NetworkTxRateLimiterMaxRate takes
+	// precedence over it.
+	K8sEgressBandwidth = "kubernetes.io/egress-bandwidth"
 )
 
 // Agent related annotations
@@ -0,0 +1,140 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package mtls builds the mutual TLS configurations of the runtime servers
+// and clients talking to each other across hosts. Both ends present a
+// certificate signed by the CA they verify the peer certificate with.
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// Config is a mutual TLS configuration.
+type Config struct {
+	// CertFile and KeyFile are the certificate and the key presented
+	// to the peer.
+	CertFile string
+	KeyFile  string
+
+	// CAFile is the certificate of the CA the peer certificate is
+	// verified with.
+	CAFile string
+}
+
+// Enabled tells whether any file of the configuration is set.
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || c.CAFile != ""
+}
+
+// Check checks all the files of the configuration are set.
+func (c Config) Check() error {
+	if c.CertFile == "" || c.KeyFile == "" || c.CAFile == "" {
+		return fmt.Errorf("Mutual TLS requires a certificate, a key and a CA certificate")
+	}
+
+	return nil
+}
+
+// load returns the certificate and the CA certificate pool of the config.
+func (c Config) load() (tls.Certificate, *x509.CertPool, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	ca, err := ioutil.ReadFile(c.CAFile)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return tls.Certificate{}, nil, fmt.Errorf("no CA certificate found in %s", c.CAFile)
+	}
+
+	return cert, pool, nil
+}
+
+// ServerConfig returns the TLS configuration of a server requiring and
+// verifying the certificate of its clients.
+func (c Config) ServerConfig() (*tls.Config, error) {
+	if err := c.Check(); err != nil {
+		return nil, err
+	}
+
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// ClientConfig returns the TLS configuration of a client presenting its
+// certificate. The server certificate chain is always verified, its host
+// name only when verifyHostname is set, e.g. there is none over vsock.
+func (c Config) ClientConfig(verifyHostname bool) (*tls.Config, error) {
+	if err := c.Check(); err != nil {
+		return nil, err
+	}
+
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if !verifyHostname {
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = verifyChain(pool)
+	}
+
+	return config, nil
+}
+
+// verifyChain returns a function verifying a server certificate chain is
+// signed by a CA of pool.
+func verifyChain(pool *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("no peer certificate")
+		}
+
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         pool,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		return err
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCertificate writes to dir a certificate for cn signed by the CA, or
+// self-signed without CA, and its key. It returns their paths along with
+// the certificate and the key.
+func testCertificate(assert *assert.Assertions, dir, cn string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (string, string, *x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		ca = template
+		caKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	assert.NoError(err)
+
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(err)
+
+	certPath := filepath.Join(dir, cn+".pem")
+	keyPath := filepath.Join(dir, cn+"-key.pem")
+	assert.NoError(ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	return certPath, keyPath, cert, key
+}
+
+func TestConfigCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(Config{}.Enabled())
+	assert.True(Config{CAFile: "/ca.pem"}.Enabled())
+
+	assert.Error(Config{}.Check())
+	assert.Error(Config{CertFile: "/cert.pem", KeyFile: "/key.pem"}.Check())
+	assert.NoError(Config{CertFile: "/cert.pem", KeyFile: "/key.pem", CAFile: "/ca.pem"}.Check())
+}
+
+func TestConfigs(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mtls")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	caPath, _, ca, caKey := testCertificate(assert, dir, "ca", nil, nil)
+	cert, key, serverCert, _ := testCertificate(assert, dir, "server", ca, caKey)
+	_, _, otherCA, otherCAKey := testCertificate(assert, dir, "other-ca", nil, nil)
+	_, _, otherCert, _ := testCertificate(assert, dir, "other", otherCA, otherCAKey)
+
+	_, err = Config{CertFile: cert, KeyFile: key}.ServerConfig()
+	assert.Error(err)
+	_, err = Config{CertFile: cert, KeyFile: key}.ClientConfig(true)
+	assert.Error(err)
+	_, err = Config{CertFile: cert, KeyFile: key, CAFile: key}.ClientConfig(true)
+	assert.Error(err)
+
+	config := Config{CertFile: cert, KeyFile: key, CAFile: caPath}
+
+	tlsConfig, err := config.ServerConfig()
+	assert.NoError(err)
+	assert.Equal(tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+	assert.NotNil(tlsConfig.ClientCAs)
+
+	tlsConfig, err = config.ClientConfig(true)
+	assert.NoError(err)
+	assert.False(tlsConfig.InsecureSkipVerify)
+	assert.Len(tlsConfig.Certificates, 1)
+
+	tlsConfig, err = config.ClientConfig(false)
+	assert.NoError(err)
+	assert.True(tlsConfig.InsecureSkipVerify)
+
+	// the server chain is verified without host name
+	verify := tlsConfig.VerifyPeerCertificate
+	assert.NoError(verify([][]byte{serverCert.Raw}, nil))
+	assert.Error(verify(nil, nil))
+	assert.Error(verify([][]byte{otherCert.Raw}, nil))
+}
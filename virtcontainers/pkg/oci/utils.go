@@ -9,12 +9,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"path/filepath"
 	"regexp"
 	goruntime "runtime"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	criContainerdAnnotations "github.com/containerd/cri-containerd/pkg/annotations"
 	crioAnnotations "github.com/cri-o/cri-o/pkg/annotations"
@@ -26,6 +28,7 @@ import (
 	exp "github.com/kata-containers/runtime/virtcontainers/experimental"
 	vcAnnotations "github.com/kata-containers/runtime/virtcontainers/pkg/annotations"
 	dockershimAnnotations "github.com/kata-containers/runtime/virtcontainers/pkg/annotations/dockershim"
+	"github.com/kata-containers/runtime/virtcontainers/pkg/mtls"
 	"github.com/kata-containers/runtime/virtcontainers/types"
 )
 
@@ -86,8 +89,53 @@ type FactoryConfig struct {
 	// VMCacheNumber specifies the the number of caches of VMCache.
 	VMCacheNumber uint
 
+	// VMCacheMaxNumber specifies the number of caches VMCache grows up
+	// to when it runs out of VMs.
+	VMCacheMaxNumber uint
+
+	// VMCacheRefillInterval specifies the minimum interval between two
+	// VM boots of VMCache.
+	VMCacheRefillInterval time.Duration
+
+	// VMCacheIdleTimeout specifies the time after which VMCache stops an
+	// unused VM above VMCacheNumber.
+	VMCacheIdleTimeout time.Duration
+
 	// VMCacheEndpoint specifies the endpoint of transport VM from the VM cache server to runtime.
 	VMCacheEndpoint string
+
+	// VMCacheTLSCert, VMCacheTLSKey and VMCacheTLSCA specify the
+	// certificate, the key and the CA certificate of the VMCache mutual
+	// TLS, required by the TCP and vsock endpoints.
+	VMCacheTLSCert string
+	VMCacheTLSKey  string
+	VMCacheTLSCA   string
+
+	// VMCacheClientQuota specifies the number of VMs each client gets
+	// from the VM cache server per minute, 0 not limiting the clients.
+	VMCacheClientQuota uint
+
+	// Pools are the named VM pools the factory holds besides the VMs
+	// created from the hypervisor configuration.
+	Pools []FactoryPoolConfig
+}
+
+// FactoryPoolConfig is a structure to set a named VM factory pool. The VMs
+// of a pool are created from the hypervisor configuration, the non zero
+// values of the pool overriding it.
+type FactoryPoolConfig struct {
+	Name string
+
+	// VMCacheNumber specifies the number of VMs VMCache holds in the pool.
+	VMCacheNumber uint
+
+	// VMCacheMaxNumber specifies the number of VMs VMCache holds in the
+	// pool when it runs out of VMs.
+	VMCacheMaxNumber uint
+
+	NumVCPUs   uint32
+	MemorySize uint32
+	KernelPath string
 }
 
 // RuntimeConfig aggregates all runtime specific settings
@@ -129,6 +177,15 @@ type RuntimeConfig struct {
 
 	//Experimental features enabled
 	Experimental []exp.Feature
+
+	//Determines the sandbox health-check policy
+	MonitorConfig vc.MonitorConfig
+
+	//Egress allow-list enforced on the VM network traffic
+	NetworkPolicy *vc.NetworkPolicy
+
+	//Mutual TLS of the live migrations over tcp
+	MigrationTLS mtls.Config
 }
 
 // AddKernelParam allows the addition of new kernel parameters to an existing
@@ -306,11 +363,14 @@ func networkConfig(ocispec specs.Spec, config RuntimeConfig) (vc.NetworkConfig,
 	}
 	netConf.InterworkingModel = config.InterNetworkModel
 	netConf.DisableNewNetNs = config.DisableNewNetNs
+	netConf.Policy = config.NetworkPolicy
 
 	netConf.NetmonConfig = vc.NetmonConfig{
 		Path:   config.NetmonConfig.Path,
 		Debug:  config.NetmonConfig.Debug,
 		Enable: config.NetmonConfig.Enable,
+
+		InProcess: config.NetmonConfig.InProcess,
 	}
 
 	return netConf, nil
@@ -388,6 +448,10 @@ func addAnnotations(ocispec specs.Spec, config *vc.SandboxConfig, runtimeConfig
 	if err := addAgentConfigOverrides(ocispec, config); err != nil {
 		return err
 	}
+
+	if err := addNetworkConfigOverrides(ocispec, config); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -847,6 +911,214 @@ func addRuntimeConfigOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig, r
 		sbConfig.NetworkConfig.InterworkingModel = runtimeConfig.InterNetworkModel
 	}
 
+	if err := addNetInterfaceOverrides(ocispec, sbConfig); err != nil {
+		return err
+	}
+
+	if value, ok := ocispec.Annotations[vcAnnotations.NetworkPolicy]; ok {
+		// The workload can't replace the policy of the operator.
+		if runtimeConfig.NetworkPolicy != nil {
+			return fmt.Errorf("Error parsing annotation %s: The network policy is set by the runtime configuration", vcAnnotations.NetworkPolicy)
+		}
+
+		policy, err := vc.ParseNetworkPolicy([]byte(value))
+		if err != nil {
+			return fmt.Errorf("Error parsing annotation %s: %v", vcAnnotations.NetworkPolicy, err)
+		}
+		sbConfig.NetworkConfig.Policy = policy
+	}
+
+	return addMonitorConfigOverrides(ocispec, sbConfig)
+}
+
+func addNetInterfaceOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig) error {
+	for key, value := range ocispec.Annotations {
+		if !strings.HasPrefix(key, vcAnnotations.NetInterfacePrefix) {
+			continue
+		}
+
+		// The interface name may contain dots, the option doesn't.
+		i := strings.LastIndex(key, ".")
+		if i < len(vcAnnotations.NetInterfacePrefix)+1 {
+			return fmt.Errorf("Error parsing annotation %s: Please specify an interface and an option", key)
+		}
+		name, option := key[len(vcAnnotations.NetInterfacePrefix):i], key[i+1:]
+
+		if sbConfig.NetworkConfig.Interfaces == nil {
+			sbConfig.NetworkConfig.Interfaces = make(map[string]vc.NetInterfaceConfig)
+		}
+
+		c := sbConfig.NetworkConfig.Interfaces[name]
+		if err := setNetInterfaceOption(&c, option, value); err != nil {
+			return fmt.Errorf("Error parsing annotation %s: %v", key, err)
+		}
+
+		// A queue pair is handled by a vCPU.
+		if numVCPUs := sbConfig.HypervisorConfig.NumVCPUs; numVCPUs > 0 && c.Queues > numVCPUs {
+			return fmt.Errorf("Error parsing annotation %s: Please specify at most %d queues, the number of vCPUs", key, numVCPUs)
+		}
+		sbConfig.NetworkConfig.Interfaces[name] = c
+	}
+
+	return nil
+}
+
+// maxNetInterfaceQueues is the maximum number of queues of a TAP device.
+const maxNetInterfaceQueues = 256
+
+func setNetInterfaceOption(c *vc.NetInterfaceConfig, option, value string) error {
+	var err error
+
+	switch option {
+	case "queues":
+		var queues uint64
+		if queues, err = strconv.ParseUint(value, 10, 32); err != nil || queues == 0 || queues > maxNetInterfaceQueues {
+			return fmt.Errorf("Please specify a number of queues between 1 and %d", maxNetInterfaceQueues)
+		}
+		c.Queues = uint32(queues)
+	case "mtu":
+		var mtu uint64
+		if mtu, err = strconv.ParseUint(value, 10, 32); err != nil || mtu < 68 || mtu > 65535 {
+			return fmt.Errorf("Please specify a MTU between 68 and 65535")
+		}
+		c.MTU = uint32(mtu)
+	case "rx_queue_size":
+		c.RxQueueSize, err = parseNetQueueSize(value)
+	case "tx_queue_size":
+		c.TxQueueSize, err = parseNetQueueSize(value)
+	case "disable_vhost_net":
+		c.DisableVhostNet, err = strconv.ParseBool(value)
+	case "disable_checksum":
+		c.DisableChecksum, err = strconv.ParseBool(value)
+	case "disable_tso":
+		c.DisableTSO, err = strconv.ParseBool(value)
+	case "disable_gso":
+		c.DisableGSO, err = strconv.ParseBool(value)
+	case "rx_rate_limiter_max_rate":
+		c.RxRateLimiterMaxRate, err = parseMaxRate(value)
+	case "tx_rate_limiter_max_rate":
+		c.TxRateLimiterMaxRate, err = parseMaxRate(value)
+	default:
+		return fmt.Errorf("Unknown network interface option %s", option)
+	}
+
+	return err
+}
+
+// parseNetQueueSize parses a virtqueue size, a power of 2 between 256 and
+// 1024.
+func parseNetQueueSize(value string) (uint32, error) {
+	size, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || size < 256 || size > 1024 || size&(size-1) != 0 {
+		return 0, fmt.Errorf("Please specify a queue size power of 2 between 256 and 1024")
+	}
+
+	return uint32(size), nil
+}
+
+// parseMaxRate parses a positive number of bits per second.
+func parseMaxRate(value string) (uint64, error) {
+	rate, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || rate == 0 {
+		return 0, fmt.Errorf("Please specify a positive number of bits per second")
+	}
+
+	return rate, nil
+}
+
+// bandwidthSuffixes are the suffixes of the Kubernetes quantities, the binary
+// ones first as they extend the decimal ones.
+var bandwidthSuffixes = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50}, {"Ei", 1 << 60},
+	{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+}
+
+// parseBandwidth parses a Kubernetes bandwidth annotation, a quantity of bits
+// per second such as "10M" or "1Gi".
+func parseBandwidth(value string) (uint64, error) {
+	number, multiplier := value, float64(1)
+	for _, s := range bandwidthSuffixes {
+		if strings.HasSuffix(value, s.suffix) {
+			number, multiplier = strings.TrimSuffix(value, s.suffix), s.multiplier
+			break
+		}
+	}
+
+	rate, err := strconv.ParseFloat(number, 64)
+	if err != nil || rate*multiplier < 1 || rate*multiplier > math.MaxUint64 {
+		return 0, fmt.Errorf("Please specify a positive quantity of bits per second")
+	}
+
+	return uint64(rate * multiplier), nil
+}
+
+func addNetworkConfigOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig) error {
+	for _, a := range []struct {
+		key     string
+		k8sKey  string
+		maxRate *uint64
+	}{
+		{vcAnnotations.NetworkRxRateLimiterMaxRate, vcAnnotations.K8sIngressBandwidth, &sbConfig.NetworkConfig.RxRateLimiterMaxRate},
+		{vcAnnotations.NetworkTxRateLimiterMaxRate, vcAnnotations.K8sEgressBandwidth, &sbConfig.NetworkConfig.TxRateLimiterMaxRate},
+	} {
+		var err error
+
+		if value, ok := ocispec.Annotations[a.key]; ok {
+			if *a.maxRate, err = parseMaxRate(value); err != nil {
+				return fmt.Errorf("Error parsing annotation %s: %v", a.key, err)
+			}
+		} else if value, ok := ocispec.Annotations[a.k8sKey]; ok {
+			if *a.maxRate, err = parseBandwidth(value); err != nil {
+				return fmt.Errorf("Error parsing annotation %s: %v", a.k8sKey, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func addMonitorConfigOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig) error {
+	if value, ok := ocispec.Annotations[vcAnnotations.MonitorInterval]; ok {
+		interval, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || interval == 0 {
+			return fmt.Errorf("Error parsing annotation for %s: Please specify a positive number of seconds", vcAnnotations.MonitorInterval)
+		}
+		sbConfig.MonitorConfig.Interval = time.Duration(interval) * time.Second
+	}
+
+	if value, ok := ocispec.Annotations[vcAnnotations.MonitorFailureThreshold]; ok {
+		threshold, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("Error parsing annotation for %s: Please specify uint32 value", vcAnnotations.MonitorFailureThreshold)
+		}
+		sbConfig.MonitorConfig.FailureThreshold = uint32(threshold)
+	}
+
+	if value, ok := ocispec.Annotations[vcAnnotations.MonitorGracePeriod]; ok {
+		gracePeriod, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("Error parsing annotation for %s: Please specify a number of seconds", vcAnnotations.MonitorGracePeriod)
+		}
+		sbConfig.MonitorConfig.GracePeriod = time.Duration(gracePeriod) * time.Second
+	}
+
+	if value, ok := ocispec.Annotations[vcAnnotations.MonitorAction]; ok {
+		if err := sbConfig.MonitorConfig.Action.SetAction(value); err != nil {
+			return fmt.Errorf("Unknown monitor action specified in annotation %s", vcAnnotations.MonitorAction)
+		}
+	}
+
+	if value, ok := ocispec.Annotations[vcAnnotations.Restartable]; ok {
+		restartable, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("Error parsing annotation for %s: Please specify boolean value 'true|false'", vcAnnotations.Restartable)
+		}
+		sbConfig.MonitorConfig.Restartable = restartable
+	}
+
 	return nil
 }
 
@@ -928,6 +1200,8 @@ func SandboxConfig(ocispec specs.Spec, runtimeConfig RuntimeConfig, bundlePath,
 
 		NetworkConfig: networkConfig,
 
+		MonitorConfig: runtimeConfig.MonitorConfig,
+
 		Containers: []vc.ContainerConfig{containerConfig},
 
 		Annotations: map[string]string{
@@ -1048,13 +1322,24 @@ func getShmSize(c vc.ContainerConfig) (uint64, error) {
 
 // StatusToOCIState translates a virtcontainers container status into an OCI state.
 func StatusToOCIState(status vc.ContainerStatus) specs.State {
+	annotations := status.Annotations
+	if status.GuestPID != 0 {
+		// The status annotations are the container ones, they must
+		// not be modified.
+		annotations = make(map[string]string, len(status.Annotations)+1)
+		for k, v := range status.Annotations {
+			annotations[k] = v
+		}
+		annotations[vcAnnotations.GuestPidKey] = strconv.Itoa(status.GuestPID)
+	}
+
 	return specs.State{
 		Version:     specs.Version,
 		ID:          status.ID,
 		Status:      StateToOCIState(status.State.State),
 		Pid:         status.PID,
 		Bundle:      status.Annotations[vcAnnotations.BundlePathKey],
-		Annotations: status.Annotations,
+		Annotations: annotations,
 	}
 }
 
@@ -1126,3 +1411,15 @@ func IsCRIOContainerManager(spec *specs.Spec) bool {
 	}
 	return false
 }
+
+// IsRestartableSandbox checks if the VM of a Pod may be restarted by the
+// monitor, as config overridden by the Pod annotations asks for it either
+// when its hypervisor process dies or when its health checks fail.
+func IsRestartableSandbox(spec *specs.Spec, config vc.MonitorConfig) bool {
+	sbConfig := vc.SandboxConfig{MonitorConfig: config}
+	if err := addMonitorConfigOverrides(*spec, &sbConfig); err != nil {
+		return false
+	}
+
+	return sbConfig.MonitorConfig.Restartable || sbConfig.MonitorConfig.Action == vc.MonitorActionRestartVM
+}
@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cri-o/cri-o/pkg/annotations"
 	crioAnnotations "github.com/cri-o/cri-o/pkg/annotations"
@@ -332,6 +333,46 @@ func TestStatusToOCIStateSuccessfulWithNoState(t *testing.T) {
 
 }
 
+func TestStatusToOCIStateSuccessfulWithGuestPID(t *testing.T) {
+	testContID := "testContID"
+	testPID := 12345
+	testRootFs := "testRootFs"
+
+	state := types.ContainerState{
+		State: types.StateRunning,
+	}
+
+	containerAnnotations := map[string]string{
+		vcAnnotations.BundlePathKey: tempBundlePath,
+	}
+
+	cStatus := vc.ContainerStatus{
+		ID:          testContID,
+		State:       state,
+		PID:         testPID,
+		GuestPID:    42,
+		RootFs:      testRootFs,
+		Annotations: containerAnnotations,
+	}
+
+	expected := specs.State{
+		Version: specs.Version,
+		ID:      testContID,
+		Status:  "running",
+		Pid:     testPID,
+		Bundle:  tempBundlePath,
+		Annotations: map[string]string{
+			vcAnnotations.BundlePathKey: tempBundlePath,
+			vcAnnotations.GuestPidKey:   "42",
+		},
+	}
+
+	testStatusToOCIStateSuccessful(t, cStatus, expected)
+
+	// the container annotations are left untouched
+	assert.NotContains(t, containerAnnotations, vcAnnotations.GuestPidKey)
+}
+
 func TestStateToOCIState(t *testing.T) {
 	var state types.StateString
 	assert := assert.New(t)
@@ -1022,6 +1063,210 @@ func TestAddRuntimeAnnotations(t *testing.T) {
 	assert.Equal(config.NetworkConfig.InterworkingModel, vc.NetXConnectMacVtapModel)
 }
 
+func TestAddNetInterfaceAnnotations(t *testing.T) {
+	assert := assert.New(t)
+
+	ocispec := specs.Spec{
+		Annotations: map[string]string{
+			vcAnnotations.NetInterfacePrefix + "eth0.queues":                    "2",
+			vcAnnotations.NetInterfacePrefix + "eth0.mtu":                       "1400",
+			vcAnnotations.NetInterfacePrefix + "eth0.rx_queue_size":             "1024",
+			vcAnnotations.NetInterfacePrefix + "eth0.tx_queue_size":             "256",
+			vcAnnotations.NetInterfacePrefix + "eth0.disable_vhost_net":         "true",
+			vcAnnotations.NetInterfacePrefix + "eth0.disable_checksum":          "true",
+			vcAnnotations.NetInterfacePrefix + "eth0.disable_tso":               "true",
+			vcAnnotations.NetInterfacePrefix + "eth0.disable_gso":               "true",
+			vcAnnotations.NetInterfacePrefix + "net.1.mtu":                      "9000",
+			vcAnnotations.NetInterfacePrefix + "net.1.rx_rate_limiter_max_rate": "1000000",
+			vcAnnotations.NetInterfacePrefix + "net.1.tx_rate_limiter_max_rate": "2000000",
+		},
+	}
+
+	config := vc.SandboxConfig{HypervisorConfig: vc.HypervisorConfig{NumVCPUs: 2}}
+	assert.NoError(addNetInterfaceOverrides(ocispec, &config))
+	assert.Equal(map[string]vc.NetInterfaceConfig{
+		"eth0": {
+			Queues:          2,
+			MTU:             1400,
+			RxQueueSize:     1024,
+			TxQueueSize:     256,
+			DisableVhostNet: true,
+			DisableChecksum: true,
+			DisableTSO:      true,
+			DisableGSO:      true,
+		},
+		"net.1": {
+			MTU:                  9000,
+			RxRateLimiterMaxRate: 1000000,
+			TxRateLimiterMaxRate: 2000000,
+		},
+	}, config.NetworkConfig.Interfaces)
+
+	for key, value := range map[string]string{
+		vcAnnotations.NetInterfacePrefix + "eth0.queues":                   "0",
+		vcAnnotations.NetInterfacePrefix + "eth1.queues":                   "257",
+		vcAnnotations.NetInterfacePrefix + "eth2.queues":                   "3",
+		vcAnnotations.NetInterfacePrefix + "eth0.mtu":                      "10",
+		vcAnnotations.NetInterfacePrefix + "eth0.rx_queue_size":            "300",
+		vcAnnotations.NetInterfacePrefix + "eth0.tx_queue_size":            "2048",
+		vcAnnotations.NetInterfacePrefix + "eth0.disable_tso":              "foo",
+		vcAnnotations.NetInterfacePrefix + "eth0.rx_rate_limiter_max_rate": "0",
+		vcAnnotations.NetInterfacePrefix + "eth0.foo":                      "1",
+		vcAnnotations.NetInterfacePrefix + "mtu":                           "1400",
+	} {
+		ocispec.Annotations = map[string]string{key: value}
+		assert.Error(addNetInterfaceOverrides(ocispec, &vc.SandboxConfig{HypervisorConfig: vc.HypervisorConfig{NumVCPUs: 2}}), key)
+	}
+}
+
+func TestAddNetworkPolicyAnnotation(t *testing.T) {
+	assert := assert.New(t)
+
+	ocispec := specs.Spec{
+		Annotations: map[string]string{
+			vcAnnotations.NetworkPolicy: `{"egress": [{"cidr": "10.0.0.0/8", "protocol": "tcp", "ports": [443]}]}`,
+		},
+	}
+
+	config := vc.SandboxConfig{}
+	assert.NoError(addRuntimeConfigOverrides(ocispec, &config, RuntimeConfig{}))
+	assert.Equal(&vc.NetworkPolicy{
+		Egress: []vc.NetworkPolicyRule{{CIDR: "10.0.0.0/8", Protocol: "tcp", Ports: []uint16{443}}},
+	}, config.NetworkConfig.Policy)
+
+	// The policy of the runtime configuration can't be replaced.
+	runtimeConfig := RuntimeConfig{NetworkPolicy: &vc.NetworkPolicy{}}
+	assert.Error(addRuntimeConfigOverrides(ocispec, &vc.SandboxConfig{}, runtimeConfig))
+
+	ocispec.Annotations[vcAnnotations.NetworkPolicy] = `{"egress": [{"cidr": "10.0.0.0/8", "ports": [443]}]}`
+	assert.Error(addRuntimeConfigOverrides(ocispec, &vc.SandboxConfig{}, RuntimeConfig{}))
+}
+
+func TestAddNetworkRateLimiterAnnotations(t *testing.T) {
+	assert := assert.New(t)
+
+	ocispec := specs.Spec{
+		Annotations: map[string]string{
+			vcAnnotations.NetworkRxRateLimiterMaxRate: "1000000",
+			vcAnnotations.K8sIngressBandwidth:         "1G",
+			vcAnnotations.K8sEgressBandwidth:          "10Mi",
+		},
+	}
+
+	config := vc.SandboxConfig{}
+	assert.NoError(addNetworkConfigOverrides(ocispec, &config))
+	assert.Equal(uint64(1000000), config.NetworkConfig.RxRateLimiterMaxRate)
+	assert.Equal(uint64(10*1024*1024), config.NetworkConfig.TxRateLimiterMaxRate)
+
+	for key, value := range map[string]string{
+		vcAnnotations.NetworkRxRateLimiterMaxRate: "0",
+		vcAnnotations.NetworkTxRateLimiterMaxRate: "10M",
+		vcAnnotations.K8sIngressBandwidth:         "10m",
+		vcAnnotations.K8sEgressBandwidth:          "-1M",
+	} {
+		ocispec.Annotations = map[string]string{key: value}
+		assert.Error(addNetworkConfigOverrides(ocispec, &vc.SandboxConfig{}), key)
+	}
+}
+
+func TestParseBandwidth(t *testing.T) {
+	assert := assert.New(t)
+
+	for value, rate := range map[string]uint64{
+		"1000": 1000,
+		"10k":  10000,
+		"1.5M": 1500000,
+		"1G":   1000000000,
+		"1Ki":  1024,
+		"2Gi":  2 * 1024 * 1024 * 1024,
+	} {
+		r, err := parseBandwidth(value)
+		assert.NoError(err, value)
+		assert.Equal(rate, r, value)
+	}
+
+	for _, value := range []string{"", "M", "0", "0.1", "1m", "foo"} {
+		_, err := parseBandwidth(value)
+		assert.Error(err, value)
+	}
+}
+
+func TestAddMonitorAnnotations(t *testing.T) {
+	assert := assert.New(t)
+
+	config := vc.SandboxConfig{
+		Annotations: make(map[string]string),
+	}
+
+	ocispec := specs.Spec{
+		Annotations: make(map[string]string),
+	}
+
+	runtimeConfig := RuntimeConfig{
+		HypervisorType: vc.QemuHypervisor,
+		Console:        consolePath,
+	}
+
+	ocispec.Annotations[vcAnnotations.MonitorInterval] = "5"
+	ocispec.Annotations[vcAnnotations.MonitorFailureThreshold] = "3"
+	ocispec.Annotations[vcAnnotations.MonitorGracePeriod] = "30"
+	ocispec.Annotations[vcAnnotations.MonitorAction] = "report"
+	ocispec.Annotations[vcAnnotations.Restartable] = "true"
+
+	err := addAnnotations(ocispec, &config, runtimeConfig)
+	assert.NoError(err)
+	assert.Equal(vc.MonitorConfig{
+		Interval:         5 * time.Second,
+		FailureThreshold: 3,
+		GracePeriod:      30 * time.Second,
+		Action:           vc.MonitorActionReport,
+		Restartable:      true,
+	}, config.MonitorConfig)
+
+	for key, value := range map[string]string{
+		vcAnnotations.MonitorInterval:         "0",
+		vcAnnotations.MonitorFailureThreshold: "-1",
+		vcAnnotations.MonitorGracePeriod:      "foo",
+		vcAnnotations.MonitorAction:           "foo",
+		vcAnnotations.Restartable:             "foo",
+	} {
+		ocispec.Annotations = map[string]string{key: value}
+		err = addAnnotations(ocispec, &config, runtimeConfig)
+		assert.Error(err, key)
+	}
+}
+
+func TestIsRestartableSandbox(t *testing.T) {
+	assert := assert.New(t)
+
+	for value, restartable := range map[string]bool{
+		"":      false,
+		"foo":   false,
+		"false": false,
+		"true":  true,
+	} {
+		spec := &specs.Spec{
+			Annotations: map[string]string{vcAnnotations.Restartable: value},
+		}
+		assert.Equal(restartable, IsRestartableSandbox(spec, vc.MonitorConfig{}), value)
+	}
+
+	assert.False(IsRestartableSandbox(&specs.Spec{}, vc.MonitorConfig{}))
+	assert.True(IsRestartableSandbox(&specs.Spec{}, vc.MonitorConfig{Restartable: true}))
+
+	// the VM is restarted on failed health checks
+	config := vc.MonitorConfig{Action: vc.MonitorActionRestartVM}
+	assert.True(IsRestartableSandbox(&specs.Spec{}, config))
+
+	spec := &specs.Spec{
+		Annotations: map[string]string{vcAnnotations.MonitorAction: string(vc.MonitorActionStop)},
+	}
+	assert.False(IsRestartableSandbox(spec, config))
+
+	spec.Annotations[vcAnnotations.MonitorAction] = string(vc.MonitorActionRestartVM)
+	assert.True(IsRestartableSandbox(spec, vc.MonitorConfig{}))
+}
+
 func TestIsCRIOContainerManager(t *testing.T) {
 	assert := assert.New(t)
 
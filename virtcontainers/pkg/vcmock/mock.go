@@ -17,6 +17,7 @@ package vcmock
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"syscall"
 
@@ -92,6 +93,15 @@ func (m *VCMock) StopSandbox(ctx context.Context, sandboxID string, force bool)
 	return nil, fmt.Errorf("%s: %s (%+v): sandboxID: %v", mockErrorPrefix, getSelf(), m, sandboxID)
 }
 
+// MigrateSandbox implements the VC function of the same name.
+func (m *VCMock) MigrateSandbox(ctx context.Context, sandboxID, target string, tlsConfig *tls.Config) error {
+	if m.MigrateSandboxFunc != nil {
+		return m.MigrateSandboxFunc(ctx, sandboxID, target, tlsConfig)
+	}
+
+	return fmt.Errorf("%s: %s (%+v): sandboxID: %v", mockErrorPrefix, getSelf(), m, sandboxID)
+}
+
 // RunSandbox implements the VC function of the same name.
 func (m *VCMock) RunSandbox(ctx context.Context, sandboxConfig vc.SandboxConfig) (vc.VCSandbox, error) {
 	if m.RunSandboxFunc != nil {
@@ -263,6 +273,15 @@ func (m *VCMock) RemoveInterface(ctx context.Context, sandboxID string, inf *vcT
 	return nil, fmt.Errorf("%s: %s (%+v): sandboxID: %v", mockErrorPrefix, getSelf(), m, sandboxID)
 }
 
+// UpdateInterface implements the VC function of the same name.
+func (m *VCMock) UpdateInterface(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	if m.UpdateInterfaceFunc != nil {
+		return m.UpdateInterfaceFunc(ctx, sandboxID, inf)
+	}
+
+	return nil, fmt.Errorf("%s: %s (%+v): sandboxID: %v", mockErrorPrefix, getSelf(), m, sandboxID)
+}
+
 // ListInterfaces implements the VC function of the same name.
 func (m *VCMock) ListInterfaces(ctx context.Context, sandboxID string) ([]*vcTypes.Interface, error) {
 	if m.ListInterfacesFunc != nil {
@@ -290,6 +309,15 @@ func (m *VCMock) ListRoutes(ctx context.Context, sandboxID string) ([]*vcTypes.R
 	return nil, fmt.Errorf("%s: %s (%+v): sandboxID: %v", mockErrorPrefix, getSelf(), m, sandboxID)
 }
 
+// AddNeighbors implements the VC function of the same name.
+func (m *VCMock) AddNeighbors(ctx context.Context, sandboxID string, neighs []*vcTypes.ARPNeighbor) error {
+	if m.AddNeighborsFunc != nil {
+		return m.AddNeighborsFunc(ctx, sandboxID, neighs)
+	}
+
+	return fmt.Errorf("%s: %s (%+v): sandboxID: %v", mockErrorPrefix, getSelf(), m, sandboxID)
+}
+
 func (m *VCMock) CleanupContainer(ctx context.Context, sandboxID, containerID string, force bool) error {
 	if m.CleanupContainerFunc != nil {
 		return m.CleanupContainerFunc(ctx, sandboxID, containerID, true)
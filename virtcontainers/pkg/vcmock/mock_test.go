@@ -7,6 +7,7 @@ package vcmock
 
 import (
 	"context"
+	"crypto/tls"
 	"reflect"
 	"syscall"
 	"testing"
@@ -305,6 +306,32 @@ func TestVCMockStopSandbox(t *testing.T) {
 	assert.True(IsMockError(err))
 }
 
+func TestVCMockMigrateSandbox(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &VCMock{}
+	assert.Nil(m.MigrateSandboxFunc)
+
+	ctx := context.Background()
+	err := m.MigrateSandbox(ctx, testSandboxID, "tcp:localhost:4444", nil)
+	assert.Error(err)
+	assert.True(IsMockError(err))
+
+	m.MigrateSandboxFunc = func(ctx context.Context, sandboxID, target string, tlsConfig *tls.Config) error {
+		return nil
+	}
+
+	err = m.MigrateSandbox(ctx, testSandboxID, "tcp:localhost:4444", nil)
+	assert.NoError(err)
+
+	// reset
+	m.MigrateSandboxFunc = nil
+
+	err = m.MigrateSandbox(ctx, testSandboxID, "tcp:localhost:4444", nil)
+	assert.Error(err)
+	assert.True(IsMockError(err))
+}
+
 func TestVCMockCreateContainer(t *testing.T) {
 	assert := assert.New(t)
 
@@ -737,6 +764,60 @@ func TestVCMockRemoveInterface(t *testing.T) {
 	assert.True(IsMockError(err))
 }
 
+func TestVCMockUpdateInterface(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &VCMock{}
+	config := &vc.SandboxConfig{}
+	assert.Nil(m.UpdateInterfaceFunc)
+
+	ctx := context.Background()
+	_, err := m.UpdateInterface(ctx, config.ID, nil)
+	assert.Error(err)
+	assert.True(IsMockError(err))
+
+	m.UpdateInterfaceFunc = func(ctx context.Context, sid string, inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+		return nil, nil
+	}
+
+	_, err = m.UpdateInterface(ctx, config.ID, nil)
+	assert.NoError(err)
+
+	// reset
+	m.UpdateInterfaceFunc = nil
+
+	_, err = m.UpdateInterface(ctx, config.ID, nil)
+	assert.Error(err)
+	assert.True(IsMockError(err))
+}
+
+func TestVCMockAddNeighbors(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &VCMock{}
+	config := &vc.SandboxConfig{}
+	assert.Nil(m.AddNeighborsFunc)
+
+	ctx := context.Background()
+	err := m.AddNeighbors(ctx, config.ID, nil)
+	assert.Error(err)
+	assert.True(IsMockError(err))
+
+	m.AddNeighborsFunc = func(ctx context.Context, sid string, neighs []*vcTypes.ARPNeighbor) error {
+		return nil
+	}
+
+	err = m.AddNeighbors(ctx, config.ID, nil)
+	assert.NoError(err)
+
+	// reset
+	m.AddNeighborsFunc = nil
+
+	err = m.AddNeighbors(ctx, config.ID, nil)
+	assert.Error(err)
+	assert.True(IsMockError(err))
+}
+
 func TestVCMockListInterfaces(t *testing.T) {
 	assert := assert.New(t)
 
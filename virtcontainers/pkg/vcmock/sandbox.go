@@ -6,6 +6,7 @@
 package vcmock
 
 import (
+	"crypto/tls"
 	"io"
 	"syscall"
 
@@ -153,6 +154,11 @@ func (s *Sandbox) Monitor() (chan error, error) {
 	return nil, nil
 }
 
+// Events implements the VCSandbox function of the same name.
+func (s *Sandbox) Events() <-chan vc.Event {
+	return nil
+}
+
 // UpdateContainer implements the VCSandbox function of the same name.
 func (s *Sandbox) UpdateContainer(containerID string, resources specs.LinuxResources) error {
 	return nil
@@ -168,6 +174,11 @@ func (s *Sandbox) WaitProcess(containerID, processID string) (int32, error) {
 	return 0, nil
 }
 
+// ProcessStats implements the VCSandbox function of the same name.
+func (s *Sandbox) ProcessStats(containerID string) ([]vc.ProcessStats, error) {
+	return nil, nil
+}
+
 // SignalProcess implements the VCSandbox function of the same name.
 func (s *Sandbox) SignalProcess(containerID, processID string, signal syscall.Signal, all bool) error {
 	return nil
@@ -198,6 +209,11 @@ func (s *Sandbox) RemoveInterface(inf *vcTypes.Interface) (*vcTypes.Interface, e
 	return nil, nil
 }
 
+// UpdateInterface implements the VCSandbox function of the same name.
+func (s *Sandbox) UpdateInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	return nil, nil
+}
+
 // ListInterfaces implements the VCSandbox function of the same name.
 func (s *Sandbox) ListInterfaces() ([]*vcTypes.Interface, error) {
 	return nil, nil
@@ -213,6 +229,26 @@ func (s *Sandbox) ListRoutes() ([]*vcTypes.Route, error) {
 	return nil, nil
 }
 
+// AddNeighbors implements the VCSandbox function of the same name.
+func (s *Sandbox) AddNeighbors(neighs []*vcTypes.ARPNeighbor) error {
+	return nil
+}
+
 func (s *Sandbox) GetOOMEvent() (string, error) {
 	return "", nil
 }
+
+// Checkpoint implements the VCSandbox function of the same name.
+func (s *Sandbox) Checkpoint(dir string) error {
+	return nil
+}
+
+// Migrate implements the VCSandbox function of the same name.
+func (s *Sandbox) Migrate(target string, tlsConfig *tls.Config) error {
+	return nil
+}
+
+// RestartVM implements the VCSandbox function of the same name.
+func (s *Sandbox) RestartVM() error {
+	return nil
+}
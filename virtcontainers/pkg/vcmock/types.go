@@ -7,6 +7,7 @@ package vcmock
 
 import (
 	"context"
+	"crypto/tls"
 	"syscall"
 
 	vc "github.com/kata-containers/runtime/virtcontainers"
@@ -54,6 +55,7 @@ type VCMock struct {
 	StatsContainerFunc func(ctx context.Context, sandboxID, containerID string) (vc.ContainerStats, error)
 	StatsSandboxFunc   func(ctx context.Context, sandboxID string) (vc.SandboxStats, []vc.ContainerStats, error)
 	StopSandboxFunc    func(ctx context.Context, sandboxID string, force bool) (vc.VCSandbox, error)
+	MigrateSandboxFunc func(ctx context.Context, sandboxID, target string, tlsConfig *tls.Config) error
 
 	CreateContainerFunc      func(ctx context.Context, sandboxID string, containerConfig vc.ContainerConfig) (vc.VCSandbox, vc.VCContainer, error)
 	DeleteContainerFunc      func(ctx context.Context, sandboxID, containerID string) (vc.VCContainer, error)
@@ -71,8 +73,10 @@ type VCMock struct {
 
 	AddInterfaceFunc     func(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error)
 	RemoveInterfaceFunc  func(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error)
+	UpdateInterfaceFunc  func(ctx context.Context, sandboxID string, inf *vcTypes.Interface) (*vcTypes.Interface, error)
 	ListInterfacesFunc   func(ctx context.Context, sandboxID string) ([]*vcTypes.Interface, error)
 	UpdateRoutesFunc     func(ctx context.Context, sandboxID string, routes []*vcTypes.Route) ([]*vcTypes.Route, error)
 	ListRoutesFunc       func(ctx context.Context, sandboxID string) ([]*vcTypes.Route, error)
+	AddNeighborsFunc     func(ctx context.Context, sandboxID string, neighs []*vcTypes.ARPNeighbor) error
 	CleanupContainerFunc func(ctx context.Context, sandboxID, containerID string, force bool) error
 }
@@ -0,0 +1,165 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+// processStatsFields are the ps(1) fields the process stats are read from:
+// the CPU time in seconds, the resident set size in KiB and the bytes read
+// from and written to storage. They are procps ones, the busybox ps(1) does
+// not know about them.
+var processStatsFields = []string{"pid", "ppid", "cputimes", "rss", "rbytes", "wbytes"}
+
+// processStatsArgs are the ps(1) arguments listing the processStatsFields
+// of all the processes.
+var processStatsArgs = []string{"-e", "-o", strings.Join(processStatsFields, ",")}
+
+// ErrProcessStatsNotSupported is the cause of the errors returned when the
+// VM cannot report the stats of the container processes, e.g. its ps(1) is
+// the busybox one.
+var ErrProcessStatsNotSupported = errors.New("Process stats not supported by the VM")
+
+// ProcessStats describes the resources used by a container process.
+type ProcessStats struct {
+	// GuestPid is the process ID in the VM.
+	GuestPid int
+
+	// GuestPPid is the parent process ID in the VM.
+	GuestPPid int
+
+	// CPUTime is the user and system CPU time used by the process, to
+	// the second.
+	CPUTime time.Duration
+
+	// RSS is the resident set size of the process, in bytes.
+	RSS uint64
+
+	// ReadBytes is the number of bytes the process read from storage.
+	ReadBytes uint64
+
+	// WriteBytes is the number of bytes the process wrote to storage.
+	WriteBytes uint64
+}
+
+// parseProcessStats reads the stats of the processes from the output of
+// ps(1) run with the processStatsArgs. The fields ps cannot read are
+// reported as 0.
+func parseProcessStats(processList ProcessList) ([]ProcessStats, error) {
+	lines := strings.Split(strings.TrimSpace(string(processList)), "\n")
+	if len(strings.Fields(lines[0])) != len(processStatsFields) {
+		return nil, errors.Wrapf(ErrProcessStatsNotSupported, "Unexpected process list header %q", lines[0])
+	}
+
+	var stats []ProcessStats
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) != len(processStatsFields) {
+			continue
+		}
+
+		values := make([]uint64, len(fields))
+		for i, field := range fields {
+			// ps reports "-" for the fields it cannot read.
+			if field == "-" {
+				continue
+			}
+
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %s in process list line %q: %v", processStatsFields[i], line, err)
+			}
+			values[i] = v
+		}
+
+		stats = append(stats, ProcessStats{
+			GuestPid:   int(values[0]),
+			GuestPPid:  int(values[1]),
+			CPUTime:    time.Duration(values[2]) * time.Second,
+			RSS:        values[3] << 10,
+			ReadBytes:  values[4],
+			WriteBytes: values[5],
+		})
+	}
+
+	return stats, nil
+}
+
+// listProcessStats lists the stats of the processes of a container. The
+// agent failing to run ps(1) with the processStatsArgs is reported as
+// ErrProcessStatsNotSupported.
+func listProcessStats(sandbox *Sandbox, c *Container) ([]ProcessStats, error) {
+	processList, err := sandbox.agent.processListContainer(sandbox, *c, ProcessListOptions{
+		Format: "table",
+		Args:   processStatsArgs,
+	})
+	if err != nil {
+		// The agent reports the ps(1) failures as is.
+		if st, ok := grpcStatus.FromError(err); ok && st.Code() == codes.Unknown {
+			return nil, errors.Wrapf(ErrProcessStatsNotSupported, "%v", err)
+		}
+		return nil, err
+	}
+
+	return parseProcessStats(processList)
+}
+
+func (s *Sandbox) setExecGuestPid(processID string, pid int) {
+	s.execGuestPidsLock.Lock()
+	defer s.execGuestPidsLock.Unlock()
+
+	if s.execGuestPids == nil {
+		s.execGuestPids = make(map[string]int)
+	}
+	s.execGuestPids[processID] = pid
+}
+
+func (s *Sandbox) removeExecGuestPid(processID string) {
+	s.execGuestPidsLock.Lock()
+	defer s.execGuestPidsLock.Unlock()
+
+	delete(s.execGuestPids, processID)
+}
+
+// isExecGuestPid tells whether pid is the one of a running process
+// executed with EnterContainer.
+func (s *Sandbox) isExecGuestPid(pid int) bool {
+	s.execGuestPidsLock.Lock()
+	defer s.execGuestPidsLock.Unlock()
+
+	for _, p := range s.execGuestPids {
+		if p == pid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ProcessStats returns the resources used by the processes of a container
+// in the VM, along with their parent so that the descendants of the
+// processes executed with EnterContainer can be told apart. They are all
+// listed at once by the agent.
+func (s *Sandbox) ProcessStats(containerID string) ([]ProcessStats, error) {
+	c, err := s.findContainer(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkSandboxRunning("get the process stats of"); err != nil {
+		return nil, err
+	}
+
+	return listProcessStats(s, c)
+}
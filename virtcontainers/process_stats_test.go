@@ -0,0 +1,143 @@
+// Copyright (c) 2020 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+const testProcessStatsList = `  PID  PPID     TIME   RSS RBYTES WBYTES
+    1     0       12  9212  4096   8192
+   42     0        3  2048      -      -
+   43    42        1  1024      0      0
+`
+
+type processStatsTestAgent struct {
+	noopAgent
+	options ProcessListOptions
+	err     error
+}
+
+func (a *processStatsTestAgent) processListContainer(sandbox *Sandbox, c Container, options ProcessListOptions) (ProcessList, error) {
+	a.options = options
+	return ProcessList(testProcessStatsList), a.err
+}
+
+func TestParseProcessStats(t *testing.T) {
+	assert := assert.New(t)
+
+	stats, err := parseProcessStats(ProcessList(testProcessStatsList))
+	assert.NoError(err)
+	assert.Equal([]ProcessStats{
+		{
+			GuestPid:   1,
+			CPUTime:    12 * time.Second,
+			RSS:        9212 << 10,
+			ReadBytes:  4096,
+			WriteBytes: 8192,
+		},
+		// the fields ps cannot read are reported as 0
+		{
+			GuestPid: 42,
+			CPUTime:  3 * time.Second,
+			RSS:      2048 << 10,
+		},
+		{
+			GuestPid:  43,
+			GuestPPid: 42,
+			CPUTime:   time.Second,
+			RSS:       1024 << 10,
+		},
+	}, stats)
+
+	// busybox ps lists its own fields
+	_, err = parseProcessStats(ProcessList("PID   USER     TIME  COMMAND\n1 root 0:00 /pause"))
+	assert.Equal(ErrProcessStatsNotSupported, pkgErrors.Cause(err))
+
+	_, err = parseProcessStats(ProcessList(""))
+	assert.Error(err)
+
+	_, err = parseProcessStats(ProcessList("PID PPID TIME RSS RBYTES WBYTES\n1 0 foo 0 0 0"))
+	assert.Error(err)
+	assert.NotEqual(ErrProcessStatsNotSupported, pkgErrors.Cause(err))
+}
+
+func TestSandboxProcessStats(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, nil, nil)
+	assert.NoError(err)
+	defer cleanUp()
+
+	agent := &processStatsTestAgent{}
+	s.agent = agent
+
+	contID := "999"
+	_, err = s.CreateContainer(newTestContainerConfigNoop(contID))
+	assert.NoError(err)
+
+	// the sandbox is not running
+	_, err = s.ProcessStats(contID)
+	assert.Error(err)
+
+	err = s.Start()
+	assert.NoError(err)
+
+	_, err = s.ProcessStats("foo")
+	assert.Error(err)
+
+	stats, err := s.ProcessStats(contID)
+	assert.NoError(err)
+	assert.Len(stats, 3)
+	assert.Equal("table", agent.options.Format)
+	assert.Equal(processStatsArgs, agent.options.Args)
+
+	// ps failing in the VM
+	agent.err = grpcStatus.Error(codes.Unknown, "exit status 1")
+	_, err = s.ProcessStats(contID)
+	assert.Equal(ErrProcessStatsNotSupported, pkgErrors.Cause(err))
+
+	agent.err = errors.New("Dead agent")
+	_, err = s.ProcessStats(contID)
+	assert.Error(err)
+	assert.NotEqual(ErrProcessStatsNotSupported, pkgErrors.Cause(err))
+}
+
+func TestExecGuestPids(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Sandbox{}
+	assert.False(s.isExecGuestPid(42))
+
+	s.setExecGuestPid("exec", 42)
+	assert.True(s.isExecGuestPid(42))
+	assert.False(s.isExecGuestPid(43))
+
+	s.removeExecGuestPid("exec")
+	assert.False(s.isExecGuestPid(42))
+}
+
+func TestKataAgentGetExecGuestPid(t *testing.T) {
+	assert := assert.New(t)
+
+	k := &kataAgent{}
+	s := &Sandbox{agent: &processStatsTestAgent{}}
+	c := &Container{id: "foo", process: Process{GuestPid: 1}}
+
+	// the descendants of the executed process are not it
+	assert.Equal(42, k.getExecGuestPid(s, c))
+
+	// the processes executed before are not it
+	s.setExecGuestPid("exec", 42)
+	assert.Equal(0, k.getExecGuestPid(s, c))
+}
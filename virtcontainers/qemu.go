@@ -185,7 +185,29 @@ func (q *qemu) capabilities() types.Capabilities {
 	span, _ := q.trace("capabilities")
 	defer span.Finish()
 
-	return q.arch.capabilities()
+	caps := q.arch.capabilities()
+	caps.SetSnapshotSupport()
+	caps.SetMigrationSupport()
+	caps.SetTemplateSupport()
+
+	return caps
+}
+
+// checkTemplateConfig checks the qemu VM template requirements. The guest
+// memory of the template is shared with the VMs booted from it through
+// the file at MemoryPath.
+func (q *qemu) checkTemplateConfig(conf *HypervisorConfig) error {
+	if conf.MemoryPath == "" {
+		return fmt.Errorf("Missing MemoryPath for vm template")
+	}
+
+	return nil
+}
+
+// templateFiles returns the shared guest memory file and the devices state
+// of the template VM.
+func (q *qemu) templateFiles(conf *HypervisorConfig) []string {
+	return []string{conf.MemoryPath, conf.DevicesStatePath}
 }
 
 func (q *qemu) hypervisorConfig() HypervisorConfig {
@@ -443,6 +465,10 @@ func (q *qemu) setupTemplate(knobs *govmmQemu.Knobs, memory *govmmQemu.Memory) g
 		}
 	}
 
+	if q.config.BootFromCheckpoint {
+		incoming.MigrationType = govmmQemu.MigrationDefer
+	}
+
 	return incoming
 }
 
@@ -857,6 +883,12 @@ func (q *qemu) startSandbox(timeout int) error {
 		}
 	}
 
+	if q.config.BootFromCheckpoint {
+		if err = q.bootFromCheckpoint(); err != nil {
+			return err
+		}
+	}
+
 	if q.config.VirtioMem {
 		err = q.setupVirtioMem()
 	}
@@ -884,6 +916,23 @@ func (q *qemu) bootFromTemplate() error {
 	return q.waitMigration()
 }
 
+// bootFromCheckpoint loads the VM state saved by a sandbox checkpoint.
+// Unlike templating, guest memory is part of the saved state.
+func (q *qemu) bootFromCheckpoint() error {
+	err := q.qmpSetup()
+	if err != nil {
+		return err
+	}
+	defer q.qmpShutdown()
+
+	uri := fmt.Sprintf("exec:cat %s", q.config.DevicesStatePath)
+	err = q.qmpMonitorCh.qmp.ExecuteMigrationIncoming(q.qmpMonitorCh.ctx, uri)
+	if err != nil {
+		return err
+	}
+	return q.waitMigration()
+}
+
 // waitSandbox will wait for the Sandbox's VM to be up and running.
 func (q *qemu) waitSandbox(timeout int) error {
 	span, _ := q.trace("waitSandbox")
@@ -1428,6 +1477,11 @@ func (q *qemu) hotAddNetDevice(name, hardAddr string, VMFds, VhostFds []*os.File
 }
 
 func (q *qemu) hotplugNetDevice(endpoint Endpoint, op operation) (err error) {
+	// The network devices are hotplugged with the qemu defaults.
+	if netPair := endpoint.NetworkPair(); op == addDevice && netPair != nil && netPair.Config.virtqueueOptions() {
+		return fmt.Errorf("Cannot set the queue sizes and the offloads of hotplugged network device %s", endpoint.Name())
+	}
+
 	err = q.qmpSetup()
 	if err != nil {
 		return err
@@ -1446,6 +1500,11 @@ func (q *qemu) hotplugNetDevice(endpoint Endpoint, op operation) (err error) {
 	}
 
 	devID := "virtio-" + tap.ID
+	queues := int(q.config.NumVCPUs)
+	if len(tap.VMFds) > 0 {
+		queues = len(tap.VMFds)
+	}
+
 	if op == addDevice {
 		if err = q.hotAddNetDevice(tap.Name, endpoint.HardwareAddr(), tap.VMFds, tap.VhostFds); err != nil {
 			return err
@@ -1486,9 +1545,9 @@ func (q *qemu) hotplugNetDevice(endpoint Endpoint, op operation) (err error) {
 		}
 		if machine.Type == QemuCCWVirtio {
 			devNoHotplug := fmt.Sprintf("fe.%x.%x", bridge.Addr, addr)
-			return q.qmpMonitorCh.qmp.ExecuteNetCCWDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), devNoHotplug, int(q.config.NumVCPUs))
+			return q.qmpMonitorCh.qmp.ExecuteNetCCWDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), devNoHotplug, queues)
 		}
-		return q.qmpMonitorCh.qmp.ExecuteNetPCIDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), addr, bridge.ID, romFile, int(q.config.NumVCPUs), defaultDisableModern)
+		return q.qmpMonitorCh.qmp.ExecuteNetPCIDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), addr, bridge.ID, romFile, queues, defaultDisableModern)
 
 	}
 
@@ -1841,8 +1900,8 @@ func (q *qemu) getSandboxConsole(id string) (string, error) {
 	return utils.BuildSocketPath(q.store.RunVMStoragePath(), id, consoleSocket)
 }
 
-func (q *qemu) saveSandbox() error {
-	q.Logger().Info("save sandbox")
+func (q *qemu) saveSandbox(statePath string) error {
+	q.Logger().WithField("state-path", statePath).Info("save sandbox")
 
 	err := q.qmpSetup()
 	if err != nil {
@@ -1859,7 +1918,7 @@ func (q *qemu) saveSandbox() error {
 		}
 	}
 
-	err = q.qmpMonitorCh.qmp.ExecSetMigrateArguments(q.qmpMonitorCh.ctx, fmt.Sprintf("%s>%s", qmpExecCatCmd, q.config.DevicesStatePath))
+	err = q.qmpMonitorCh.qmp.ExecSetMigrateArguments(q.qmpMonitorCh.ctx, fmt.Sprintf("%s>%s", qmpExecCatCmd, statePath))
 	if err != nil {
 		q.Logger().WithError(err).Error("exec migration")
 		return err
@@ -1868,9 +1927,33 @@ func (q *qemu) saveSandbox() error {
 	return q.waitMigration()
 }
 
+// migrateSandbox live migrates the running VM to uri. QEMU stops the VM
+// once the migration completed, it can only be resumed if the destination
+// could not start it.
+func (q *qemu) migrateSandbox(uri string) error {
+	q.Logger().WithField("uri", uri).Info("migrate sandbox")
+
+	err := q.qmpSetup()
+	if err != nil {
+		return err
+	}
+
+	err = q.qmpMonitorCh.qmp.ExecSetMigrateArguments(q.qmpMonitorCh.ctx, uri)
+	if err != nil {
+		q.Logger().WithError(err).Error("migration")
+		return err
+	}
+
+	return q.waitMigration()
+}
+
+// waitMigration waits for the migration to complete. It only gives up once
+// the migration makes no progress for qmpMigrationWaitTimeout, as saving or
+// transferring the memory of a large guest takes a lot longer than that.
 func (q *qemu) waitMigration() error {
-	t := time.NewTimer(qmpMigrationWaitTimeout)
-	defer t.Stop()
+	var transferred int64
+	deadline := time.Now().Add(qmpMigrationWaitTimeout)
+
 	for {
 		status, err := q.qmpMonitorCh.qmp.ExecuteQueryMigration(q.qmpMonitorCh.ctx)
 		if err != nil {
@@ -1880,16 +1963,24 @@ func (q *qemu) waitMigration() error {
 		if status.Status == "completed" {
 			break
 		}
+		if status.Status == "failed" {
+			q.Logger().WithField("migration-status", status).Error("qemu migration failed")
+			return fmt.Errorf("qemu migration failed")
+		}
+
+		if status.RAM.Transferred > transferred {
+			transferred = status.RAM.Transferred
+			deadline = time.Now().Add(qmpMigrationWaitTimeout)
+		}
 
-		select {
-		case <-t.C:
+		if time.Now().After(deadline) {
 			q.Logger().WithField("migration-status", status).Error("timeout waiting for qemu migration")
-			return fmt.Errorf("timed out after %d seconds waiting for qemu migration", qmpMigrationWaitTimeout)
-		default:
-			// migration in progress
-			q.Logger().WithField("migration-status", status).Debug("migration in progress")
-			time.Sleep(100 * time.Millisecond)
+			return fmt.Errorf("qemu migration made no progress for %v", qmpMigrationWaitTimeout)
 		}
+
+		// migration in progress
+		q.Logger().WithField("migration-status", status).Debug("migration in progress")
+		time.Sleep(100 * time.Millisecond)
 	}
 
 	return nil
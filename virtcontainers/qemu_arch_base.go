@@ -598,16 +598,79 @@ func genericNetwork(endpoint Endpoint, vhost, nestedRun bool, index int) (govmmQ
 		return govmmQemu.NetDevice{}, fmt.Errorf("Unknown type for endpoint")
 	}
 
+	if netPair := endpoint.NetworkPair(); netPair != nil {
+		d.VHost = d.VHost && !netPair.Config.DisableVhostNet
+	}
+
 	return d, nil
 }
 
+// qemuNetDevice is a network device with virtqueue sizes or offloads set,
+// the govmm network device does not handle them.
+type qemuNetDevice struct {
+	govmmQemu.NetDevice
+	config NetInterfaceConfig
+}
+
+// newNetDevice returns the network device d of endpoint, along with the
+// virtqueue options of the endpoint when they are set.
+func newNetDevice(d govmmQemu.NetDevice, endpoint Endpoint) govmmQemu.Device {
+	netPair := endpoint.NetworkPair()
+	if netPair == nil || !netPair.Config.virtqueueOptions() {
+		return d
+	}
+
+	return qemuNetDevice{d, netPair.Config}
+}
+
+// QemuParams returns the qemu parameters of the network device, the
+// virtqueue options being appended to the -device ones.
+func (d qemuNetDevice) QemuParams(config *govmmQemu.Config) []string {
+	params := d.NetDevice.QemuParams(config)
+
+	for i := 0; i+1 < len(params); i++ {
+		if params[i] == "-device" {
+			params[i+1] += d.deviceParams()
+		}
+	}
+
+	return params
+}
+
+func (d qemuNetDevice) deviceParams() string {
+	var p []string
+
+	if d.config.RxQueueSize > 0 {
+		p = append(p, fmt.Sprintf(",rx_queue_size=%d", d.config.RxQueueSize))
+	}
+
+	if d.config.TxQueueSize > 0 {
+		p = append(p, fmt.Sprintf(",tx_queue_size=%d", d.config.TxQueueSize))
+	}
+
+	if d.config.DisableChecksum {
+		p = append(p, ",csum=off,guest_csum=off")
+	}
+
+	// The segmentation offloads depend on the checksum ones.
+	if d.config.DisableChecksum || d.config.DisableTSO {
+		p = append(p, ",host_tso4=off,host_tso6=off,guest_tso4=off,guest_tso6=off")
+	}
+
+	if d.config.DisableChecksum || d.config.DisableGSO {
+		p = append(p, ",gso=off,host_ufo=off,guest_ufo=off")
+	}
+
+	return strings.Join(p, "")
+}
+
 func (q *qemuArchBase) appendNetwork(devices []govmmQemu.Device, endpoint Endpoint) ([]govmmQemu.Device, error) {
 	d, err := genericNetwork(endpoint, q.vhost, q.nestedRun, q.networkIndex)
 	if err != nil {
 		return devices, fmt.Errorf("Failed to append network %v", err)
 	}
 	q.networkIndex++
-	devices = append(devices, d)
+	devices = append(devices, newNetDevice(d, endpoint))
 	return devices, nil
 }
 
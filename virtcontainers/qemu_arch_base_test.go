@@ -11,6 +11,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	govmmQemu "github.com/kata-containers/govmm/qemu"
@@ -569,6 +570,45 @@ func TestQemuArchBaseAppendNetwork(t *testing.T) {
 	assert.Equal(expectedOut, devices)
 }
 
+func TestQemuArchBaseAppendNetworkConfig(t *testing.T) {
+	assert := assert.New(t)
+	qemuArchBase := newQemuArchBase()
+	qemuArchBase.vhost = true
+
+	vethEp := &VethEndpoint{
+		NetPair: NetworkInterfacePair{
+			TapInterface: TapInterface{
+				TAPIface: NetworkInterface{
+					Name: "tap0_kata",
+				},
+			},
+			Config: NetInterfaceConfig{
+				RxQueueSize:     1024,
+				TxQueueSize:     512,
+				DisableVhostNet: true,
+				DisableTSO:      true,
+			},
+		},
+		EndpointType: VethEndpointType,
+	}
+
+	devices, err := qemuArchBase.appendNetwork(nil, vethEp)
+	assert.NoError(err)
+	assert.Len(devices, 1)
+
+	d := devices[0].(qemuNetDevice)
+	assert.False(d.VHost)
+	params := d.QemuParams(&govmmQemu.Config{})
+	assert.Len(params, 4)
+	assert.Equal("-device", params[2])
+	assert.True(strings.HasSuffix(params[3], ",rx_queue_size=1024,tx_queue_size=512,host_tso4=off,host_tso6=off,guest_tso4=off,guest_tso6=off"), params[3])
+
+	vethEp.NetPair.Config = NetInterfaceConfig{}
+	devices, err = qemuArchBase.appendNetwork(nil, vethEp)
+	assert.NoError(err)
+	assert.True(devices[0].(govmmQemu.NetDevice).VHost)
+}
+
 func TestQemuArchBaseAppendIOMMU(t *testing.T) {
 	var devices []govmmQemu.Device
 	var err error
@@ -184,7 +184,7 @@ func (q *qemuS390x) appendNetwork(devices []govmmQemu.Device, endpoint Endpoint)
 		return devices, fmt.Errorf("Failed to append network %v", err)
 	}
 
-	devices = append(devices, d)
+	devices = append(devices, newNetDevice(d, endpoint))
 	return devices, nil
 }
 
@@ -347,6 +347,19 @@ func TestQemuCapabilities(t *testing.T) {
 
 	caps := q.capabilities()
 	assert.True(caps.IsBlockDeviceHotplugSupported())
+	assert.True(caps.IsSnapshotSupported())
+	assert.True(caps.IsTemplateSupported())
+}
+
+func TestQemuCheckTemplateConfig(t *testing.T) {
+	assert := assert.New(t)
+	q := &qemu{}
+
+	config := HypervisorConfig{BootToBeTemplate: true}
+	assert.Error(q.checkTemplateConfig(&config))
+
+	config.MemoryPath = "foobar"
+	assert.NoError(q.checkTemplateConfig(&config))
 }
 
 func TestQemuQemuPath(t *testing.T) {
@@ -413,6 +426,27 @@ func TestHotplugUnsupportedDeviceType(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestHotplugNetDeviceVirtqueueOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	q := &qemu{
+		ctx:    context.Background(),
+		id:     "qemuTest",
+		config: newQemuConfig(),
+	}
+
+	endpoint := &VethEndpoint{
+		NetPair: NetworkInterfacePair{
+			Config: NetInterfaceConfig{RxQueueSize: 1024},
+		},
+		EndpointType: VethEndpointType,
+	}
+
+	err := q.hotplugNetDevice(endpoint, addDevice)
+	assert.Error(err)
+	assert.Contains(err.Error(), "hotplugged network device")
+}
+
 func TestQMPSetupShutdown(t *testing.T) {
 	assert := assert.New(t)
 
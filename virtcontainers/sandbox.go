@@ -13,6 +13,7 @@ import (
 	"math"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"syscall"
@@ -95,6 +96,9 @@ type SandboxConfig struct {
 
 	NetworkConfig NetworkConfig
 
+	// MonitorConfig is the health-check policy of the sandbox monitor.
+	MonitorConfig MonitorConfig
+
 	// Volumes is a list of shared volumes between the host and the Sandbox.
 	Volumes []types.Volume
 
@@ -176,6 +180,12 @@ func (sandboxConfig *SandboxConfig) valid() bool {
 			return false
 		}
 	}
+
+	if err := sandboxConfig.MonitorConfig.valid(); err != nil {
+		virtLog.WithError(err).Error("invalid sandbox monitor configuration")
+		return false
+	}
+
 	return true
 }
 
@@ -194,6 +204,7 @@ type Sandbox struct {
 
 	network Network
 	monitor *monitor
+	events  *eventEmitter
 
 	config *SandboxConfig
 
@@ -203,6 +214,19 @@ type Sandbox struct {
 
 	containers map[string]*Container
 
+	// execGuestPids holds the PIDs in the VM of the running processes
+	// executed in the containers, by process ID.
+	execGuestPids     map[string]int
+	execGuestPidsLock sync.Mutex
+
+	// checkpointed holds the states of the containers found in the
+	// checkpoint the sandbox VM is restored from.
+	checkpointed map[string]persistapi.ContainerState
+
+	// checkpointedEndpoints holds the network endpoints the sandbox VM
+	// restored from a checkpoint was saved with.
+	checkpointedEndpoints []Endpoint
+
 	state types.SandboxState
 
 	networkNS NetworkNamespace
@@ -326,6 +350,7 @@ func (s *Sandbox) Status() SandboxStatus {
 			ID:          c.id,
 			State:       c.state,
 			PID:         c.process.Pid,
+			GuestPID:    c.process.GuestPid,
 			StartTime:   c.process.StartTime,
 			RootFs:      rootfs,
 			Annotations: c.config.Annotations,
@@ -521,7 +546,6 @@ func newSandbox(ctx context.Context, sandboxConfig SandboxConfig, factory Factor
 	s := &Sandbox{
 		id:              sandboxConfig.ID,
 		factory:         factory,
-		hypervisor:      hypervisor,
 		agent:           agent,
 		config:          &sandboxConfig,
 		volumes:         sandboxConfig.Volumes,
@@ -533,9 +557,12 @@ func newSandbox(ctx context.Context, sandboxConfig SandboxConfig, factory Factor
 		sharePidNs:      sandboxConfig.SharePidNs,
 		stateful:        sandboxConfig.Stateful,
 		networkNS:       NetworkNamespace{NetNsPath: sandboxConfig.NetworkConfig.NetNSPath},
+		events:          &eventEmitter{},
 		ctx:             ctx,
 	}
 
+	s.hypervisor = newEventHypervisor(s, hypervisor)
+
 	if s.newStore, err = persist.GetDriver(); err != nil || s.newStore == nil {
 		return nil, fmt.Errorf("failed to get fs persist driver: %v", err)
 	}
@@ -558,6 +585,15 @@ func newSandbox(ctx context.Context, sandboxConfig SandboxConfig, factory Factor
 		sandboxConfig.HypervisorConfig.SELinuxProcessLabel = spec.Process.SelinuxLabel
 	}
 
+	if sandboxConfig.HypervisorConfig.BootFromCheckpoint {
+		// The VM is restored from its checkpoint, not taken from the factory.
+		s.factory = nil
+
+		if err = s.loadCheckpoint(filepath.Dir(sandboxConfig.HypervisorConfig.DevicesStatePath)); err != nil {
+			return nil, err
+		}
+	}
+
 	if useOldStore(ctx) {
 		vcStore, err := store.NewVCSandboxStore(ctx, s.id)
 		if err != nil {
@@ -826,6 +862,10 @@ func (s *Sandbox) Delete() error {
 		s.monitor.stop()
 	}
 
+	if s.events != nil {
+		s.events.close()
+	}
+
 	if err := s.hypervisor.cleanup(); err != nil {
 		s.Logger().WithError(err).Error("failed to cleanup hypervisor")
 	}
@@ -898,7 +938,7 @@ func (s *Sandbox) createNetwork() error {
 
 		s.networkNS.Endpoints = endpoints
 
-		if s.config.NetworkConfig.NetmonConfig.Enable {
+		if s.config.NetworkConfig.NetmonConfig.external() {
 			if err := s.startNetworkMonitor(); err != nil {
 				return err
 			}
@@ -916,7 +956,7 @@ func (s *Sandbox) removeNetwork() error {
 	span, _ := s.trace("removeNetwork")
 	defer span.Finish()
 
-	if s.config.NetworkConfig.NetmonConfig.Enable {
+	if s.config.NetworkConfig.NetmonConfig.external() {
 		if err := stopNetmon(s.networkNS.NetmonPID); err != nil {
 			return err
 		}
@@ -968,6 +1008,7 @@ func (s *Sandbox) AddInterface(inf *vcTypes.Interface) (*vcTypes.Interface, erro
 	}
 
 	endpoint.SetProperties(netInfo)
+	s.config.NetworkConfig.setInterfaceConfig(endpoint)
 	if err := doNetNS(s.networkNS.NetNsPath, func(_ ns.NetNS) error {
 		s.Logger().WithField("endpoint-type", endpoint.Type()).Info("Hot attaching endpoint")
 		return endpoint.HotAttach(s.hypervisor)
@@ -983,13 +1024,16 @@ func (s *Sandbox) AddInterface(inf *vcTypes.Interface) (*vcTypes.Interface, erro
 
 	// Add network for vm
 	inf.PciPath = endpoint.PciPath()
+	// The hypervisor may expose the interface to the guest with another
+	// MAC address, e.g. a spare firecracker network device.
+	inf.HwAddr = endpoint.HardwareAddr()
 	return s.agent.updateInterface(inf)
 }
 
 // RemoveInterface removes a nic of the sandbox.
 func (s *Sandbox) RemoveInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error) {
 	for i, endpoint := range s.networkNS.Endpoints {
-		if endpoint.HardwareAddr() == inf.HwAddr {
+		if endpoint.HardwareAddr() == inf.HwAddr || endpoint.Properties().Iface.HardwareAddr.String() == inf.HwAddr {
 			s.Logger().WithField("endpoint-type", endpoint.Type()).Info("Hot detaching endpoint")
 			if err := endpoint.HotDetach(s.hypervisor, s.networkNS.NetNsCreated, s.networkNS.NetNsPath); err != nil {
 				return inf, err
@@ -1006,6 +1050,112 @@ func (s *Sandbox) RemoveInterface(inf *vcTypes.Interface) (*vcTypes.Interface, e
 	return nil, nil
 }
 
+// endpointByHwAddr returns the endpoint of the sandbox with the hardware
+// address hwAddr, on the host or in the guest.
+func (s *Sandbox) endpointByHwAddr(hwAddr string) Endpoint {
+	for _, endpoint := range s.networkNS.Endpoints {
+		if endpoint.HardwareAddr() == hwAddr || endpoint.Properties().Iface.HardwareAddr.String() == hwAddr {
+			return endpoint
+		}
+	}
+
+	return nil
+}
+
+// UpdateInterface updates the IP addresses of a nic of the sandbox.
+func (s *Sandbox) UpdateInterface(inf *vcTypes.Interface) (*vcTypes.Interface, error) {
+	endpoint := s.endpointByHwAddr(inf.HwAddr)
+	if endpoint == nil {
+		s.Logger().WithField("interface", inf.Name).Debug("Ignoring update of unknown interface")
+		return nil, nil
+	}
+
+	netInfo, err := s.generateNetInfo(inf)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := endpoint.Properties()
+	properties.Addrs = netInfo.Addrs
+	endpoint.SetProperties(properties)
+
+	// Update the sandbox storage
+	if err := s.Save(); err != nil {
+		return nil, err
+	}
+
+	// The interface is described to the agent as when the sandbox
+	// network is set up.
+	ifaces, _, _, err := generateVCNetworkStructures(NetworkNamespace{
+		NetNsPath: s.networkNS.NetNsPath,
+		Endpoints: []Endpoint{endpoint},
+	})
+	if err != nil || len(ifaces) == 0 {
+		return nil, err
+	}
+
+	return s.agent.updateInterface(ifaces[0])
+}
+
+// AddNeighbors adds static ARP and NDP neighbors to the nics of the sandbox.
+func (s *Sandbox) AddNeighbors(neighs []*vcTypes.ARPNeighbor) error {
+	for _, neigh := range neighs {
+		if neigh.ToIPAddress == nil {
+			return fmt.Errorf("Neighbor without IP address on %s", neigh.Device)
+		}
+
+		ip := net.ParseIP(neigh.ToIPAddress.Address)
+		if ip == nil {
+			return fmt.Errorf("Invalid neighbor IP address %q", neigh.ToIPAddress.Address)
+		}
+
+		var hwAddr net.HardwareAddr
+		if neigh.LLAddr != "" {
+			var err error
+			if hwAddr, err = net.ParseMAC(neigh.LLAddr); err != nil {
+				return err
+			}
+		}
+
+		// Keep track of the neighbor with its endpoint, so that it is
+		// set up again with the network of a restarted VM.
+		for _, endpoint := range s.networkNS.Endpoints {
+			if endpoint.Name() != neigh.Device {
+				continue
+			}
+
+			properties := endpoint.Properties()
+			n := netlink.Neigh{
+				LinkIndex:    properties.Iface.Index,
+				IP:           ip,
+				HardwareAddr: hwAddr,
+				State:        neigh.State,
+				Flags:        neigh.Flags,
+			}
+
+			replaced := false
+			for i := range properties.Neighbors {
+				if properties.Neighbors[i].IP.Equal(ip) {
+					properties.Neighbors[i] = n
+					replaced = true
+				}
+			}
+			if !replaced {
+				properties.Neighbors = append(properties.Neighbors, n)
+			}
+
+			endpoint.SetProperties(properties)
+		}
+	}
+
+	// Update the sandbox storage
+	if err := s.Save(); err != nil {
+		return err
+	}
+
+	return s.agent.addARPNeighbors(neighs)
+}
+
 // ListInterfaces lists all nics and their configurations in the sandbox.
 func (s *Sandbox) ListInterfaces() ([]*vcTypes.Interface, error) {
 	return s.agent.listInterfaces()
@@ -1045,7 +1195,16 @@ func (s *Sandbox) startVM() (err error) {
 			return vm.assignSandbox(s)
 		}
 
-		return s.hypervisor.startSandbox(vmStartTimeout)
+		if err := s.hypervisor.startSandbox(vmStartTimeout); err != nil {
+			return err
+		}
+
+		// VMs restored from a checkpoint are paused
+		if s.config.HypervisorConfig.BootFromCheckpoint {
+			return s.hypervisor.resumeSandbox()
+		}
+
+		return nil
 	}); err != nil {
 		return err
 	}
@@ -1066,7 +1225,7 @@ func (s *Sandbox) startVM() (err error) {
 
 		s.networkNS.Endpoints = endpoints
 
-		if s.config.NetworkConfig.NetmonConfig.Enable {
+		if s.config.NetworkConfig.NetmonConfig.external() {
 			if err := s.startNetworkMonitor(); err != nil {
 				return err
 			}
@@ -1084,6 +1243,7 @@ func (s *Sandbox) startVM() (err error) {
 	}
 
 	s.Logger().Info("Agent started in the sandbox")
+	s.emit(EventAgentConnected, "", nil)
 
 	return nil
 }
@@ -1353,6 +1513,7 @@ func (s *Sandbox) StatusContainer(containerID string) (ContainerStatus, error) {
 			ID:          c.id,
 			State:       c.state,
 			PID:         c.process.Pid,
+			GuestPID:    c.process.GuestPid,
 			StartTime:   c.process.StartTime,
 			RootFs:      rootfs,
 			Annotations: c.config.Annotations,
@@ -1614,6 +1775,135 @@ func (s *Sandbox) Stop(force bool) error {
 	return nil
 }
 
+// RestartVM replaces the VM of a running sandbox with a new one, booted
+// with the same configuration. The sandbox network endpoints are plugged
+// into the new VM and the containers are created again in it, from their
+// persisted states: ready containers are created, running and paused ones
+// are started, and paused again, with new processes.
+func (s *Sandbox) RestartVM() (err error) {
+	span, ctx := s.trace("restartVM")
+	defer span.Finish()
+
+	if s.state.State != types.StateRunning {
+		return fmt.Errorf("Sandbox not running, impossible to restart its VM")
+	}
+
+	if s.monitor != nil {
+		s.monitor.suspend()
+		defer s.monitor.resume()
+	}
+
+	s.Logger().Info("Restarting VM")
+
+	// Stopping the containers below persists them as such.
+	_, states, err := s.newStore.FromDisk(s.id)
+	if err != nil {
+		return err
+	}
+
+	// Whatever state the VM is in, it is gone from now on.
+	if err := s.hypervisor.stopSandbox(); err != nil {
+		s.Logger().WithError(err).Warn("failed to stop VM")
+	}
+	s.agent.markDead()
+
+	for _, c := range s.containers {
+		if err := c.stop(true); err != nil {
+			return err
+		}
+	}
+
+	s.agent.cleanup(s)
+	if err := s.hypervisor.cleanup(); err != nil {
+		s.Logger().WithError(err).Warn("failed to cleanup hypervisor")
+	}
+
+	// The new VM is always booted from scratch.
+	s.factory = nil
+	s.config.HypervisorConfig.BootFromCheckpoint = false
+	s.checkpointed = nil
+
+	h, err := newHypervisor(s.config.HypervisorType)
+	if err != nil {
+		return err
+	}
+	s.hypervisor = newEventHypervisor(s, h)
+
+	if err := s.hypervisor.createSandbox(ctx, s.id, s.networkNS, &s.config.HypervisorConfig, s.stateful); err != nil {
+		return err
+	}
+
+	agentConfig, err := newAgentConfig(s.config.AgentType, s.config.AgentConfig)
+	if err != nil {
+		return err
+	}
+
+	s.agent = newAgent(s.config.AgentType)
+	if s.disableVMShutdown, err = s.agent.init(ctx, s, agentConfig); err != nil {
+		return err
+	}
+
+	if err := s.agent.createSandbox(s); err != nil {
+		return err
+	}
+
+	// Plug the sandbox network endpoints into the new VM.
+	for _, endpoint := range s.networkNS.Endpoints {
+		if err := endpoint.Detach(true, s.networkNS.NetNsPath); err != nil {
+			return err
+		}
+	}
+
+	if err := doNetNS(s.networkNS.NetNsPath, func(_ ns.NetNS) error {
+		for _, endpoint := range s.networkNS.Endpoints {
+			if err := endpoint.Attach(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := s.startVM(); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			s.stopVM()
+		}
+	}()
+
+	s.postCreatedNetwork()
+
+	if err := s.getAndStoreGuestDetails(); err != nil {
+		return err
+	}
+
+	// Containers are created in the order the sandbox knew them, the
+	// sandbox container being the first one.
+	for _, contConfig := range s.config.Containers {
+		c, ok := s.containers[contConfig.ID]
+		if !ok {
+			continue
+		}
+
+		if err := c.restartIn(types.StateString(states[c.id].State)); err != nil {
+			return err
+		}
+	}
+
+	if err := s.storeSandbox(); err != nil {
+		return err
+	}
+
+	s.Logger().Info("VM restarted")
+	s.emit(EventVMRestarted, "", nil)
+
+	return nil
+}
+
 // list lists all sandbox running on the host.
 func (s *Sandbox) list() ([]Sandbox, error) {
 	return nil, nil
@@ -1678,10 +1968,16 @@ func (s *Sandbox) unsetSandboxBlockIndex(index int) error {
 
 // HotplugAddDevice is used for add a device to sandbox
 // Sandbox implement DeviceReceiver interface from device/api/interface.go
-func (s *Sandbox) HotplugAddDevice(device api.Device, devType config.DeviceType) error {
+func (s *Sandbox) HotplugAddDevice(device api.Device, devType config.DeviceType) (err error) {
 	span, _ := s.trace("HotplugAddDevice")
 	defer span.Finish()
 
+	defer func() {
+		if err == nil {
+			s.emitDeviceEvent(EventDeviceHotplugged, device, devType)
+		}
+	}()
+
 	if s.config.SandboxCgroupOnly {
 		// We are about to add a device to the hypervisor,
 		// the device cgroup MUST be updated since the hypervisor
@@ -1736,7 +2032,13 @@ func (s *Sandbox) HotplugAddDevice(device api.Device, devType config.DeviceType)
 
 // HotplugRemoveDevice is used for removing a device from sandbox
 // Sandbox implement DeviceReceiver interface from device/api/interface.go
-func (s *Sandbox) HotplugRemoveDevice(device api.Device, devType config.DeviceType) error {
+func (s *Sandbox) HotplugRemoveDevice(device api.Device, devType config.DeviceType) (err error) {
+	defer func() {
+		if err == nil {
+			s.emitDeviceEvent(EventDeviceHotunplugged, device, devType)
+		}
+	}()
+
 	defer func() {
 		if s.config.SandboxCgroupOnly {
 			// Remove device from cgroup, the hypervisor
@@ -2290,7 +2592,14 @@ func (s *Sandbox) GetPatchedOCISpec() *specs.Spec {
 }
 
 func (s *Sandbox) GetOOMEvent() (string, error) {
-	return s.agent.getOOMEvent()
+	containerID, err := s.agent.getOOMEvent()
+	if err != nil {
+		return "", err
+	}
+
+	s.emit(EventOOM, containerID, nil)
+
+	return containerID, nil
 }
 
 // getSandboxCPUSet returns the union of each of the sandbox's containers' CPU sets'
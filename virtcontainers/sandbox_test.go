@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
 	"path"
@@ -25,10 +26,12 @@ import (
 	exp "github.com/kata-containers/runtime/virtcontainers/experimental"
 	"github.com/kata-containers/runtime/virtcontainers/persist/fs"
 	"github.com/kata-containers/runtime/virtcontainers/pkg/annotations"
+	vcTypes "github.com/kata-containers/runtime/virtcontainers/pkg/types"
 	"github.com/kata-containers/runtime/virtcontainers/store"
 	"github.com/kata-containers/runtime/virtcontainers/types"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
 )
 
@@ -1709,3 +1712,115 @@ func TestSandboxStoreClean(t *testing.T) {
 	assert.Error(err)
 	assert.True(os.IsNotExist(err))
 }
+
+func TestSandboxRestartVM(t *testing.T) {
+	assert := assert.New(t)
+
+	pausedID := "999"
+	readyID := "888"
+	contConfigs := []ContainerConfig{
+		newTestContainerConfigNoop(testSandboxID),
+		newTestContainerConfigNoop(pausedID),
+	}
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, contConfigs, nil)
+	assert.NoError(err)
+	defer cleanUp()
+
+	// sandbox is not running yet
+	err = s.RestartVM()
+	assert.Error(err)
+
+	err = s.Start()
+	assert.NoError(err)
+
+	err = s.PauseContainer(pausedID)
+	assert.NoError(err)
+
+	_, err = s.CreateContainer(newTestContainerConfigNoop(readyID))
+	assert.NoError(err)
+
+	_, err = s.Monitor()
+	assert.NoError(err)
+	defer s.monitor.stop()
+
+	hypervisor := s.hypervisor
+
+	err = s.RestartVM()
+	assert.NoError(err)
+
+	assert.False(hypervisor == s.hypervisor, "sandbox VM not replaced")
+	assert.Equal(types.StateRunning, s.state.State)
+	assert.Equal(types.StateRunning, s.containers[testSandboxID].state.State)
+	assert.Equal(types.StatePaused, s.containers[pausedID].state.State)
+	assert.Equal(types.StateReady, s.containers[readyID].state.State)
+	assert.False(s.monitor.suspended)
+}
+
+func TestSandboxUpdateInterfaceAddNeighbors(t *testing.T) {
+	defer cleanUp()
+	assert := assert.New(t)
+
+	s, err := testCreateSandbox(t, testSandboxID, MockHypervisor, newHypervisorConfig(nil, nil), NoopAgentType, NetworkConfig{}, nil, nil)
+	assert.NoError(err)
+
+	hwAddr, err := net.ParseMAC("02:00:ca:fe:00:48")
+	assert.NoError(err)
+
+	endpoint := &VethEndpoint{
+		EndpointProperties: NetworkInfo{
+			Iface: NetlinkIface{
+				LinkAttrs: netlink.LinkAttrs{Name: "eth0", Index: 2, HardwareAddr: hwAddr},
+			},
+		},
+		NetPair: NetworkInterfacePair{
+			VirtIface: NetworkInterface{Name: "eth0"},
+		},
+	}
+	s.networkNS = NetworkNamespace{
+		NetNsPath: "/foo/bar/netns",
+		Endpoints: []Endpoint{endpoint},
+	}
+
+	inf := &vcTypes.Interface{
+		Name:   "eth0",
+		HwAddr: hwAddr.String(),
+		IPAddresses: []*vcTypes.IPAddress{
+			{Family: netlink.FAMILY_V4, Address: "192.168.0.2", Mask: "24"},
+			{Family: netlink.FAMILY_V4, Address: "192.168.0.3", Mask: "24"},
+		},
+	}
+
+	_, err = s.UpdateInterface(inf)
+	assert.NoError(err)
+	assert.Len(endpoint.Properties().Addrs, 2)
+	assert.Equal("192.168.0.3/24", endpoint.Properties().Addrs[1].IPNet.String())
+
+	// Unknown interfaces are ignored.
+	_, err = s.UpdateInterface(&vcTypes.Interface{Name: "eth1", HwAddr: "02:00:ca:fe:00:49"})
+	assert.NoError(err)
+
+	neigh := &vcTypes.ARPNeighbor{
+		ToIPAddress: &vcTypes.IPAddress{Family: netlink.FAMILY_V4, Address: "192.168.0.1"},
+		Device:      "eth0",
+		LLAddr:      "02:00:ca:fe:00:01",
+		State:       netlink.NUD_PERMANENT,
+	}
+
+	err = s.AddNeighbors([]*vcTypes.ARPNeighbor{neigh})
+	assert.NoError(err)
+	assert.Len(endpoint.Properties().Neighbors, 1)
+
+	// A neighbor with the same IP address replaces the previous one.
+	neigh.LLAddr = "02:00:ca:fe:00:02"
+	err = s.AddNeighbors([]*vcTypes.ARPNeighbor{neigh})
+	assert.NoError(err)
+	if assert.Len(endpoint.Properties().Neighbors, 1) {
+		assert.Equal(neigh.LLAddr, endpoint.Properties().Neighbors[0].HardwareAddr.String())
+		assert.Equal(2, endpoint.Properties().Neighbors[0].LinkIndex)
+	}
+
+	neigh.LLAddr = "foo"
+	err = s.AddNeighbors([]*vcTypes.ARPNeighbor{neigh})
+	assert.Error(err)
+}
@@ -10,6 +10,11 @@ const (
 	blockDeviceHotplugSupport
 	multiQueueSupport
 	fsSharingSupported
+	snapshotSupport
+	migrationSupport
+	templateSupport
+	netRateLimiterSupport
+	netSharedRateLimiterSupport
 )
 
 // Capabilities describe a virtcontainers hypervisor capabilities
@@ -57,3 +62,57 @@ func (caps *Capabilities) IsFsSharingSupported() bool {
 func (caps *Capabilities) SetFsSharingSupport() {
 	caps.flags |= fsSharingSupported
 }
+
+// IsSnapshotSupported tells if an hypervisor can save and restore the VM state.
+func (caps *Capabilities) IsSnapshotSupported() bool {
+	return caps.flags&snapshotSupport != 0
+}
+
+// SetSnapshotSupport sets the VM state save and restore capability to true.
+func (caps *Capabilities) SetSnapshotSupport() {
+	caps.flags |= snapshotSupport
+}
+
+// IsMigrationSupported tells if an hypervisor can live migrate the VM.
+func (caps *Capabilities) IsMigrationSupported() bool {
+	return caps.flags&migrationSupport != 0
+}
+
+// SetMigrationSupport sets the VM live migration capability to true.
+func (caps *Capabilities) SetMigrationSupport() {
+	caps.flags |= migrationSupport
+}
+
+// IsTemplateSupported tells if an hypervisor can boot VMs to be used as
+// a VM template and VMs from such a template.
+func (caps *Capabilities) IsTemplateSupported() bool {
+	return caps.flags&templateSupport != 0
+}
+
+// SetTemplateSupport sets the VM templating capability to true.
+func (caps *Capabilities) SetTemplateSupport() {
+	caps.flags |= templateSupport
+}
+
+// IsNetRateLimiterSupported tells if an hypervisor can cap the bandwidth of
+// each direction of a network device.
+func (caps *Capabilities) IsNetRateLimiterSupported() bool {
+	return caps.flags&netRateLimiterSupport != 0
+}
+
+// SetNetRateLimiterSupport sets the network rate limiter capability to true.
+func (caps *Capabilities) SetNetRateLimiterSupport() {
+	caps.flags |= netRateLimiterSupport
+}
+
+// IsNetSharedRateLimiterSupported tells if an hypervisor can cap the
+// bandwidth of a network device, with the same limit in both directions.
+func (caps *Capabilities) IsNetSharedRateLimiterSupported() bool {
+	return caps.flags&netSharedRateLimiterSupport != 0
+}
+
+// SetNetSharedRateLimiterSupport sets the shared network rate limiter
+// capability to true.
+func (caps *Capabilities) SetNetSharedRateLimiterSupport() {
+	caps.flags |= netSharedRateLimiterSupport
+}
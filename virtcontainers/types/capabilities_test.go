@@ -43,3 +43,43 @@ func TestMultiQueueCapability(t *testing.T) {
 	caps.SetMultiQueueSupport()
 	assert.True(caps.IsMultiQueueSupported())
 }
+
+func TestSnapshotCapability(t *testing.T) {
+	assert := assert.New(t)
+	var caps Capabilities
+
+	assert.False(caps.IsSnapshotSupported())
+	caps.SetSnapshotSupport()
+	assert.True(caps.IsSnapshotSupported())
+}
+
+func TestMigrationCapability(t *testing.T) {
+	assert := assert.New(t)
+	var caps Capabilities
+
+	assert.False(caps.IsMigrationSupported())
+	caps.SetMigrationSupport()
+	assert.True(caps.IsMigrationSupported())
+}
+
+func TestTemplateCapability(t *testing.T) {
+	assert := assert.New(t)
+	var caps Capabilities
+
+	assert.False(caps.IsTemplateSupported())
+	caps.SetTemplateSupport()
+	assert.True(caps.IsTemplateSupported())
+}
+
+func TestNetRateLimiterCapability(t *testing.T) {
+	assert := assert.New(t)
+	var caps Capabilities
+
+	assert.False(caps.IsNetRateLimiterSupported())
+	caps.SetNetRateLimiterSupport()
+	assert.True(caps.IsNetRateLimiterSupported())
+
+	assert.False(caps.IsNetSharedRateLimiterSupported())
+	caps.SetNetSharedRateLimiterSupport()
+	assert.True(caps.IsNetSharedRateLimiterSupported())
+}
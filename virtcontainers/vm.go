@@ -53,7 +53,31 @@ type VMConfig struct {
 
 // Valid check VMConfig validity.
 func (c *VMConfig) Valid() error {
-	return c.HypervisorConfig.valid()
+	if err := c.HypervisorConfig.valid(); err != nil {
+		return err
+	}
+
+	if !c.HypervisorConfig.BootToBeTemplate && !c.HypervisorConfig.BootFromTemplate {
+		return nil
+	}
+
+	hypervisor, err := newHypervisor(c.HypervisorType)
+	if err != nil {
+		return err
+	}
+
+	return hypervisor.checkTemplateConfig(&c.HypervisorConfig)
+}
+
+// TemplateFiles returns the files a VM template booted with the config is
+// saved to, they depend on the hypervisor.
+func (c *VMConfig) TemplateFiles() ([]string, error) {
+	hypervisor, err := newHypervisor(c.HypervisorType)
+	if err != nil {
+		return nil, err
+	}
+
+	return hypervisor.templateFiles(&c.HypervisorConfig), nil
 }
 
 // ToGrpc convert VMConfig struct to grpc format pb.GrpcVMConfig.
@@ -300,7 +324,7 @@ func (v *VM) Pause() error {
 // Save saves a VM to persistent disk.
 func (v *VM) Save() error {
 	v.logger().Info("save vm")
-	return v.hypervisor.saveSandbox()
+	return v.hypervisor.saveSandbox(v.hypervisor.hypervisorConfig().DevicesStatePath)
 }
 
 // Resume resumes a paused VM.
@@ -445,8 +469,10 @@ func (v *VM) assignSandbox(s *Sandbox) error {
 		return err
 	}
 
-	s.hypervisor = v.hypervisor
+	// The VM was booted by the factory, it starts for the sandbox now.
+	s.hypervisor = newEventHypervisor(s, v.hypervisor)
 	s.config.HypervisorConfig.VMid = v.id
+	s.emit(EventVMStarted, "", nil)
 
 	return nil
 }
@@ -96,6 +96,20 @@ func TestVMConfigValid(t *testing.T) {
 	}
 	err = config.Valid()
 	assert.Nil(err)
+
+	// template requirements are checked by the hypervisor
+	config.HypervisorType = QemuHypervisor
+	config.HypervisorConfig.BootToBeTemplate = true
+	err = config.Valid()
+	assert.Error(err)
+
+	config.HypervisorConfig.MemoryPath = testDir
+	err = config.Valid()
+	assert.Nil(err)
+
+	config.HypervisorType = AcrnHypervisor
+	err = config.Valid()
+	assert.Error(err)
 }
 
 func TestSetupProxy(t *testing.T) {